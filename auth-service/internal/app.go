@@ -2,7 +2,10 @@ package internal
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,42 +18,67 @@ import (
 	userRepository "forum-app/auth-service/internal/repository/user"
 	"forum-app/auth-service/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func Run() error {
-	cfg := config.LoadConfig()
+// logLevel filters the service's logs; SIGHUP may change it.
+var logLevel slog.LevelVar
 
-	// Initialize SQLite Key Repository
-	keyRepo, err := repository.NewSQLiteKeyRepository(cfg.SQLitePath)
-	if err != nil {
-		return err
-	}
+func Run(live *config.Live) error {
+	cfg := live.Get()
+	logLevel.Set(cfg.LogLevel)
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: &logLevel})))
+	slog.Debug("Loaded configuration:\n" + cfg.String())
 
-	// Initialize MongoDB Refresh Token Repository
-	refreshTokenRepo, err := repository.NewMongoDBRefreshTokenRepository(cfg.MongoDBURI, cfg.MongoDBName)
+	// Initialize User, Key, Refresh Token, Rate Limit and Denylist Repositories
+	store, err := openStorage(cfg)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if rRepo, ok := refreshTokenRepo.(*repository.MongoDBRefreshTokenRepository); ok {
-			rRepo.CloseMongoDBConnection()
-		}
-	}()
+	defer store.Close()
+	userRepo, keyRepo, refreshTokenRepo := store.users, store.keys, store.refreshTokens
+	if users, err := userRepo.List(); err == nil && len(users) == 0 {
+		log.Println("No users yet, create one with `auth-service user create <username>`.")
+	}
 
-	// Initialize User Repository (example in-memory)
-	userRepo := userRepository.NewInMemoryUserRepository()
+	// Initialize Trust Level Repository
+	trustLevelRepo := repository.NewStaticTrustLevelRepository(0)
+	if cfg.CoreServiceURL != "" {
+		trustLevelRepo = repository.NewCoreServiceTrustLevelRepository(cfg.CoreServiceURL)
+	}
 
-	// Initialize Auth Service
-	authService := services.NewAuthService(userRepo, keyRepo, refreshTokenRepo, cfg)
+	// Initialize Key Manager, Token Codec and Auth Service
+	keyManager := services.NewKeyManager(keyRepo, live)
+	tokenCodec := services.NewTokenCodec(keyManager, live)
+	authService := services.NewAuthService(userRepo, tokenCodec, refreshTokenRepo, trustLevelRepo, store.rateLimits, store.denylist, live)
 
 	// Initialize Gin Router
 	router := gin.Default()
 
 	// Setup Auth Routes
-	handlers.SetupAuthRoutes(router, authService)
+	handlers.SetupAuthRoutes(router, authService, cfg.ServiceToken)
+	handlers.SetupAdminRoutes(router, keyManager, cfg.AdminToken)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Start Key Rotation in the Background
-	go handlers.RotateKeysPeriodically(keyRepo, time.Minute*15)
+	// Rotate, retire and delete keys in the background until shutdown
+	keysDone := make(chan struct{})
+	go func() {
+		defer close(keysDone)
+		keyManager.Run(ctx)
+	}()
+
+	// Delete expired refresh tokens the store does not expire by itself
+	cleanupDone := make(chan struct{})
+	if cleaner, ok := refreshTokenRepo.(repository.RefreshTokenCleaner); ok {
+		go func() {
+			defer close(cleanupDone)
+			handlers.CleanupRefreshTokensPeriodically(ctx, cleaner, cfg.RefreshTokenCleanupInterval)
+		}()
+	} else {
+		close(cleanupDone)
+	}
 
 	// Server setup
 	server := &http.Server{
@@ -58,11 +86,24 @@ func Run() error {
 		Handler: router,
 	}
 
+	// Reload the settings that are safe to change on SIGHUP
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			if err := live.Reload(); err != nil {
+				slog.Warn("Keeping the current configuration", "err", err)
+				continue
+			}
+			logLevel.Set(live.Get().LogLevel)
+		}
+	}()
+
 	// Graceful shutdown
+	shutdownDone := make(chan struct{})
 	go func() {
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-		<-quit
+		defer close(shutdownDone)
+		<-ctx.Done()
 		log.Println("Shutting down server...")
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -79,5 +120,144 @@ func Run() error {
 		return err
 	}
 
+	// Let requests and background jobs finish before the stores close
+	<-shutdownDone
+	<-keysDone
+	<-cleanupDone
 	return nil
 }
+
+// storage holds the repositories whose backing stores are chosen by config.
+type storage struct {
+	// db is the SQLite database; it is nil with in-memory storage.
+	db            *sql.DB
+	users         userRepository.UserRepository
+	keys          repository.KeyRepository
+	refreshTokens repository.RefreshTokenRepository
+	rateLimits    repository.RateLimitRepository
+	denylist      repository.DenylistRepository
+	// rdb is the Redis client, nil without REDIS_URL.
+	rdb     *redis.Client
+	closers []func()
+}
+
+// Close releases the stores in reverse order of opening.
+func (s *storage) Close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+}
+
+// openStorage creates the repositories in the stores selected by
+// AUTH_STORAGE, REFRESH_TOKEN_STORE and REDIS_URL.
+func openStorage(cfg *config.Config) (*storage, error) {
+	s := &storage{}
+	switch cfg.Storage {
+	case "memory":
+		log.Println("Using in-memory storage, keys and tokens are lost on exit")
+		s.users = userRepository.NewInMemoryUserRepository()
+		s.keys = repository.NewInMemoryKeyRepository()
+		s.refreshTokens = repository.NewInMemoryRefreshTokenRepository()
+		s.rateLimits = repository.NewInMemoryRateLimitRepository()
+		s.denylist = repository.NewInMemoryDenylistRepository()
+		return s, nil
+	case "persistent":
+	default:
+		return nil, fmt.Errorf("unknown storage %q", cfg.Storage)
+	}
+
+	if err := s.open(cfg); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *storage) open(cfg *config.Config) error {
+	if err := s.openSQLite(cfg); err != nil {
+		return err
+	}
+	keys, err := s.openKeys(cfg)
+	if err != nil {
+		return err
+	}
+	if err := keys.EnsureActiveKey(); err != nil {
+		return fmt.Errorf("failed to create signing keys: %w", err)
+	}
+
+	if err := s.openRedis(cfg); err != nil {
+		return err
+	}
+	if s.rdb != nil {
+		s.rateLimits = repository.NewRedisRateLimitRepository(s.rdb)
+		s.denylist = repository.NewRedisDenylistRepository(s.rdb)
+	} else {
+		s.rateLimits = repository.NewInMemoryRateLimitRepository()
+		s.denylist = repository.NewInMemoryDenylistRepository()
+	}
+	return s.openRefreshTokens(cfg)
+}
+
+// openSQLite opens the database and the users stored in it.
+func (s *storage) openSQLite(cfg *config.Config) error {
+	db, err := repository.OpenSQLite(cfg.SQLitePath)
+	if err != nil {
+		return err
+	}
+	s.closers = append(s.closers, func() { db.Close() })
+	s.db = db
+	s.users = userRepository.NewSQLiteUserRepository(db)
+	return nil
+}
+
+// openKeys opens the signing keys in the SQLite database, leaving them as
+// they are.
+func (s *storage) openKeys(cfg *config.Config) (*repository.SQLiteKeyRepository, error) {
+	master, err := repository.LoadMasterKey(cfg.MasterKey, cfg.MasterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	keys := repository.NewSQLiteKeyRepository(s.db, master)
+	s.keys = keys
+	return keys, nil
+}
+
+// openRedis connects to REDIS_URL, if set, unless it is connected already.
+func (s *storage) openRedis(cfg *config.Config) error {
+	if s.rdb != nil || cfg.RedisURL == "" {
+		return nil
+	}
+	rdb, err := repository.OpenRedis(cfg.RedisURL)
+	if err != nil {
+		return err
+	}
+	s.closers = append(s.closers, func() { rdb.Close() })
+	s.rdb = rdb
+	return nil
+}
+
+// openRefreshTokens opens the refresh token store; the SQLite store needs
+// openSQLite first.
+func (s *storage) openRefreshTokens(cfg *config.Config) error {
+	var err error
+	switch cfg.RefreshTokenStore {
+	case "mongodb":
+		s.refreshTokens, err = repository.NewMongoDBRefreshTokenRepository(cfg.MongoDBURI, cfg.MongoDBName)
+		if err == nil {
+			s.closers = append(s.closers, s.refreshTokens.(*repository.MongoDBRefreshTokenRepository).CloseMongoDBConnection)
+		}
+	case "sqlite":
+		s.refreshTokens = repository.NewSQLiteRefreshTokenRepository(s.db)
+	case "redis":
+		if err := s.openRedis(cfg); err != nil {
+			return err
+		}
+		if s.rdb == nil {
+			return fmt.Errorf("refresh token store redis needs REDIS_URL")
+		}
+		s.refreshTokens = repository.NewRedisRefreshTokenRepository(s.rdb)
+	default:
+		err = fmt.Errorf("unknown refresh token store %q", cfg.RefreshTokenStore)
+	}
+	return err
+}
@@ -0,0 +1,354 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"forum-app/auth-service/internal/config"
+	"forum-app/auth-service/internal/repository"
+	"forum-app/auth-service/internal/service"
+)
+
+const usage = `usage: auth-service [-config file] [-setting value ...] <command> [arguments]
+
+Every setting can be given as flag, e.g. -access-token-ttl 10m for
+access_token_ttl; run auth-service config to see them all.
+
+Commands:
+  serve                                   run the service (the default); SIGHUP reloads TTLs,
+                                          key rotation, login rate limits and the log level
+  config                                  check the configuration and print it without secrets
+  user create [-password p] <username>    create a user; the password is read from stdin if not given
+  user list
+  user disable <username>                 lock a user out and end their sessions
+  user enable <username>
+  user reset-password [-password p] <username>
+  keys list
+  keys rotate                             activate the next signing key
+  keys retire <id>                        retire an inactive signing key
+  keys encrypt                            encrypt plaintext signing keys with the master key
+  keys rewrap <new master key file>       re-wrap signing keys with a new master key
+  sessions list <username>
+  sessions revoke <username> <session id>
+  sessions revoke -all <username>
+  migrate up
+  migrate down [-steps n]
+`
+
+var errUsage = errors.New("invalid arguments")
+
+// RunCLI runs the command given in args, the program's arguments without its
+// name. Without a command it serves.
+func RunCLI(args []string) error {
+	loader, args, err := config.NewLoader(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] == "serve" {
+		return Run(config.NewLive(loader, cfg))
+	}
+
+	switch args[0] {
+	case "config":
+		fmt.Print(cfg)
+	case "user":
+		// Disabling a user and resetting a password end their sessions
+		need := adminStores{refreshTokens: len(args) > 1 && (args[1] == "disable" || args[1] == "reset-password")}
+		err = withAdmin(cfg, need, func(admin *services.AdminService, _ *storage) error { return userCommand(admin, args[1:]) })
+	case "keys":
+		err = withAdmin(cfg, adminStores{keys: true}, func(admin *services.AdminService, s *storage) error { return keysCommand(admin, s, args[1:]) })
+	case "sessions":
+		err = withAdmin(cfg, adminStores{refreshTokens: true}, func(admin *services.AdminService, _ *storage) error { return sessionsCommand(admin, args[1:]) })
+	case "migrate":
+		err = migrateCommand(cfg, args[1:])
+	default:
+		err = errUsage
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	return err
+}
+
+// adminStores selects the stores an admin command needs besides the users.
+type adminStores struct {
+	keys, refreshTokens bool
+}
+
+// withAdmin opens the stores an admin command needs. Commands change the
+// stores directly, so they work while the service is running.
+func withAdmin(cfg *config.Config, need adminStores, fn func(admin *services.AdminService, s *storage) error) error {
+	if cfg.Storage == "memory" {
+		return errors.New("admin commands need persistent storage, not AUTH_STORAGE=memory")
+	}
+
+	store, err := openAdminStorage(cfg, need)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return fn(services.NewAdminService(store.users, store.keys, store.refreshTokens), store)
+}
+
+// openAdminStorage opens the SQLite database and the stores selected by
+// need. Unlike serving, it creates no signing keys.
+func openAdminStorage(cfg *config.Config, need adminStores) (*storage, error) {
+	s := &storage{}
+	err := s.openSQLite(cfg)
+	if err == nil && need.keys {
+		_, err = s.openKeys(cfg)
+	}
+	if err == nil && need.refreshTokens {
+		err = s.openRefreshTokens(cfg)
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func userCommand(admin *services.AdminService, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	password := flags.String("password", "", "the new password; read from stdin if empty")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+
+	switch {
+	case args[0] == "list" && flags.NArg() == 0:
+		users, err := admin.ListUsers()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUSERNAME\tDISABLED\tCREATED")
+		for _, user := range users {
+			fmt.Fprintf(w, "%d\t%s\t%t\t%s\n", user.ID, user.Username, user.Disabled, formatTime(user.CreatedAt))
+		}
+		return w.Flush()
+	case flags.NArg() != 1:
+		return errUsage
+	}
+
+	username := flags.Arg(0)
+	switch args[0] {
+	case "create":
+		pw, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+		user, err := admin.CreateUser(username, pw)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created user %s with ID %d.\n", user.Username, user.ID)
+	case "disable":
+		if err := admin.DisableUser(context.Background(), username); err != nil {
+			return err
+		}
+		fmt.Printf("Disabled user %s.\n", username)
+	case "enable":
+		if err := admin.EnableUser(username); err != nil {
+			return err
+		}
+		fmt.Printf("Enabled user %s.\n", username)
+	case "reset-password":
+		pw, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+		if err := admin.ResetPassword(context.Background(), username, pw); err != nil {
+			return err
+		}
+		fmt.Printf("Reset the password of %s.\n", username)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+// readPassword returns the password given as flag or else the first line
+// of stdin, so it does not have to appear in the shell history.
+func readPassword(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func keysCommand(admin *services.AdminService, s *storage, args []string) error {
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		keys, err := admin.ListKeys()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTATE\tENCRYPTED\tCREATED\tACTIVATED\tDEACTIVATED\tRETIRED")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%d\t%s\t%t\t%s\t%s\t%s\t%s\n", k.ID, k.State(), k.Encrypted, formatTime(k.CreatedAt),
+				formatTimePtr(k.ActivatedAt), formatTimePtr(k.DeactivatedAt), formatTimePtr(k.RetiredAt))
+		}
+		return w.Flush()
+	case len(args) == 1 && args[0] == "rotate":
+		if err := admin.RotateKey(); err != nil {
+			return err
+		}
+		fmt.Println("Rotated the signing key.")
+	case len(args) == 2 && args[0] == "retire":
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errUsage
+		}
+		if err := admin.RetireKey(id); err != nil {
+			return err
+		}
+		fmt.Printf("Retired signing key %d.\n", id)
+	case len(args) == 1 && args[0] == "encrypt":
+		n, err := s.keys.(*repository.SQLiteKeyRepository).EncryptPlaintextKeys(context.Background())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Encrypted %d signing keys.\n", n)
+	case len(args) == 2 && args[0] == "rewrap":
+		next, err := repository.LoadMasterKey("", args[1])
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return errors.New("new master key file is empty")
+		}
+		n, err := s.keys.(*repository.SQLiteKeyRepository).RewrapKeys(context.Background(), next)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Re-wrapped %d signing keys with master key %s; configure it before restarting.\n", n, next.ID)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func sessionsCommand(admin *services.AdminService, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("sessions "+args[0], flag.ContinueOnError)
+	all := flags.Bool("all", false, "revoke all of the user's sessions")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "list" && flags.NArg() == 1:
+		sessions, err := admin.ListSessions(ctx, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCREATED\tEXPIRES")
+		for _, session := range sessions {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", session.ID, formatTime(session.CreatedAt), formatTime(session.ExpiresAt))
+		}
+		return w.Flush()
+	case args[0] == "revoke" && *all && flags.NArg() == 1:
+		n, err := admin.RevokeSessions(ctx, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Revoked %d sessions.\n", n)
+	case args[0] == "revoke" && !*all && flags.NArg() == 2:
+		if err := admin.RevokeSession(ctx, flags.Arg(0), flags.Arg(1)); err != nil {
+			return err
+		}
+		fmt.Println("Revoked the session.")
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+// migrateCommand works on the SQLite file without migrating it on open, as
+// the other commands do.
+func migrateCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	db, err := repository.OpenSQLiteWithoutMigrations(cfg.SQLitePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s.\n", m.Version, m.Name)
+		}
+		fmt.Printf("Applied %d migrations.\n", len(applied))
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		for _, m := range reverted {
+			fmt.Printf("Reverted %04d_%s.\n", m.Version, m.Name)
+		}
+		fmt.Printf("Reverted %d migrations.\n", len(reverted))
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format(time.DateTime)
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return formatTime(*t)
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"forum-app/auth-service/internal/config"
+	"forum-app/auth-service/internal/service"
+)
+
+func TestAdminOpensOnlyNeededStores(t *testing.T) {
+	// Nothing listens on the Redis URL, so opening the refresh token store fails
+	cfg := &config.Config{
+		Storage:           "persistent",
+		SQLitePath:        filepath.Join(t.TempDir(), "keys.db"),
+		RefreshTokenStore: "redis",
+		RedisURL:          "redis://127.0.0.1:1",
+	}
+
+	err := withAdmin(cfg, adminStores{keys: true}, func(admin *services.AdminService, s *storage) error {
+		if err := keysCommand(admin, s, []string{"list"}); err != nil {
+			return err
+		}
+		var n int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM keys").Scan(&n); err != nil {
+			return err
+		}
+		if n != 0 {
+			t.Errorf("keys list created %d keys", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("keys list: %v", err)
+	}
+
+	err = withAdmin(cfg, adminStores{}, func(admin *services.AdminService, _ *storage) error {
+		return userCommand(admin, []string{"list"})
+	})
+	if err != nil {
+		t.Errorf("user list: %v", err)
+	}
+	if err := withAdmin(cfg, adminStores{refreshTokens: true}, func(*services.AdminService, *storage) error { return nil }); err == nil {
+		t.Error("sessions commands opened without the refresh token store")
+	}
+}
@@ -1,78 +1,61 @@
 package config
 
 import (
-	"log"
-	"os"
-	"strconv"
+	"log/slog"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
+// Config is the service's configuration. Every setting has a key used in
+// config files and, with dashes, as flag name, an environment variable and
+// maybe a default; see Loader for the order the sources are read in.
+// Settings tagged reload are safe to change while running, see Live.
 type Config struct {
-	Port            string
-	SQLitePath      string
-	MongoDBURI      string
-	MongoDBName     string
-	JWTSigningKey   string
-	AccessTokenTTL  time.Duration
-	RefreshTokenTTL time.Duration
-}
-
-func LoadConfig() *Config {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Println("Error loading .env file")
-	}
-
-	accessTokenTTL, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL"))
-	if err != nil {
-		accessTokenTTL = time.Minute * 15
-	}
-
-	refreshTokenTTL, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
-	if err != nil {
-		refreshTokenTTL = time.Hour * 24 * 7 // 7 days
-	}
-	return &Config{
-		Port:            os.Getenv("AUTH_SERVICE_PORT"),
-		SQLitePath:      os.Getenv("SQLITE_PATH"),
-		MongoDBURI:      os.Getenv("MONGODB_URI"),
-		MongoDBName:     os.Getenv("MONGODB_NAME"),
-		JWTSigningKey:   os.Getenv("JWT_SIGNING_KEY"),
-		AccessTokenTTL:  accessTokenTTL,
-		RefreshTokenTTL: refreshTokenTTL,
-	}
-}
-
-func GetString(key string, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
-}
-
-func GetInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
-	}
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		return defaultValue
-	}
-	return value
-}
-
-func GetBool(key string, defaultValue bool) bool {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
-	}
-	value, err := strconv.ParseBool(valueStr)
-	if err != nil {
-		return defaultValue
-	}
-	return value
+	Port string `key:"port" env:"AUTH_SERVICE_PORT" default:":8080"`
+	// Storage is "persistent" or "memory". In memory mode signing keys and
+	// refresh tokens live in the process only and nothing else is opened.
+	Storage    string `key:"storage" env:"AUTH_STORAGE" default:"persistent"`
+	SQLitePath string `key:"sqlite_path" env:"SQLITE_PATH" default:"./keys.db"`
+	// MasterKey encrypts the signing keys in SQLite, given as base64 of 32
+	// bytes directly or in MasterKeyFile. Without one keys are plaintext.
+	MasterKey     string `key:"master_key" env:"SIGNING_KEY_MASTER_KEY" secret:"value"`
+	MasterKeyFile string `key:"master_key_file" env:"SIGNING_KEY_MASTER_KEY_FILE"`
+	// RefreshTokenStore is "mongodb", "sqlite" or "redis"; with "sqlite"
+	// refresh tokens are kept in the same file as the signing keys.
+	RefreshTokenStore string `key:"refresh_token_store" env:"REFRESH_TOKEN_STORE" default:"mongodb"`
+	// How often expired refresh tokens are deleted from stores that do not
+	// expire them on their own.
+	RefreshTokenCleanupInterval time.Duration `key:"refresh_token_cleanup_interval" env:"REFRESH_TOKEN_CLEANUP_INTERVAL" default:"1h"`
+	// RedisURL points to a Redis-protocol server shared by all instances.
+	// When set, login rate limits and revoked tokens are kept there instead
+	// of in each process.
+	RedisURL string `key:"redis_url" env:"REDIS_URL" secret:"url"`
+	// At most LoginRateLimit login attempts per username are allowed within
+	// LoginRateWindow; 0 disables the limit.
+	LoginRateLimit  int           `key:"login_rate_limit" env:"LOGIN_RATE_LIMIT" default:"10" reload:"true"`
+	LoginRateWindow time.Duration `key:"login_rate_window" env:"LOGIN_RATE_WINDOW" default:"1m" reload:"true"`
+	MongoDBURI      string        `key:"mongodb_uri" env:"MONGODB_URI" default:"mongodb://localhost:27017" secret:"url"`
+	MongoDBName     string        `key:"mongodb_name" env:"MONGODB_NAME" default:"auth_db"`
+	JWTSigningKey   string        `key:"jwt_signing_key" env:"JWT_SIGNING_KEY" secret:"value"`
+	AccessTokenTTL  time.Duration `key:"access_token_ttl" env:"ACCESS_TOKEN_TTL" default:"15m" reload:"true"`
+	RefreshTokenTTL time.Duration `key:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" default:"168h" reload:"true"`
+	// Tokens name the service as issuer and the forum as audience, and are
+	// accepted TokenLeeway before and after their validity for clock skew.
+	TokenIssuer   string        `key:"token_issuer" env:"TOKEN_ISSUER" default:"auth-service"`
+	TokenAudience string        `key:"token_audience" env:"TOKEN_AUDIENCE" default:"forum-app"`
+	TokenLeeway   time.Duration `key:"token_leeway" env:"TOKEN_LEEWAY" default:"30s" reload:"true"`
+	// How often the access token signing key is replaced; 0 only rotates on
+	// request. Replaced keys verify tokens for another AccessTokenTTL and
+	// are deleted KeyRetention after that, or never with 0.
+	KeyRotationInterval time.Duration `key:"key_rotation_interval" env:"KEY_ROTATION_INTERVAL" default:"15m" reload:"true"`
+	KeyRetention        time.Duration `key:"key_retention" env:"KEY_RETENTION" default:"720h" reload:"true"`
+	// AdminToken guards the /admin endpoints as bearer token; empty turns
+	// them off.
+	AdminToken string `key:"admin_token" env:"AUTH_ADMIN_TOKEN" secret:"value"`
+	// ServiceToken lets other services look users up as bearer token; empty
+	// leaves lookups to signed-in users.
+	ServiceToken string `key:"service_token" env:"AUTH_SERVICE_TOKEN" secret:"value"`
+	// Where trust levels for token claims are read from; empty leaves every
+	// user at level 0.
+	CoreServiceURL string     `key:"core_service_url" env:"CORE_SERVICE_URL"`
+	LogLevel       slog.Level `key:"log_level" env:"LOG_LEVEL" default:"info" reload:"true"`
 }
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Live holds the configuration of the running service. Readers call Get for
+// every use, so a Reload takes effect on their next request.
+type Live struct {
+	loader  *Loader
+	current atomic.Pointer[Config]
+
+	mu sync.Mutex // serializes Reload
+	// loaded is the configuration last read, which differs from current in
+	// settings waiting for a restart. Reload compares against it so that
+	// such a change is logged once.
+	loaded *Config
+}
+
+func NewLive(loader *Loader, cfg *Config) *Live {
+	l := &Live{loader: loader, loaded: cfg}
+	l.current.Store(cfg)
+	return l
+}
+
+// Get returns the current configuration, which must not be modified.
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// Reload loads the configuration again and applies the settings tagged
+// reload. Changes to other settings are logged and wait for a restart. An
+// invalid configuration is rejected as a whole.
+func (l *Live) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next, err := l.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	cfg := *l.Get()
+	current, prev, loaded := reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(l.loaded).Elem(), reflect.ValueOf(next).Elem()
+	for _, f := range fields {
+		to := loaded.Field(f.index)
+		if prev.Field(f.index).Equal(to) {
+			continue
+		}
+		if !f.reload {
+			log.Printf("Config %s changed, restart to apply it", f.key)
+			continue
+		}
+		log.Printf("Config %s changed to %s", f.key, redact(f, fmt.Sprint(to.Interface())))
+		current.Field(f.index).Set(to)
+	}
+	l.loaded = next
+	l.current.Store(&cfg)
+	return nil
+}
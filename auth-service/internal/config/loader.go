@@ -0,0 +1,296 @@
+package config
+
+import (
+	"cmp"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// field describes one setting of Config, read from its struct tags.
+type field struct {
+	index  int
+	key    string
+	env    string
+	def    string
+	secret string // "value" hides the whole value, "url" only the password
+	reload bool
+}
+
+func (f field) flag() string {
+	return strings.ReplaceAll(f.key, "_", "-")
+}
+
+var fields = configFields()
+
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	fields := make([]field, t.NumField())
+	for i := range fields {
+		tag := t.Field(i).Tag
+		fields[i] = field{
+			index:  i,
+			key:    tag.Get("key"),
+			env:    tag.Get("env"),
+			def:    tag.Get("default"),
+			secret: tag.Get("secret"),
+			reload: tag.Get("reload") == "true",
+		}
+	}
+	return fields
+}
+
+// Errors lists everything wrong with a configuration at once.
+type Errors []error
+
+func (e Errors) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Loader reads the configuration from its sources, later ones overriding
+// earlier ones:
+//
+//  1. the defaults,
+//  2. the .env file in the working directory,
+//  3. the YAML or TOML file named by -config or AUTH_CONFIG_FILE,
+//  4. the environment,
+//  5. the flags.
+//
+// The environment and flags are those the process started with; the files
+// are read again on every Load.
+type Loader struct {
+	flags   map[string]string
+	environ map[string]string
+}
+
+// NewLoader parses the configuration flags at the start of args and returns
+// the arguments after them.
+func NewLoader(args []string) (*Loader, []string, error) {
+	flags := flag.NewFlagSet("auth-service", flag.ContinueOnError)
+	flags.String("config", "", "read settings from this YAML or TOML `file`")
+	for _, f := range fields {
+		flags.String(f.flag(), "", "sets "+f.key+", like $"+f.env)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	l := &Loader{flags: make(map[string]string), environ: make(map[string]string)}
+	flags.Visit(func(f *flag.Flag) {
+		l.flags[f.Name] = f.Value.String()
+	})
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			l.environ[k] = v
+		}
+	}
+	return l, flags.Args(), nil
+}
+
+// Load reads and validates the configuration. The error lists all
+// problems found.
+func (l *Loader) Load() (*Config, error) {
+	cfg := &Config{}
+	v := reflect.ValueOf(cfg).Elem()
+	var errs Errors
+
+	for _, f := range fields {
+		if f.def != "" {
+			if err := set(v.Field(f.index), f.def); err != nil {
+				errs = append(errs, fmt.Errorf("default of %s: %w", f.key, err))
+			}
+		}
+	}
+
+	dotenv, err := readDotenv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, f := range fields {
+		if value := dotenv[f.env]; value != "" {
+			if err := set(v.Field(f.index), value); err != nil {
+				errs = append(errs, fmt.Errorf(".env: %s: %w", f.env, err))
+			}
+		}
+	}
+
+	path := cmp.Or(l.flags["config"], l.environ["AUTH_CONFIG_FILE"], dotenv["AUTH_CONFIG_FILE"])
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			value := values[key]
+			f, ok := fieldByKey(key)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown setting %s", path, key))
+				continue
+			}
+			if err := set(v.Field(f.index), value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if value := l.environ[f.env]; value != "" {
+			if err := set(v.Field(f.index), value); err != nil {
+				errs = append(errs, fmt.Errorf("$%s: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if value, ok := l.flags[f.flag()]; ok {
+			if err := set(v.Field(f.index), value); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", f.flag(), err))
+			}
+		}
+	}
+
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// readDotenv reads the .env file, if there is one. Empty variables count as
+// unset, as in the environment.
+func readDotenv() (map[string]string, error) {
+	env, err := godotenv.Read(".env")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+	return env, nil
+}
+
+// readFile reads a flat YAML or TOML file of settings, told apart by the
+// file's extension.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%s: unknown config file type %q, want .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("%s: %s: want a single value", path, key)
+		case nil:
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+func fieldByKey(key string) (field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// set parses s into the setting v.
+func set(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetInt(int64(n))
+	default:
+		panic("config: unsupported setting type " + v.Type().String())
+	}
+	return nil
+}
+
+// String lists the settings one per line with secrets redacted, so the
+// result can be logged.
+func (c *Config) String() string {
+	v := reflect.ValueOf(c).Elem()
+	var b strings.Builder
+	for _, f := range fields {
+		value := fmt.Sprint(v.Field(f.index).Interface())
+		if value != "" {
+			value = redact(f, value)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", f.key, value)
+	}
+	return b.String()
+}
+
+func redact(f field, value string) string {
+	switch f.secret {
+	case "value":
+		return "[redacted]"
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil {
+			return "[redacted]"
+		}
+		return u.Redacted()
+	}
+	return value
+}
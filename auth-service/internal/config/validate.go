@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// minSecretLength is the shortest secret accepted: 256 bits, the size of an
+// HS256 key.
+const minSecretLength = 32
+
+// placeholderSecrets are parts of the example values found in sample files
+// and documentation, which must never sign or authorize anything.
+var placeholderSecrets = []string{"your-secret", "change-me", "changeme", "placeholder", "example"}
+
+// Validate returns every problem with the configuration, or nothing if it
+// can be run with.
+func (c *Config) Validate() Errors {
+	var errs Errors
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+
+	if c.Port == "" {
+		fail("port", "must be set")
+	} else if _, _, err := net.SplitHostPort(c.Port); err != nil {
+		fail("port", "want [host]:port, got %q", c.Port)
+	}
+
+	switch c.Storage {
+	case "memory":
+	case "persistent":
+		if c.SQLitePath == "" {
+			fail("sqlite_path", "must be set with persistent storage")
+		}
+		switch c.RefreshTokenStore {
+		case "mongodb":
+			if c.MongoDBURI == "" {
+				fail("mongodb_uri", "must be set with the mongodb refresh token store")
+			}
+			if c.MongoDBName == "" {
+				fail("mongodb_name", "must be set with the mongodb refresh token store")
+			}
+		case "sqlite":
+		case "redis":
+			if c.RedisURL == "" {
+				fail("redis_url", "must be set with the redis refresh token store")
+			}
+		default:
+			fail("refresh_token_store", "want mongodb, sqlite or redis, got %q", c.RefreshTokenStore)
+		}
+	default:
+		fail("storage", "want persistent or memory, got %q", c.Storage)
+	}
+	if c.MasterKey != "" && c.MasterKeyFile != "" {
+		fail("master_key", "set either master_key or master_key_file, not both")
+	}
+
+	if c.JWTSigningKey == "" {
+		fail("jwt_signing_key", "must be set")
+	} else {
+		checkSecret(fail, "jwt_signing_key", c.JWTSigningKey)
+	}
+	if c.AdminToken != "" {
+		checkSecret(fail, "admin_token", c.AdminToken)
+	}
+	if c.ServiceToken != "" {
+		checkSecret(fail, "service_token", c.ServiceToken)
+	}
+	if c.AccessTokenTTL <= 0 {
+		fail("access_token_ttl", "must be positive")
+	}
+	if c.RefreshTokenTTL <= c.AccessTokenTTL {
+		fail("refresh_token_ttl", "must be longer than access_token_ttl")
+	}
+	if c.TokenIssuer == "" {
+		fail("token_issuer", "must be set")
+	}
+	if c.TokenAudience == "" {
+		fail("token_audience", "must be set")
+	}
+	if c.TokenLeeway < 0 || c.TokenLeeway >= c.AccessTokenTTL {
+		fail("token_leeway", "must be at least 0 and shorter than access_token_ttl")
+	}
+	if c.KeyRotationInterval != 0 && c.KeyRotationInterval < time.Minute {
+		fail("key_rotation_interval", "must be 0 or at least 1m")
+	}
+	if c.KeyRetention < 0 {
+		fail("key_retention", "must not be negative")
+	}
+	if c.RefreshTokenCleanupInterval <= 0 {
+		fail("refresh_token_cleanup_interval", "must be positive")
+	}
+
+	if c.LoginRateLimit < 0 {
+		fail("login_rate_limit", "must not be negative")
+	}
+	if c.LoginRateLimit > 0 && c.LoginRateWindow <= 0 {
+		fail("login_rate_window", "must be positive")
+	}
+
+	if c.RedisURL != "" {
+		checkURL(fail, "redis_url", c.RedisURL, "redis", "rediss")
+	}
+	if c.CoreServiceURL != "" {
+		checkURL(fail, "core_service_url", c.CoreServiceURL, "http", "https")
+	}
+	return errs
+}
+
+// checkSecret rejects secrets that are short or copied from an example.
+func checkSecret(fail func(key, format string, args ...any), key, value string) {
+	if len(value) < minSecretLength {
+		fail(key, "must be at least %d bytes long", minSecretLength)
+		return
+	}
+	lower := strings.ToLower(value)
+	for _, placeholder := range placeholderSecrets {
+		if strings.Contains(lower, placeholder) {
+			fail(key, "must not be a placeholder value")
+			return
+		}
+	}
+}
+
+func checkURL(fail func(key, format string, args ...any), key, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	if err != nil {
+		// The error repeats the URL, which may hold a password.
+		fail(key, "not a valid URL")
+		return
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	fail(key, "want a %s URL, got scheme %q", schemes[0], u.Scheme)
+}
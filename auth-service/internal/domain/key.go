@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Signing keys go through these states: the next key is published ahead of
+// use, so every instance can verify tokens signed with it once it becomes
+// the active key; previous keys only verify tokens until those expire;
+// retired keys are no longer used at all.
+const (
+	KeyNext     = "next"
+	KeyActive   = "active"
+	KeyPrevious = "previous"
+	KeyRetired  = "retired"
+)
+
+// SigningKey describes a signing key without its key material.
+type SigningKey struct {
+	ID            int64      `json:"id"`
+	Active        bool       `json:"active"`
+	Encrypted     bool       `json:"encrypted"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ActivatedAt   *time.Time `json:"activated_at,omitempty"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	RetiredAt     *time.Time `json:"retired_at,omitempty"`
+}
+
+func (k SigningKey) State() string {
+	switch {
+	case k.RetiredAt != nil:
+		return KeyRetired
+	case k.Active:
+		return KeyActive
+	case k.ActivatedAt == nil:
+		return KeyNext
+	}
+	return KeyPrevious
+}
@@ -14,6 +14,8 @@ type TokenDetails struct {
 type AccessDetails struct {
 	AccessUuid string
 	UserId     int
+	TrustLevel int
+	ExpiresAt  time.Time
 }
 
 type RefreshToken struct {
@@ -1,7 +1,12 @@
 package domain
 
+import "time"
+
 type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Password string `json:"-"` // Don't expose password
+	// Disabled users can neither log in nor refresh their tokens.
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
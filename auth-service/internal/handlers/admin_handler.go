@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"forum-app/auth-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	keys *services.KeyManager
+}
+
+func NewAdminHandler(keys *services.KeyManager) *AdminHandler {
+	return &AdminHandler{keys: keys}
+}
+
+// RotateKey activates the next signing key right away, e.g. when the active
+// one may have leaked.
+func (h *AdminHandler) RotateKey(c *gin.Context) {
+	id, err := h.keys.Rotate()
+	if err != nil {
+		log.Printf("Error rotating key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate the signing key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"active_key_id": id})
+}
+
+// AdminMiddleware lets through requests bearing the admin token.
+func AdminMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetupAdminRoutes adds the operator endpoints, unless there is no admin
+// token to guard them.
+func SetupAdminRoutes(router *gin.Engine, keys *services.KeyManager, token string) {
+	if token == "" {
+		log.Println("No admin token configured, the admin API is off.")
+		return
+	}
+	handler := NewAdminHandler(keys)
+	admin := router.Group("/admin")
+	admin.Use(AdminMiddleware(token))
+	{
+		admin.POST("/keys/rotate", handler.RotateKey)
+	}
+}
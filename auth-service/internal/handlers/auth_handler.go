@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"forum-app/auth-service/internal/repository"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"forum-app/auth-service/internal/domain"
+	userRepository "forum-app/auth-service/internal/repository/user"
 	"forum-app/auth-service/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -34,6 +38,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	tokens, err := h.authService.Login(context.Background(), req.Username, req.Password)
+	if errors.Is(err, services.ErrTooManyAttempts) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -53,15 +61,97 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.RefreshToken(context.Background(), req.RefreshToken)
+	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		tokenError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
 }
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout revokes the access token from the Authorization header and, if one
+// is sent, the refresh token.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	accessToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if err := h.authService.Logout(c.Request.Context(), accessToken, req.RefreshToken); err != nil {
+		tokenError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type ValidateRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Validate lets other services check an access token and resolve its user.
+func (h *AuthHandler) Validate(c *gin.Context) {
+	var req ValidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	accessDetails, err := h.authService.VerifyAccessToken(req.Token)
+	if err != nil && !services.IsTokenError(err) {
+		log.Printf("Error validating token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"valid": false})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":       true,
+		"user_id":     accessDetails.UserId,
+		"trust_level": accessDetails.TrustLevel,
+	})
+}
+
+// GetUser exposes the public part of a user record, e.g. to resolve @mentions.
+// Disabled users are not found.
+func (h *AuthHandler) GetUser(c *gin.Context) {
+	user, err := h.authService.FindUser(c.Param("username"))
+	if errors.Is(err, userRepository.ErrUserNotFound) || err == nil && user.Disabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": userRepository.ErrUserNotFound.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to find the user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": user.ID, "username": user.Username})
+}
+
+// tokenError responds 401 with the reason if the token was rejected and
+// 500 if the service failed to check it.
+func tokenError(c *gin.Context, err error) {
+	if !services.IsTokenError(err) {
+		log.Printf("Error checking token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check the token"})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
+
 // Middleware для проверки access token
 func (h *AuthHandler) AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -85,6 +175,27 @@ func (h *AuthHandler) AuthMiddleware(authService services.AuthService) gin.Handl
 	}
 }
 
+// LookupMiddleware lets through other services bearing the service token and
+// signed-in users, so user records cannot be enumerated anonymously.
+func (h *AuthHandler) LookupMiddleware(authService services.AuthService, serviceToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || given == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			return
+		}
+		if serviceToken != "" && subtle.ConstantTimeCompare([]byte(given), []byte(serviceToken)) == 1 {
+			c.Next()
+			return
+		}
+		if _, err := authService.VerifyAccessToken(given); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid access token"})
+			return
+		}
+		c.Next()
+	}
+}
+
 func (h *AuthHandler) Protected(c *gin.Context) {
 	accessDetails, exists := c.Get("access_details")
 	if !exists {
@@ -118,10 +229,18 @@ func GetUserID(c *gin.Context) (int, error) {
 	return accessDetailsPtr.UserId, nil
 }
 
-func SetupAuthRoutes(router *gin.Engine, authService services.AuthService) {
+func SetupAuthRoutes(router *gin.Engine, authService services.AuthService, serviceToken string) {
 	handler := NewAuthHandler(authService)
 	router.POST("/login", handler.Login)
 	router.POST("/refresh", handler.Refresh)
+	router.POST("/logout", handler.Logout)
+	router.POST("/validate", handler.Validate)
+
+	users := router.Group("/users")
+	users.Use(handler.LookupMiddleware(authService, serviceToken))
+	{
+		users.GET("/:username", handler.GetUser)
+	}
 
 	// Protected routes
 	protected := router.Group("/protected")
@@ -131,14 +250,25 @@ func SetupAuthRoutes(router *gin.Engine, authService services.AuthService) {
 	}
 }
 
-// Функция для периодической ротации ключей
-func RotateKeysPeriodically(keyRepository repository.KeyRepository, interval time.Duration) {
+// CleanupRefreshTokensPeriodically deletes expired refresh tokens every
+// interval until ctx is done.
+func CleanupRefreshTokensPeriodically(ctx context.Context, cleaner repository.RefreshTokenCleaner, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
-	for range ticker.C {
-		_, err := keyRepository.RotateKey()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := cleaner.DeleteExpired(ctx, time.Now())
 		if err != nil {
-			log.Printf("Error rotating key: %v", err)
+			log.Printf("Error deleting expired refresh tokens: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("Deleted %d expired refresh tokens", n)
 		}
 	}
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// DenylistRepository remembers revoked access tokens by their access_uuid
+// until they would have expired anyway.
+type DenylistRepository interface {
+	Deny(ctx context.Context, id string, until time.Time) error
+	IsDenied(ctx context.Context, id string) (bool, error)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrMasterKeyRequired is returned when reading an encrypted signing key
+	// without a master key configured.
+	ErrMasterKeyRequired = errors.New("signing key is encrypted but no master key is configured")
+	// ErrWrongMasterKey is returned when a signing key was wrapped with
+	// another master key than the configured one.
+	ErrWrongMasterKey = errors.New("signing key was encrypted with another master key")
+)
+
+const dataKeySize = 32
+
+// MasterKey protects signing keys with envelope encryption: every signing key
+// is encrypted with its own random data key, and only the data key is
+// encrypted with the master key. Changing the master key thus re-wraps the
+// data keys and never touches the signing keys themselves. Both layers are
+// bound to the key's ID, so a row copied over another one fails to decrypt
+// instead of swapping in a key under a different ID.
+type MasterKey struct {
+	// ID identifies the master key without revealing it. Every encrypted row
+	// records the ID of the master key that wrapped its data key.
+	ID   string
+	aead cipher.AEAD
+}
+
+// NewMasterKey creates a master key from 32 bytes of key material.
+func NewMasterKey(key []byte) (*MasterKey, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &MasterKey{ID: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// ParseMasterKey decodes a base64 encoded master key, as produced by
+// `openssl rand -base64 32`.
+func ParseMasterKey(encoded string) (*MasterKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	return NewMasterKey(key)
+}
+
+// LoadMasterKey parses the master key given directly or, if value is empty,
+// read from file. It returns nil if neither is set.
+func LoadMasterKey(value, file string) (*MasterKey, error) {
+	if value == "" && file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master key: %w", err)
+		}
+		value = string(content)
+	}
+	if value == "" {
+		return nil, nil
+	}
+	return ParseMasterKey(value)
+}
+
+// seal encrypts the plaintext of key id under a new data key and returns the
+// ciphertext together with the wrapped data key.
+func (m *MasterKey) seal(id int64, plaintext []byte) (ciphertext, wrappedKey []byte, err error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ciphertext, err = encrypt(aead, plaintext, keyID(id)); err != nil {
+		return nil, nil, err
+	}
+	if wrappedKey, err = m.wrap(id, dataKey); err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, wrappedKey, nil
+}
+
+func (m *MasterKey) open(id int64, ciphertext, wrappedKey []byte) ([]byte, error) {
+	dataKey, err := m.unwrap(id, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(aead, ciphertext, keyID(id))
+}
+
+func (m *MasterKey) wrap(id int64, dataKey []byte) ([]byte, error) {
+	return encrypt(m.aead, dataKey, keyID(id))
+}
+
+func (m *MasterKey) unwrap(id int64, wrappedKey []byte) ([]byte, error) {
+	return decrypt(m.aead, wrappedKey, keyID(id))
+}
+
+// keyID encodes a key's ID as additional data for the AEAD.
+func keyID(id int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns the random nonce followed by the sealed plaintext, which
+// only decrypts together with the same additional data.
+func encrypt(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func decrypt(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+	}
+	return plaintext, nil
+}
@@ -1,106 +1,407 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
-	_ "github.com/glebarez/sqlite" // SQLite driver
+	"forum-app/auth-service/internal/domain"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrKeyNotFound = errors.New("signing key not found")
+	// ErrKeyActive is returned when retiring the key tokens are signed with.
+	ErrKeyActive = errors.New("the active signing key cannot be retired")
+)
+
+// KeyRepository stores the signing keys through their life cycle, see
+// domain.SigningKey. There is always an active key and, after the first
+// rotation, a next key.
 type KeyRepository interface {
-	GetCurrentKey() (string, error)
-	RotateKey() (string, error)
+	// GetSigningKeys returns the material of all keys tokens may be verified
+	// with, which are all but the retired ones, and the active key's ID.
+	GetSigningKeys() (activeID int64, keys map[int64]string, err error)
+	// RotateKey activates the next key and publishes a new next key. With a
+	// non-zero dueBefore it only rotates if the active key was activated
+	// before then, and reports whether it did.
+	RotateKey(dueBefore time.Time) (bool, error)
+	// ListKeys describes all keys, newest first, without their material.
+	ListKeys() ([]domain.SigningKey, error)
+	// RetireKey marks an inactive key as no longer usable.
+	RetireKey(id int64) error
+	// RetireKeys retires the keys deactivated before the given time.
+	RetireKeys(deactivatedBefore time.Time) (int64, error)
+	// DeleteRetiredKeys deletes the keys retired before the given time.
+	DeleteRetiredKeys(retiredBefore time.Time) (int64, error)
 }
 
+// SQLiteKeyRepository stores signing keys encrypted with the master key, or
+// in plaintext if there is none.
 type SQLiteKeyRepository struct {
-	db         *sql.DB
-	dbFilePath string
+	db     *sql.DB
+	master *MasterKey
+}
+
+// NewSQLiteKeyRepository returns the repository without touching the keys;
+// the service calls EnsureActiveKey before it signs with them.
+func NewSQLiteKeyRepository(db *sql.DB, master *MasterKey) *SQLiteKeyRepository {
+	return &SQLiteKeyRepository{db: db, master: master}
 }
 
-func NewSQLiteKeyRepository(dbFilePath string) (KeyRepository, error) {
-	db, err := openOrCreateSQLiteDB(dbFilePath)
+// nextKey selects the next key, the oldest one published but never active.
+const nextKey = "SELECT id FROM keys WHERE is_active = 0 AND activated_at IS NULL AND retired_at IS NULL ORDER BY id LIMIT 1"
+
+// EnsureActiveKey creates the active and the next key if they are missing,
+// and reminds of keys left in plaintext. Instances starting together may
+// both try, so the checks are part of the inserts.
+func (r *SQLiteKeyRepository) EnsureActiveKey() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	created, err := r.insertKey(tx, true, "SELECT 1 FROM keys WHERE is_active = 1")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create key repository: %w", err)
+		return err
+	}
+	if _, err := r.insertKey(tx, false, nextKey); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if created {
+		log.Println("Generated initial signing key.")
 	}
 
-	return &SQLiteKeyRepository{db: db, dbFilePath: dbFilePath}, nil
+	if r.master == nil {
+		log.Println("No master key configured, signing keys are stored in plaintext.")
+		return nil
+	}
+	var plaintext int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM keys WHERE key_ciphertext IS NULL").Scan(&plaintext); err != nil {
+		return err
+	}
+	if plaintext > 0 {
+		log.Printf("%d signing keys are stored in plaintext, run 'auth-service keys encrypt' to encrypt them.", plaintext)
+	}
+	return nil
 }
 
-func openOrCreateSQLiteDB(dbFilePath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dbFilePath)
+// insertKey stores a new key, encrypted if there is a master key, unless
+// the query unless returns a row. A key that is not active becomes the next
+// key. Encrypted keys are bound to their row's ID, so the row is inserted
+// first and filled in once the ID is known.
+func (r *SQLiteKeyRepository) insertKey(tx *sql.Tx, active bool, unless string) (bool, error) {
+	var activatedAt *time.Time
+	if active {
+		now := time.Now().UTC()
+		activatedAt = &now
+	}
+	key := uuid.New().String()
+	condition := ""
+	if unless != "" {
+		condition = " WHERE NOT EXISTS (" + unless + ")"
+	}
+
+	stored := key
+	if r.master != nil {
+		stored = ""
+	}
+	res, err := tx.Exec("INSERT INTO keys (key, is_active, activated_at) SELECT ?, ?, ?"+condition, stored, active, activatedAt)
 	if err != nil {
-		return nil, err
+		return false, err
+	}
+	if n, err := res.RowsAffected(); n == 0 || err != nil {
+		return false, err
+	}
+	if r.master == nil {
+		return true, nil
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	ciphertext, wrappedKey, err := r.master.seal(id, []byte(key))
+	if err != nil {
+		return false, err
 	}
+	_, err = tx.Exec("UPDATE keys SET key_ciphertext = ?, wrapped_key = ?, master_key_id = ? WHERE id = ?",
+		ciphertext, wrappedKey, r.master.ID, id)
+	return err == nil, err
+}
+
+func (r *SQLiteKeyRepository) GetSigningKeys() (int64, map[int64]string, error) {
+	rows, err := r.db.Query(
+		"SELECT id, is_active, key, key_ciphertext, wrapped_key, master_key_id FROM keys WHERE retired_at IS NULL")
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS keys (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			key TEXT NOT NULL,
-			is_active BOOLEAN NOT NULL DEFAULT 0,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
+	var activeID int64
+	keys := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var active bool
+		var key, masterKeyID string
+		var ciphertext, wrappedKey []byte
+		if err := rows.Scan(&id, &active, &key, &ciphertext, &wrappedKey, &masterKeyID); err != nil {
+			return 0, nil, err
+		}
+		if ciphertext != nil {
+			if key, err = r.decrypt(id, ciphertext, wrappedKey, masterKeyID); err != nil {
+				return 0, nil, fmt.Errorf("key %d: %w", id, err)
+			}
+		}
+		keys[id] = key
+		if active {
+			activeID = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if activeID == 0 {
+		return 0, nil, errors.New("no active signing key")
+	}
+	return activeID, keys, nil
+}
 
+func (r *SQLiteKeyRepository) decrypt(id int64, ciphertext, wrappedKey []byte, masterKeyID string) (string, error) {
+	if r.master == nil {
+		return "", ErrMasterKeyRequired
+	}
+	if masterKeyID != r.master.ID {
+		return "", fmt.Errorf("%w: %s", ErrWrongMasterKey, masterKeyID)
+	}
+	plaintext, err := r.master.open(id, ciphertext, wrappedKey)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// RotateKey deactivates the active key first; the write lock this takes
+// makes instances sharing the database rotate one after another, and the
+// due check lets only the first of them rotate.
+func (r *SQLiteKeyRepository) RotateKey(dueBefore time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	query, args := "UPDATE keys SET is_active = 0, deactivated_at = ? WHERE is_active = 1", []any{now}
+	if !dueBefore.IsZero() {
+		query, args = query+" AND activated_at <= ?", append(args, dueBefore.UTC())
+	}
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 && !dueBefore.IsZero() {
+		return false, nil
+	}
+
+	// Activate the next key, or a new one if none was published
+	res, err = tx.Exec("UPDATE keys SET is_active = 1, activated_at = ? WHERE id = ("+nextKey+")", now)
+	if err != nil {
+		return false, err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := r.insertKey(tx, true, ""); err != nil {
+			return false, err
+		}
+	}
+	if _, err := r.insertKey(tx, false, nextKey); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	log.Println("Rotated signing key.")
+	return true, nil
+}
 
-	// If no active key exists, create one
-	var count int
-	err = db.QueryRow("SELECT COUNT(*) FROM keys WHERE is_active = 1").Scan(&count)
+func (r *SQLiteKeyRepository) ListKeys() ([]domain.SigningKey, error) {
+	rows, err := r.db.Query(`SELECT id, is_active, key_ciphertext IS NOT NULL, created_at, activated_at, deactivated_at, retired_at
+		FROM keys ORDER BY id DESC`)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if count == 0 {
-		newKey := uuid.New().String()
-		_, err = db.Exec("INSERT INTO keys (key, is_active) VALUES (?, 1)", newKey)
-		if err != nil {
+	var keys []domain.SigningKey
+	for rows.Next() {
+		var k domain.SigningKey
+		var activatedAt, deactivatedAt, retiredAt sql.NullTime
+		if err := rows.Scan(&k.ID, &k.Active, &k.Encrypted, &k.CreatedAt, &activatedAt, &deactivatedAt, &retiredAt); err != nil {
 			return nil, err
 		}
-		log.Println("Generated initial signing key.")
+		k.ActivatedAt, k.DeactivatedAt, k.RetiredAt = timePtr(activatedAt), timePtr(deactivatedAt), timePtr(retiredAt)
+		keys = append(keys, k)
 	}
+	return keys, rows.Err()
+}
 
-	return db, nil
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
 }
 
-func (r *SQLiteKeyRepository) GetCurrentKey() (string, error) {
-	var key string
-	err := r.db.QueryRow("SELECT key FROM keys WHERE is_active = 1").Scan(&key)
+// RetireKey keeps the time a key was first retired if it is retired again.
+func (r *SQLiteKeyRepository) RetireKey(id int64) error {
+	var active bool
+	err := r.db.QueryRow("SELECT is_active FROM keys WHERE id = ?", id).Scan(&active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrKeyNotFound
+	}
 	if err != nil {
-		return "", err
+		return err
+	}
+	if active {
+		return ErrKeyActive
 	}
-	return key, nil
+
+	_, err = r.db.Exec("UPDATE keys SET retired_at = COALESCE(retired_at, ?) WHERE id = ?", time.Now().UTC(), id)
+	return err
 }
 
-func (r *SQLiteKeyRepository) RotateKey() (string, error) {
-	tx, err := r.db.Begin()
+func (r *SQLiteKeyRepository) RetireKeys(deactivatedBefore time.Time) (int64, error) {
+	res, err := r.db.Exec(
+		"UPDATE keys SET retired_at = ? WHERE is_active = 0 AND retired_at IS NULL AND deactivated_at <= ?",
+		time.Now().UTC(), deactivatedBefore.UTC())
 	if err != nil {
-		return "", err
+		return 0, err
 	}
+	return res.RowsAffected()
+}
 
-	// Deactivate the current key
-	_, err = tx.Exec("UPDATE keys SET is_active = 0 WHERE is_active = 1")
+func (r *SQLiteKeyRepository) DeleteRetiredKeys(retiredBefore time.Time) (int64, error) {
+	res, err := r.db.Exec("DELETE FROM keys WHERE is_active = 0 AND retired_at <= ?", retiredBefore.UTC())
 	if err != nil {
-		tx.Rollback()
-		return "", err
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// EncryptPlaintextKeys encrypts all keys still stored in plaintext with the
+// master key and returns how many it encrypted.
+func (r *SQLiteKeyRepository) EncryptPlaintextKeys(ctx context.Context) (int, error) {
+	if r.master == nil {
+		return 0, ErrMasterKeyRequired
 	}
 
-	// Generate a new key
-	newKey := uuid.New().String()
-	_, err = tx.Exec("INSERT INTO keys (key, is_active) VALUES (?, 1)", newKey)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		tx.Rollback()
-		return "", err
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	err = tx.Commit()
+	type plaintextKey struct {
+		id  int64
+		key string
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT id, key FROM keys WHERE key_ciphertext IS NULL")
 	if err != nil {
-		return "", err
+		return 0, err
+	}
+	var keys []plaintextKey
+	for rows.Next() {
+		var k plaintextKey
+		if err := rows.Scan(&k.id, &k.key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
 	}
 
-	log.Println("Rotated signing key.")
-	return newKey, nil
+	for _, k := range keys {
+		ciphertext, wrappedKey, err := r.master.seal(k.id, []byte(k.key))
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE keys SET key = '', key_ciphertext = ?, wrapped_key = ?, master_key_id = ? WHERE id = ?",
+			ciphertext, wrappedKey, r.master.ID, k.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), tx.Commit()
+}
+
+// RewrapKeys re-encrypts the data keys of all encrypted rows with next and
+// returns how many it re-wrapped. Rows already wrapped with next are skipped,
+// so an interrupted run can simply be repeated. Afterwards next has to be
+// configured as the master key.
+func (r *SQLiteKeyRepository) RewrapKeys(ctx context.Context, next *MasterKey) (int, error) {
+	if r.master == nil {
+		return 0, ErrMasterKeyRequired
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	type wrapped struct {
+		id          int64
+		key         []byte
+		masterKeyID string
+	}
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, wrapped_key, master_key_id FROM keys WHERE key_ciphertext IS NOT NULL AND master_key_id != ?", next.ID)
+	if err != nil {
+		return 0, err
+	}
+	var keys []wrapped
+	for rows.Next() {
+		var k wrapped
+		if err := rows.Scan(&k.id, &k.key, &k.masterKeyID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		if k.masterKeyID != r.master.ID {
+			return 0, fmt.Errorf("%w: key %d uses %s", ErrWrongMasterKey, k.id, k.masterKeyID)
+		}
+		dataKey, err := r.master.unwrap(k.id, k.key)
+		if err != nil {
+			return 0, err
+		}
+		wrappedKey, err := next.wrap(k.id, dataKey)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE keys SET wrapped_key = ?, master_key_id = ? WHERE id = ?",
+			wrappedKey, next.ID, k.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), tx.Commit()
 }
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "auth.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestMasterKey(t *testing.T) *MasterKey {
+	t.Helper()
+	key := make([]byte, dataKeySize)
+	rand.Read(key)
+	master, err := NewMasterKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return master
+}
+
+func newSQLiteKeyRepository(t *testing.T, db *sql.DB, master *MasterKey) *SQLiteKeyRepository {
+	t.Helper()
+	r := NewSQLiteKeyRepository(db, master)
+	if err := r.EnsureActiveKey(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func signingKeys(t *testing.T, r KeyRepository) map[int64]string {
+	t.Helper()
+	_, keys, err := r.GetSigningKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestMasterKeySealOpen(t *testing.T) {
+	master := newTestMasterKey(t)
+	plaintext := []byte("signing key")
+
+	ciphertext, wrappedKey, err := master.seal(7, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, plaintext) {
+		t.Error("ciphertext contains the plaintext")
+	}
+	got, err := master.open(7, ciphertext, wrappedKey)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("open = %q, %v", got, err)
+	}
+
+	if _, err := master.open(8, ciphertext, wrappedKey); err == nil {
+		t.Error("key opened under another ID")
+	}
+	if _, err := newTestMasterKey(t).open(7, ciphertext, wrappedKey); err == nil {
+		t.Error("key opened with another master key")
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := master.open(7, ciphertext, wrappedKey); err == nil {
+		t.Error("tampered ciphertext opened")
+	}
+
+	if _, err := ParseMasterKey("c2hvcnQ="); err == nil {
+		t.Error("short master key accepted")
+	}
+}
+
+func TestSQLiteKeyRepositoryEncrypted(t *testing.T) {
+	db := openTestSQLite(t)
+	master := newTestMasterKey(t)
+	r := newSQLiteKeyRepository(t, db, master)
+	if _, err := r.RotateKey(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	keys := signingKeys(t, r)
+	if len(keys) != 3 {
+		t.Fatalf("%d keys, want 3", len(keys))
+	}
+
+	var plaintext int
+	if err := db.QueryRow("SELECT COUNT(*) FROM keys WHERE key != '' OR key_ciphertext IS NULL").Scan(&plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext != 0 {
+		t.Errorf("%d keys stored in plaintext", plaintext)
+	}
+
+	// Another master key, or none, cannot read them
+	if _, _, err := newSQLiteKeyRepository(t, db, newTestMasterKey(t)).GetSigningKeys(); !errors.Is(err, ErrWrongMasterKey) {
+		t.Errorf("GetSigningKeys with another master key = %v, want ErrWrongMasterKey", err)
+	}
+	if _, _, err := (&SQLiteKeyRepository{db: db}).GetSigningKeys(); !errors.Is(err, ErrMasterKeyRequired) {
+		t.Errorf("GetSigningKeys without a master key = %v, want ErrMasterKeyRequired", err)
+	}
+
+	// A key copied into another row does not decrypt there
+	_, err := db.Exec(`UPDATE keys SET (key_ciphertext, wrapped_key) =
+		(SELECT key_ciphertext, wrapped_key FROM keys WHERE id = 1) WHERE id = 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.GetSigningKeys(); err == nil {
+		t.Error("key copied to another row decrypted")
+	}
+}
+
+func TestSQLiteKeyRepositoryEncryptPlaintextKeys(t *testing.T) {
+	db := openTestSQLite(t)
+	plain := newSQLiteKeyRepository(t, db, nil)
+	want := signingKeys(t, plain)
+	if _, err := plain.EncryptPlaintextKeys(t.Context()); !errors.Is(err, ErrMasterKeyRequired) {
+		t.Errorf("EncryptPlaintextKeys without a master key = %v, want ErrMasterKeyRequired", err)
+	}
+
+	master := newTestMasterKey(t)
+	r := newSQLiteKeyRepository(t, db, master)
+	for i, wantN := range []int{len(want), 0} {
+		n, err := r.EncryptPlaintextKeys(t.Context())
+		if err != nil || n != wantN {
+			t.Errorf("run %d: EncryptPlaintextKeys = %d, %v; want %d", i+1, n, err, wantN)
+		}
+	}
+	if got := signingKeys(t, r); !maps.Equal(got, want) {
+		t.Errorf("keys changed by encryption: %v, want %v", got, want)
+	}
+	var plaintext int
+	if err := db.QueryRow("SELECT COUNT(*) FROM keys WHERE key != ''").Scan(&plaintext); err != nil || plaintext != 0 {
+		t.Errorf("%d keys left in plaintext, %v", plaintext, err)
+	}
+}
+
+func TestSQLiteKeyRepositoryRewrapKeys(t *testing.T) {
+	db := openTestSQLite(t)
+	current, next := newTestMasterKey(t), newTestMasterKey(t)
+	r := newSQLiteKeyRepository(t, db, current)
+	if _, err := r.RotateKey(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	want := signingKeys(t, r)
+
+	// A repeated run, say after an interruption, has nothing left to do
+	for i, wantN := range []int{len(want), 0} {
+		n, err := r.RewrapKeys(t.Context(), next)
+		if err != nil || n != wantN {
+			t.Errorf("run %d: RewrapKeys = %d, %v; want %d", i+1, n, err, wantN)
+		}
+	}
+	if got := signingKeys(t, newSQLiteKeyRepository(t, db, next)); !maps.Equal(got, want) {
+		t.Errorf("keys with the new master key: %v, want %v", got, want)
+	}
+	if _, _, err := r.GetSigningKeys(); !errors.Is(err, ErrWrongMasterKey) {
+		t.Errorf("GetSigningKeys with the old master key = %v, want ErrWrongMasterKey", err)
+	}
+
+	// Rewrapping with a master key that does not match the rows fails
+	if _, err := r.RewrapKeys(t.Context(), newTestMasterKey(t)); !errors.Is(err, ErrWrongMasterKey) {
+		t.Errorf("RewrapKeys from the wrong master key = %v, want ErrWrongMasterKey", err)
+	}
+}
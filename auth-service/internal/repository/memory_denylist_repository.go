@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type InMemoryDenylistRepository struct {
+	mu        sync.RWMutex
+	entries   map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewInMemoryDenylistRepository() DenylistRepository {
+	return &InMemoryDenylistRepository{entries: make(map[string]time.Time)}
+}
+
+func (r *InMemoryDenylistRepository) Deny(ctx context.Context, id string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastSweep) >= time.Minute {
+		r.lastSweep = now
+		for key, expiresAt := range r.entries {
+			if !now.Before(expiresAt) {
+				delete(r.entries, key)
+			}
+		}
+	}
+
+	if until.After(now) {
+		r.entries[id] = until
+	}
+	return nil
+}
+
+func (r *InMemoryDenylistRepository) IsDenied(ctx context.Context, id string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	until, ok := r.entries[id]
+	return ok && time.Now().Before(until), nil
+}
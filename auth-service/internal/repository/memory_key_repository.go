@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+	"github.com/google/uuid"
+)
+
+type memoryKey struct {
+	info domain.SigningKey
+	key  string
+}
+
+// InMemoryKeyRepository keeps the signing keys in process memory. Tokens it
+// signed become invalid when the process exits.
+type InMemoryKeyRepository struct {
+	mu     sync.RWMutex
+	keys   []memoryKey // oldest first
+	lastID int64
+}
+
+func NewInMemoryKeyRepository() KeyRepository {
+	r := &InMemoryKeyRepository{}
+	r.RotateKey(time.Time{})
+	return r
+}
+
+func (r *InMemoryKeyRepository) GetSigningKeys() (int64, map[int64]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var activeID int64
+	keys := make(map[int64]string)
+	for _, k := range r.keys {
+		if k.info.RetiredAt != nil {
+			continue
+		}
+		keys[k.info.ID] = k.key
+		if k.info.Active {
+			activeID = k.info.ID
+		}
+	}
+	return activeID, keys, nil
+}
+
+func (r *InMemoryKeyRepository) RotateKey(dueBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+
+	var next *memoryKey
+	for i := range r.keys {
+		k := &r.keys[i]
+		switch k.info.State() {
+		case domain.KeyActive:
+			if !dueBefore.IsZero() && k.info.ActivatedAt.After(dueBefore) {
+				return false, nil
+			}
+			k.info.Active, k.info.DeactivatedAt = false, &now
+		case domain.KeyNext:
+			if next == nil {
+				next = k
+			}
+		}
+	}
+
+	if next == nil {
+		r.add()
+		next = &r.keys[len(r.keys)-1]
+	}
+	next.info.Active, next.info.ActivatedAt = true, &now
+	r.add()
+	return true, nil
+}
+
+// add publishes a new next key.
+func (r *InMemoryKeyRepository) add() {
+	r.lastID++
+	r.keys = append(r.keys, memoryKey{
+		info: domain.SigningKey{ID: r.lastID, CreatedAt: time.Now()},
+		key:  uuid.New().String(),
+	})
+}
+
+func (r *InMemoryKeyRepository) ListKeys() ([]domain.SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]domain.SigningKey, len(r.keys))
+	for i, k := range r.keys {
+		keys[len(r.keys)-1-i] = k.info
+	}
+	return keys, nil
+}
+
+func (r *InMemoryKeyRepository) RetireKey(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.keys {
+		k := &r.keys[i]
+		if k.info.ID != id {
+			continue
+		}
+		if k.info.Active {
+			return ErrKeyActive
+		}
+		if k.info.RetiredAt == nil {
+			now := time.Now()
+			k.info.RetiredAt = &now
+		}
+		return nil
+	}
+	return ErrKeyNotFound
+}
+
+func (r *InMemoryKeyRepository) RetireKeys(deactivatedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var n int64
+	for i := range r.keys {
+		k := &r.keys[i]
+		if k.info.State() == domain.KeyPrevious && !k.info.DeactivatedAt.After(deactivatedBefore) {
+			k.info.RetiredAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *InMemoryKeyRepository) DeleteRetiredKeys(retiredBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.keys[:0]
+	for _, k := range r.keys {
+		if k.info.RetiredAt == nil || k.info.RetiredAt.After(retiredBefore) {
+			kept = append(kept, k)
+		}
+	}
+	n := int64(len(r.keys) - len(kept))
+	r.keys = kept
+	return n, nil
+}
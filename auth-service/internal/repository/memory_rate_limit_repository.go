@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type rateWindow struct {
+	count   int64
+	resetAt time.Time
+}
+
+type InMemoryRateLimitRepository struct {
+	mu        sync.Mutex
+	windows   map[string]rateWindow
+	lastSweep time.Time
+}
+
+func NewInMemoryRateLimitRepository() RateLimitRepository {
+	return &InMemoryRateLimitRepository{windows: make(map[string]rateWindow)}
+}
+
+func (r *InMemoryRateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.sweep(now)
+
+	w, ok := r.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = rateWindow{resetAt: now.Add(window)}
+	}
+	w.count++
+	r.windows[key] = w
+	return w.count, nil
+}
+
+// sweep drops finished windows, at most once a minute.
+func (r *InMemoryRateLimitRepository) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < time.Minute {
+		return
+	}
+	r.lastSweep = now
+	for key, w := range r.windows {
+		if !now.Before(w.resetAt) {
+			delete(r.windows, key)
+		}
+	}
+}
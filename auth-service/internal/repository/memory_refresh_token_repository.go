@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+)
+
+// InMemoryRefreshTokenRepository behaves like SQLiteRefreshTokenRepository
+// without a database: expired tokens are not returned and stay until
+// DeleteExpired removes them.
+type InMemoryRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]domain.RefreshToken
+}
+
+func NewInMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &InMemoryRefreshTokenRepository{tokens: make(map[string]domain.RefreshToken)}
+}
+
+func (r *InMemoryRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.Token] = *token
+	return nil
+}
+
+func (r *InMemoryRefreshTokenRepository) Get(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	refreshToken, ok := r.tokens[token]
+	if !ok || !refreshToken.ExpiresAt.After(time.Now()) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	return &refreshToken, nil
+}
+
+func (r *InMemoryRefreshTokenRepository) Delete(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tokens[token]; !ok {
+		return ErrRefreshTokenNotFound
+	}
+	delete(r.tokens, token)
+	return nil
+}
+
+func (r *InMemoryRefreshTokenRepository) ListByUser(ctx context.Context, userID int) ([]domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := time.Now()
+	var tokens []domain.RefreshToken
+	for _, refreshToken := range r.tokens {
+		if refreshToken.UserID == userID && refreshToken.ExpiresAt.After(now) {
+			tokens = append(tokens, refreshToken)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.Before(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+func (r *InMemoryRefreshTokenRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for token, refreshToken := range r.tokens {
+		if refreshToken.UserID == userID {
+			delete(r.tokens, token)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *InMemoryRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for token, refreshToken := range r.tokens {
+		if !refreshToken.ExpiresAt.After(before) {
+			delete(r.tokens, token)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+)
+
+// The in-memory stores are shared by all requests; these tests hammer them
+// from many goroutines and are meant to be run with -race.
+
+const (
+	workers    = 8
+	iterations = 100
+)
+
+func runConcurrently(fn func(worker int)) {
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(w)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestInMemoryRefreshTokenRepositoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemoryRefreshTokenRepository()
+	expiresAt := time.Now().Add(time.Hour)
+
+	runConcurrently(func(w int) {
+		for i := range iterations {
+			token := fmt.Sprintf("token-%d-%d", w, i)
+			err := r.Create(ctx, &domain.RefreshToken{ID: token, UserID: w, Token: token, ExpiresAt: expiresAt, CreatedAt: time.Now()})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := r.Get(ctx, token); err != nil {
+				t.Errorf("Get of a token just created: %v", err)
+			}
+			if _, err := r.ListByUser(ctx, w); err != nil {
+				t.Error(err)
+			}
+			if i%2 == 1 {
+				if err := r.Delete(ctx, token); err != nil {
+					t.Error(err)
+				}
+				if err := r.Delete(ctx, token); !errors.Is(err, ErrRefreshTokenNotFound) {
+					t.Errorf("second Delete = %v, want ErrRefreshTokenNotFound", err)
+				}
+			}
+			if _, err := r.(RefreshTokenCleaner).DeleteExpired(ctx, time.Now()); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	for w := range workers {
+		tokens, err := r.ListByUser(ctx, w)
+		if err != nil || len(tokens) != iterations/2 {
+			t.Errorf("user %d has %d tokens, %v; want %d", w, len(tokens), err, iterations/2)
+		}
+	}
+
+	runConcurrently(func(w int) {
+		if n, err := r.DeleteByUser(ctx, w); err != nil || n != iterations/2 {
+			t.Errorf("DeleteByUser(%d) = %d, %v; want %d", w, n, err, iterations/2)
+		}
+	})
+}
+
+func TestInMemoryRateLimitRepositoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemoryRateLimitRepository()
+
+	var mu sync.Mutex
+	counts := make(map[int64]bool)
+	runConcurrently(func(int) {
+		for range iterations {
+			n, err := r.Hit(ctx, "login:user1", time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			if counts[n] {
+				t.Errorf("count %d returned twice", n)
+			}
+			counts[n] = true
+			mu.Unlock()
+		}
+	})
+
+	if len(counts) != workers*iterations || !counts[workers*iterations] {
+		t.Errorf("got %d distinct counts, want 1 to %d", len(counts), workers*iterations)
+	}
+}
+
+func TestInMemoryDenylistRepositoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemoryDenylistRepository()
+	until := time.Now().Add(time.Hour)
+
+	runConcurrently(func(w int) {
+		for i := range iterations {
+			id := fmt.Sprintf("%d-%d", w, i)
+			if err := r.Deny(ctx, id, until); err != nil {
+				t.Error(err)
+				return
+			}
+			if denied, err := r.IsDenied(ctx, id); err != nil || !denied {
+				t.Errorf("IsDenied(%s) = %v, %v right after Deny", id, denied, err)
+			}
+			// Tokens that have expired already need no entry
+			if err := r.Deny(ctx, "expired-"+id, time.Now().Add(-time.Second)); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	if denied, _ := r.IsDenied(ctx, "expired-0-0"); denied {
+		t.Error("expired token is denied")
+	}
+	if denied, _ := r.IsDenied(ctx, "unknown"); denied {
+		t.Error("unknown token is denied")
+	}
+}
+
+func TestInMemoryKeyRepositoryConcurrent(t *testing.T) {
+	r := NewInMemoryKeyRepository()
+
+	runConcurrently(func(w int) {
+		for i := range iterations / 10 {
+			switch (w + i) % 3 {
+			case 0:
+				if _, err := r.RotateKey(time.Time{}); err != nil {
+					t.Error(err)
+				}
+			case 1:
+				if _, err := r.RetireKeys(time.Now()); err != nil {
+					t.Error(err)
+				}
+			default:
+				activeID, keys, err := r.GetSigningKeys()
+				if err != nil {
+					t.Error(err)
+				} else if _, ok := keys[activeID]; !ok {
+					t.Errorf("active key %d is not among the signing keys", activeID)
+				}
+			}
+		}
+	})
+
+	keys, err := r.ListKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := make(map[string]int)
+	for _, k := range keys {
+		states[k.State()]++
+	}
+	if states[domain.KeyActive] != 1 || states[domain.KeyNext] != 1 {
+		t.Errorf("key states %v, want exactly one active and one next key", states)
+	}
+}
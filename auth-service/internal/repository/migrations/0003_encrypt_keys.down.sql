@@ -0,0 +1,4 @@
+-- Encrypted keys are lost; only revert a database whose keys are plaintext.
+ALTER TABLE keys DROP COLUMN master_key_id;
+ALTER TABLE keys DROP COLUMN wrapped_key;
+ALTER TABLE keys DROP COLUMN key_ciphertext;
@@ -0,0 +1,5 @@
+-- Encrypted keys keep an empty key column; key_ciphertext is sealed with a
+-- data key, which is stored in wrapped_key sealed with the master key.
+ALTER TABLE keys ADD COLUMN key_ciphertext BLOB;
+ALTER TABLE keys ADD COLUMN wrapped_key BLOB;
+ALTER TABLE keys ADD COLUMN master_key_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	password TEXT NOT NULL,
+	disabled BOOLEAN NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Retired keys are kept for the record but no longer used.
+ALTER TABLE keys ADD COLUMN retired_at DATETIME;
@@ -0,0 +1,2 @@
+ALTER TABLE keys DROP COLUMN deactivated_at;
+ALTER TABLE keys DROP COLUMN activated_at;
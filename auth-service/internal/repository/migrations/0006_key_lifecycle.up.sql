@@ -0,0 +1,8 @@
+-- Keys are published before they become active and verify tokens until
+-- some time after they were deactivated.
+ALTER TABLE keys ADD COLUMN activated_at DATETIME;
+ALTER TABLE keys ADD COLUMN deactivated_at DATETIME;
+UPDATE keys SET activated_at = created_at;
+-- Only the active key verified tokens so far, so earlier keys can retire.
+UPDATE keys SET deactivated_at = CURRENT_TIMESTAMP, retired_at = COALESCE(retired_at, CURRENT_TIMESTAMP)
+WHERE is_active = 0;
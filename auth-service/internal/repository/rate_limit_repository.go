@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitRepository counts events per key in fixed windows.
+type RateLimitRepository interface {
+	// Hit records an event and returns how many the key has seen in the
+	// current window, including this one. The window starts with the first
+	// event and lasts for the given duration.
+	Hit(ctx context.Context, key string, window time.Duration) (int64, error)
+}
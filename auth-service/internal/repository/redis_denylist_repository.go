@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisDenylistRepository struct {
+	client *redis.Client
+}
+
+func NewRedisDenylistRepository(client *redis.Client) DenylistRepository {
+	return &RedisDenylistRepository{client: client}
+}
+
+func denylistKey(id string) string {
+	return redisKeyPrefix + "denylist:" + id
+}
+
+func (r *RedisDenylistRepository) Deny(ctx context.Context, id string, until time.Time) error {
+	if !until.After(time.Now()) {
+		return nil
+	}
+	return r.client.SetArgs(ctx, denylistKey(id), 1, redis.SetArgs{ExpireAt: until}).Err()
+}
+
+func (r *RedisDenylistRepository) IsDenied(ctx context.Context, id string) (bool, error) {
+	n, err := r.client.Exists(ctx, denylistKey(id)).Result()
+	return n > 0, err
+}
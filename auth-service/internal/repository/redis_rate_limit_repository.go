@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// hitScript increments the counter and starts its window on the first hit,
+// in one step so a counter can never be left without an expiry.
+var hitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+type RedisRateLimitRepository struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimitRepository(client *redis.Client) RateLimitRepository {
+	return &RedisRateLimitRepository{client: client}
+}
+
+func (r *RedisRateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return hitScript.Run(ctx, r.client, []string{redisKeyPrefix + "rate:" + key}, window.Milliseconds()).Int64()
+}
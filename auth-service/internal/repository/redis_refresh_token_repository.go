@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// createRefreshTokenScript stores a token and adds it to the user's set,
+// which lives as long as the longest-lived token in it. Token lifetimes may
+// be changed at runtime, so a newer token can expire earlier.
+var createRefreshTokenScript = redis.NewScript(`
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+redis.call("SADD", KEYS[2], ARGV[2])
+if redis.call("PTTL", KEYS[2]) < tonumber(ARGV[3]) then
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+end
+return 1
+`)
+
+// RedisRefreshTokenRepository stores each token under its own key that
+// expires at the token's ExpiresAt, so no cleanup is needed. A set per user
+// lists the user's tokens; members whose key has expired are dropped when
+// the set is read.
+type RedisRefreshTokenRepository struct {
+	client *redis.Client
+}
+
+func NewRedisRefreshTokenRepository(client *redis.Client) RefreshTokenRepository {
+	return &RedisRefreshTokenRepository{client: client}
+}
+
+func refreshTokenKey(token string) string {
+	return redisKeyPrefix + "refresh_token:" + token
+}
+
+func userTokensKey(userID int) string {
+	return redisKeyPrefix + "user_refresh_tokens:" + strconv.Itoa(userID)
+}
+
+func (r *RedisRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	// An expired token would be gone at once; Redis rejects such expiry times.
+	if !token.ExpiresAt.After(time.Now()) {
+		return nil
+	}
+
+	value, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(token.ExpiresAt).Milliseconds()
+	return createRefreshTokenScript.Run(ctx, r.client,
+		[]string{refreshTokenKey(token.Token), userTokensKey(token.UserID)}, value, token.Token, max(ttl, 1)).Err()
+}
+
+func (r *RedisRefreshTokenRepository) Get(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	value, err := r.client.Get(ctx, refreshTokenKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var refreshToken domain.RefreshToken
+	if err := json.Unmarshal(value, &refreshToken); err != nil {
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+// Delete takes the token with GETDEL, so only one caller gets it. Leaving it
+// in the user's set for a moment is harmless, as ListByUser skips it.
+func (r *RedisRefreshTokenRepository) Delete(ctx context.Context, token string) error {
+	value, err := r.client.GetDel(ctx, refreshTokenKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var refreshToken domain.RefreshToken
+	if err := json.Unmarshal(value, &refreshToken); err != nil {
+		return err
+	}
+	return r.client.SRem(ctx, userTokensKey(refreshToken.UserID), token).Err()
+}
+
+func (r *RedisRefreshTokenRepository) ListByUser(ctx context.Context, userID int) ([]domain.RefreshToken, error) {
+	members, err := r.client.SMembers(ctx, userTokensKey(userID)).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(members))
+	for i, token := range members {
+		keys[i] = refreshTokenKey(token)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []domain.RefreshToken
+	var expired []any
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			expired = append(expired, members[i])
+			continue
+		}
+		var refreshToken domain.RefreshToken
+		if err := json.Unmarshal([]byte(s), &refreshToken); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, refreshToken)
+	}
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, userTokensKey(userID), expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.Before(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+func (r *RedisRefreshTokenRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	members, err := r.client.SMembers(ctx, userTokensKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, len(members))
+	for i, token := range members {
+		keys[i] = refreshTokenKey(token)
+	}
+	var deleted *redis.IntCmd
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(keys) > 0 {
+			deleted = pipe.Del(ctx, keys...)
+		}
+		pipe.Del(ctx, userTokensKey(userID))
+		return nil
+	})
+	if err != nil || deleted == nil {
+		return 0, err
+	}
+	return deleted.Val(), nil
+}
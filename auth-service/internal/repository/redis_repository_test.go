@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis starts an in-process Redis-compatible server. Its clock only
+// moves on FastForward, so expiry can be tested without waiting.
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client, err := OpenRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func newRefreshToken(token string, userID int, ttl time.Duration) *domain.RefreshToken {
+	now := time.Now()
+	return &domain.RefreshToken{ID: token, UserID: userID, Token: token, ExpiresAt: now.Add(ttl), CreatedAt: now}
+}
+
+func TestRedisRefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	r := NewRedisRefreshTokenRepository(client)
+
+	token := newRefreshToken("a", 1, time.Hour)
+	if err := r.Create(ctx, token); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := r.Get(ctx, "a")
+	if err != nil || got.ID != "a" || got.UserID != 1 || !got.ExpiresAt.Equal(token.ExpiresAt) {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if ttl := server.TTL(refreshTokenKey("a")); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("token key TTL = %v, want about an hour", ttl)
+	}
+
+	if err := r.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, "a"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrRefreshTokenNotFound", err)
+	}
+	if server.Exists(userTokensKey(1)) {
+		t.Error("user's token set still exists after deleting the only token")
+	}
+	if err := r.Delete(ctx, "a"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("second Delete = %v, want ErrRefreshTokenNotFound", err)
+	}
+
+	// Tokens that have expired already are not stored at all
+	if err := r.Create(ctx, newRefreshToken("old", 1, -time.Second)); err != nil {
+		t.Fatalf("Create of an expired token: %v", err)
+	}
+	if server.Exists(refreshTokenKey("old")) {
+		t.Error("expired token was stored")
+	}
+}
+
+func TestRedisRefreshTokenRepositoryExpiry(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	r := NewRedisRefreshTokenRepository(client)
+
+	for _, token := range []*domain.RefreshToken{
+		newRefreshToken("short", 1, time.Minute),
+		newRefreshToken("long", 1, time.Hour),
+	} {
+		if err := r.Create(ctx, token); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, err := r.Get(ctx, "short"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Get of an expired token = %v, want ErrRefreshTokenNotFound", err)
+	}
+
+	// Listing drops the expired token from the user's set
+	members, _ := server.SMembers(userTokensKey(1))
+	if len(members) != 2 {
+		t.Fatalf("user's set before listing = %v, want both tokens", members)
+	}
+	tokens, err := r.ListByUser(ctx, 1)
+	if err != nil || len(tokens) != 1 || tokens[0].ID != "long" {
+		t.Errorf("ListByUser = %+v, %v, want the long token only", tokens, err)
+	}
+	if members, _ := server.SMembers(userTokensKey(1)); !slices.Equal(members, []string{"long"}) {
+		t.Errorf("user's set after listing = %v, want [long]", members)
+	}
+
+	// The set expires with the user's last token
+	server.FastForward(time.Hour)
+	if server.Exists(userTokensKey(1)) {
+		t.Error("user's set outlived all tokens")
+	}
+	if tokens, err := r.ListByUser(ctx, 1); err != nil || len(tokens) != 0 {
+		t.Errorf("ListByUser after all expired = %+v, %v", tokens, err)
+	}
+}
+
+func TestRedisRefreshTokenRepositoryDeleteByUser(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	r := NewRedisRefreshTokenRepository(client)
+
+	for _, token := range []*domain.RefreshToken{
+		newRefreshToken("a1", 1, time.Hour),
+		newRefreshToken("a2", 1, time.Hour),
+		// The newest token expiring first must not take the others' set along
+		newRefreshToken("a3", 1, time.Minute),
+		newRefreshToken("b1", 2, time.Hour),
+	} {
+		if err := r.Create(ctx, token); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	server.FastForward(2 * time.Minute)
+
+	// Only tokens that were still there count
+	n, err := r.DeleteByUser(ctx, 1)
+	if err != nil || n != 2 {
+		t.Errorf("DeleteByUser = %d, %v, want 2", n, err)
+	}
+	for _, token := range []string{"a1", "a2"} {
+		if _, err := r.Get(ctx, token); !errors.Is(err, ErrRefreshTokenNotFound) {
+			t.Errorf("Get(%s) after DeleteByUser = %v", token, err)
+		}
+	}
+	if server.Exists(userTokensKey(1)) {
+		t.Error("user's set still exists")
+	}
+	if tokens, err := r.ListByUser(ctx, 2); err != nil || len(tokens) != 1 {
+		t.Errorf("other user's tokens = %+v, %v", tokens, err)
+	}
+
+	if n, err := r.DeleteByUser(ctx, 3); err != nil || n != 0 {
+		t.Errorf("DeleteByUser of a user without tokens = %d, %v", n, err)
+	}
+}
+
+func TestRedisRefreshTokenRepositoryDeleteOnce(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	r := NewRedisRefreshTokenRepository(client)
+	if err := r.Create(ctx, newRefreshToken("a", 1, time.Hour)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var mu sync.Mutex
+	deleted := 0
+	runConcurrently(func(int) {
+		err := r.Delete(ctx, "a")
+		if err != nil && !errors.Is(err, ErrRefreshTokenNotFound) {
+			t.Error(err)
+		}
+		if err == nil {
+			mu.Lock()
+			deleted++
+			mu.Unlock()
+		}
+	})
+	if deleted != 1 {
+		t.Errorf("%d of %d concurrent Deletes succeeded, want 1", deleted, workers)
+	}
+}
+
+func TestRedisRateLimitRepository(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	r := NewRedisRateLimitRepository(client)
+
+	for want := int64(1); want <= 3; want++ {
+		if n, err := r.Hit(ctx, "login:user1", time.Minute); err != nil || n != want {
+			t.Fatalf("Hit = %d, %v, want %d", n, err, want)
+		}
+	}
+	if n, _ := r.Hit(ctx, "login:user2", time.Minute); n != 1 {
+		t.Errorf("Hit of another key = %d, want 1", n)
+	}
+
+	// Later hits do not extend the window
+	server.FastForward(30 * time.Second)
+	r.Hit(ctx, "login:user1", time.Minute)
+	if ttl := server.TTL(redisKeyPrefix + "rate:login:user1"); ttl != 30*time.Second {
+		t.Errorf("window TTL = %v, want 30s", ttl)
+	}
+
+	server.FastForward(30 * time.Second)
+	if n, err := r.Hit(ctx, "login:user1", time.Minute); err != nil || n != 1 {
+		t.Errorf("Hit in the next window = %d, %v, want 1", n, err)
+	}
+}
+
+func TestRedisDenylistRepository(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+	r := NewRedisDenylistRepository(client)
+
+	if err := r.Deny(ctx, "a", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Deny: %v", err)
+	}
+	if denied, err := r.IsDenied(ctx, "a"); err != nil || !denied {
+		t.Errorf("IsDenied = %v, %v, want true", denied, err)
+	}
+	if denied, err := r.IsDenied(ctx, "b"); err != nil || denied {
+		t.Errorf("IsDenied of another token = %v, %v, want false", denied, err)
+	}
+
+	// Entries go away once the token would have expired anyway
+	server.FastForward(time.Minute + time.Second)
+	if denied, err := r.IsDenied(ctx, "a"); err != nil || denied {
+		t.Errorf("IsDenied after expiry = %v, %v, want false", denied, err)
+	}
+
+	if err := r.Deny(ctx, "c", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Deny of an expired token: %v", err)
+	}
+	if server.Exists(denylistKey("c")) {
+		t.Error("expired token was added to the denylist")
+	}
+}
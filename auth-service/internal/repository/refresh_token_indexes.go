@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// refreshTokenIndex is an index the refresh_tokens collection must have.
+// Indexes are matched by their keys rather than by name, so an equivalent
+// index created by hand is reused instead of duplicated.
+type refreshTokenIndex struct {
+	name   string
+	keys   bson.D
+	unique bool
+	// ttl makes documents expire once the indexed date has passed.
+	ttl bool
+}
+
+var refreshTokenIndexes = []refreshTokenIndex{
+	{name: "token_unique", keys: bson.D{{Key: "token", Value: 1}}, unique: true},
+	{name: "user_id", keys: bson.D{{Key: "user_id", Value: 1}}},
+	{name: "expires_at_ttl", keys: bson.D{{Key: "expires_at", Value: 1}}, ttl: true},
+}
+
+// existingIndex is an index as listed by the server.
+type existingIndex struct {
+	Name               string `bson:"name"`
+	Key                bson.D `bson:"key"`
+	Unique             bool   `bson:"unique"`
+	ExpireAfterSeconds *int64 `bson:"expireAfterSeconds"`
+}
+
+// EnsureIndexes reconciles the collection's indexes with the ones the
+// repository needs. It is safe to run on every boot and from several
+// instances at once: missing indexes are created, a TTL index with the wrong
+// expiry is fixed in place, and any other drift is only logged, since
+// rebuilding a unique index may fail on existing data or lock the collection.
+func (r *MongoDBRefreshTokenRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.client.Database(r.dbName).Collection(r.collection)
+
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list refresh token indexes: %w", err)
+	}
+	var existing []existingIndex
+	if err := cursor.All(ctx, &existing); err != nil {
+		return fmt.Errorf("failed to list refresh token indexes: %w", err)
+	}
+
+	byKeys := make(map[string]existingIndex, len(existing))
+	for _, index := range existing {
+		byKeys[indexKeys(index.Key)] = index
+	}
+
+	var missing []mongo.IndexModel
+	wanted := make(map[string]bool, len(refreshTokenIndexes))
+	for _, want := range refreshTokenIndexes {
+		keys := indexKeys(want.keys)
+		wanted[keys] = true
+
+		have, ok := byKeys[keys]
+		if !ok {
+			opts := options.Index().SetName(want.name)
+			if want.unique {
+				opts.SetUnique(true)
+			}
+			if want.ttl {
+				opts.SetExpireAfterSeconds(0)
+			}
+			missing = append(missing, mongo.IndexModel{Keys: want.keys, Options: opts})
+			continue
+		}
+
+		if have.Unique != want.unique {
+			log.Printf("Refresh token index drift: %s has unique=%t, want %t; fix it manually", have.Name, have.Unique, want.unique)
+		}
+		switch {
+		case want.ttl && (have.ExpireAfterSeconds == nil || *have.ExpireAfterSeconds != 0):
+			log.Printf("Refresh token index drift: %s does not expire documents at expires_at, fixing", have.Name)
+			if err := r.setIndexTTL(ctx, want.keys); err != nil {
+				log.Printf("Failed to fix refresh token index %s: %v", have.Name, err)
+			}
+		case !want.ttl && have.ExpireAfterSeconds != nil:
+			log.Printf("Refresh token index drift: %s expires documents after %ds; fix it manually", have.Name, *have.ExpireAfterSeconds)
+		}
+	}
+
+	for keys, index := range byKeys {
+		if !wanted[keys] && index.Name != "_id_" {
+			log.Printf("Refresh token index drift: unexpected index %s on %s", index.Name, keys)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	names, err := collection.Indexes().CreateMany(ctx, missing)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token indexes: %w", err)
+	}
+	log.Printf("Created refresh token indexes: %s", strings.Join(names, ", "))
+	return nil
+}
+
+// setIndexTTL turns the index on keys into one that expires documents as
+// soon as the indexed date has passed.
+func (r *MongoDBRefreshTokenRepository) setIndexTTL(ctx context.Context, keys bson.D) error {
+	return r.client.Database(r.dbName).RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: r.collection},
+		{Key: "index", Value: bson.D{
+			{Key: "keyPattern", Value: keys},
+			{Key: "expireAfterSeconds", Value: 0},
+		}},
+	}).Err()
+}
+
+// indexKeys renders an index key document like "token_1", the way MongoDB
+// names indexes by default. Numbers compare equal whatever their BSON type.
+func indexKeys(keys bson.D) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s_%v", key.Key, key.Value)
+	}
+	return strings.Join(parts, "_")
+}
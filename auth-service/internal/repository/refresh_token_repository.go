@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
@@ -11,10 +13,27 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrRefreshTokenNotFound is returned for tokens that do not exist, expired
+// or were deleted.
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *domain.RefreshToken) error
 	Get(ctx context.Context, token string) (*domain.RefreshToken, error)
+	// Delete deletes the token, or returns ErrRefreshTokenNotFound if it is
+	// gone already. Of concurrent calls for one token only one succeeds.
 	Delete(ctx context.Context, token string) error
+	// ListByUser returns the user's unexpired tokens, one per session,
+	// oldest first.
+	ListByUser(ctx context.Context, userID int) ([]domain.RefreshToken, error)
+	// DeleteByUser deletes all of the user's tokens and returns how many.
+	DeleteByUser(ctx context.Context, userID int) (int64, error)
+}
+
+// RefreshTokenCleaner is implemented by stores that keep expired tokens until
+// they are deleted explicitly.
+type RefreshTokenCleaner interface {
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 type MongoDBRefreshTokenRepository struct {
@@ -33,17 +52,27 @@ func NewMongoDBRefreshTokenRepository(mongoURI, dbName string) (RefreshTokenRepo
 	// Check the connection
 	err = client.Ping(context.Background(), nil)
 	if err != nil {
-		log.Fatal(err)
-		return nil, err
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
 	log.Println("Connected to MongoDB!")
 
-	return &MongoDBRefreshTokenRepository{
+	repo := &MongoDBRefreshTokenRepository{
 		client:     client,
 		dbName:     dbName,
 		collection: "refresh_tokens",
-	}, nil
+	}
+
+	// Without indexes the service still works, just slower and without
+	// expiring tokens, so a failure here does not stop the start.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		log.Printf("Failed to reconcile refresh token indexes: %v", err)
+	}
+
+	return repo, nil
 }
 
 func (r *MongoDBRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
@@ -59,6 +88,9 @@ func (r *MongoDBRefreshTokenRepository) Get(ctx context.Context, token string) (
 	var refreshToken domain.RefreshToken
 	filter := bson.M{"token": token}
 	err := collection.FindOne(ctx, filter).Decode(&refreshToken)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRefreshTokenNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -70,8 +102,40 @@ func (r *MongoDBRefreshTokenRepository) Delete(ctx context.Context, token string
 	collection := r.client.Database(r.dbName).Collection(r.collection)
 
 	filter := bson.M{"token": token}
-	_, err := collection.DeleteOne(ctx, filter)
-	return err
+	result, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrRefreshTokenNotFound
+	}
+	return nil
+}
+
+func (r *MongoDBRefreshTokenRepository) ListByUser(ctx context.Context, userID int) ([]domain.RefreshToken, error) {
+	collection := r.client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{"user_id": userID, "expires_at": bson.M{"$gt": time.Now()}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []domain.RefreshToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *MongoDBRefreshTokenRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	collection := r.client.Database(r.dbName).Collection(r.collection)
+
+	res, err := collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
 }
 
 func (r *MongoDBRefreshTokenRepository) CloseMongoDBConnection() {
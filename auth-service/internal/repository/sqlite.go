@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"log"
+
+	"forum-app/pkg/migrate"
+	_ "github.com/glebarez/sqlite" // SQLite driver
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// OpenSQLite opens the service's database file and migrates it to the latest
+// schema. All SQLite repositories share the returned handle; WAL and the busy
+// timeout let requests write without failing on each other's locks.
+func OpenSQLite(dbFilePath string) (*sql.DB, error) {
+	db, err := OpenSQLiteWithoutMigrations(dbFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return db, nil
+}
+
+// OpenSQLiteWithoutMigrations opens the database file as it is, for tools
+// that manage its schema themselves.
+func OpenSQLiteWithoutMigrations(dbFilePath string) (*sql.DB, error) {
+	return sql.Open("sqlite", dbFilePath+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+}
+
+// NewMigrator returns a migrator for the service's embedded migrations.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, sub)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+)
+
+type SQLiteRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRefreshTokenRepository expects the database to be migrated, as
+// OpenSQLite does.
+func NewSQLiteRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &SQLiteRefreshTokenRepository{db: db}
+}
+
+func (r *SQLiteRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO refresh_tokens (id, user_id, token, expires_at, created_at) VALUES (?, ?, ?, ?, ?)",
+		token.ID, token.UserID, token.Token, token.ExpiresAt.UTC(), token.CreatedAt.UTC())
+	return err
+}
+
+// Get only returns tokens that have not expired yet, so a token outlives
+// neither its expiry nor a missed cleanup run.
+func (r *SQLiteRefreshTokenRepository) Get(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	var refreshToken domain.RefreshToken
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, user_id, token, expires_at, created_at FROM refresh_tokens WHERE token = ? AND expires_at > ?",
+		token, time.Now().UTC()).Scan(
+		&refreshToken.ID, &refreshToken.UserID, &refreshToken.Token, &refreshToken.ExpiresAt, &refreshToken.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &refreshToken, nil
+}
+
+func (r *SQLiteRefreshTokenRepository) Delete(ctx context.Context, token string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE token = ?", token)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRefreshTokenNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteRefreshTokenRepository) ListByUser(ctx context.Context, userID int) ([]domain.RefreshToken, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, user_id, token, expires_at, created_at FROM refresh_tokens WHERE user_id = ? AND expires_at > ? ORDER BY created_at",
+		userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []domain.RefreshToken
+	for rows.Next() {
+		var t domain.RefreshToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Token, &t.ExpiresAt, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *SQLiteRefreshTokenRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *SQLiteRefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE expires_at <= ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
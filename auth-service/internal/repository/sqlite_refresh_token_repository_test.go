@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSQLiteRefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	r := NewSQLiteRefreshTokenRepository(openTestSQLite(t))
+
+	token := newRefreshToken("a", 1, time.Hour)
+	if err := r.Create(ctx, token); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := r.Get(ctx, "a")
+	if err != nil || got.ID != "a" || got.UserID != 1 || !got.ExpiresAt.Equal(token.ExpiresAt) {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	if err := r.Create(ctx, token); err == nil {
+		t.Error("Create of an existing token succeeded")
+	}
+
+	if err := r.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, "a"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrRefreshTokenNotFound", err)
+	}
+	if err := r.Delete(ctx, "a"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("second Delete = %v, want ErrRefreshTokenNotFound", err)
+	}
+	if _, err := r.Get(ctx, "unknown"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Get of an unknown token = %v, want ErrRefreshTokenNotFound", err)
+	}
+}
+
+func TestSQLiteRefreshTokenRepositoryExpiry(t *testing.T) {
+	ctx := context.Background()
+	r := NewSQLiteRefreshTokenRepository(openTestSQLite(t))
+
+	for _, token := range []string{"expired", "valid", "other"} {
+		ttl := time.Hour
+		if token == "expired" {
+			ttl = -time.Second
+		}
+		userID := 1
+		if token == "other" {
+			userID = 2
+		}
+		if err := r.Create(ctx, newRefreshToken(token, userID, ttl)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// Expired tokens are gone before the cleanup runs
+	if _, err := r.Get(ctx, "expired"); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Get of an expired token = %v, want ErrRefreshTokenNotFound", err)
+	}
+	tokens, err := r.ListByUser(ctx, 1)
+	if err != nil || len(tokens) != 1 || tokens[0].ID != "valid" {
+		t.Errorf("ListByUser = %+v, %v; want only the valid token", tokens, err)
+	}
+
+	cleaner := r.(RefreshTokenCleaner)
+	if n, err := cleaner.DeleteExpired(ctx, time.Now()); err != nil || n != 1 {
+		t.Errorf("DeleteExpired = %d, %v; want 1", n, err)
+	}
+	if n, err := cleaner.DeleteExpired(ctx, time.Now()); err != nil || n != 0 {
+		t.Errorf("second DeleteExpired = %d, %v; want 0", n, err)
+	}
+	if _, err := r.Get(ctx, "valid"); err != nil {
+		t.Errorf("Get of a valid token after cleanup: %v", err)
+	}
+
+	if n, err := r.DeleteByUser(ctx, 1); err != nil || n != 1 {
+		t.Errorf("DeleteByUser = %d, %v; want 1", n, err)
+	}
+	if _, err := r.Get(ctx, "other"); err != nil {
+		t.Errorf("another user's token after DeleteByUser: %v", err)
+	}
+}
+
+func TestSQLiteRefreshTokenRepositoryDeleteOnce(t *testing.T) {
+	ctx := context.Background()
+	r := NewSQLiteRefreshTokenRepository(openTestSQLite(t))
+	if err := r.Create(ctx, newRefreshToken("a", 1, time.Hour)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var mu sync.Mutex
+	deleted := 0
+	runConcurrently(func(int) {
+		err := r.Delete(ctx, "a")
+		if err != nil && !errors.Is(err, ErrRefreshTokenNotFound) {
+			t.Error(err)
+		}
+		if err == nil {
+			mu.Lock()
+			deleted++
+			mu.Unlock()
+		}
+	})
+	if deleted != 1 {
+		t.Errorf("%d of %d concurrent Deletes succeeded, want 1", deleted, workers)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TrustLevelRepository provides users' forum trust levels, which are earned
+// in the core-service and carried in access token claims.
+type TrustLevelRepository interface {
+	GetTrustLevel(userID int) (int, error)
+}
+
+// CoreServiceTrustLevelRepository reads trust levels from the core-service's
+// reputation endpoint.
+type CoreServiceTrustLevelRepository struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewCoreServiceTrustLevelRepository(baseURL string) TrustLevelRepository {
+	return &CoreServiceTrustLevelRepository{
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+func (r *CoreServiceTrustLevelRepository) GetTrustLevel(userID int) (int, error) {
+	resp, err := r.client.Get(r.baseURL + "/users/" + strconv.Itoa(userID) + "/reputation")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("core-service responded with %s", resp.Status)
+	}
+
+	var result struct {
+		Reputation struct {
+			TrustLevel int `json:"trust_level"`
+		} `json:"reputation"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	return result.Reputation.TrustLevel, nil
+}
+
+// StaticTrustLevelRepository gives every user the same level, for running
+// without a core-service.
+type StaticTrustLevelRepository struct {
+	level int
+}
+
+func NewStaticTrustLevelRepository(level int) TrustLevelRepository {
+	return &StaticTrustLevelRepository{level: level}
+}
+
+func (r *StaticTrustLevelRepository) GetTrustLevel(int) (int, error) {
+	return r.level, nil
+}
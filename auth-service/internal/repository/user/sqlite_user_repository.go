@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+)
+
+// SQLiteUserRepository expects the database to be migrated, as
+// repository.OpenSQLite does.
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUserRepository(db *sql.DB) UserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+const userColumns = "id, username, password, disabled, created_at"
+
+func (r *SQLiteUserRepository) FindByUsername(username string) (*domain.User, error) {
+	return r.find("SELECT "+userColumns+" FROM users WHERE username = ?", username)
+}
+
+func (r *SQLiteUserRepository) FindByID(id int) (*domain.User, error) {
+	return r.find("SELECT "+userColumns+" FROM users WHERE id = ?", id)
+}
+
+func (r *SQLiteUserRepository) find(query string, arg any) (*domain.User, error) {
+	var user domain.User
+	err := r.db.QueryRow(query, arg).Scan(&user.ID, &user.Username, &user.Password, &user.Disabled, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *SQLiteUserRepository) Create(user *domain.User) error {
+	user.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRow("INSERT INTO users (username, password, created_at) VALUES (?, ?, ?) RETURNING id",
+		user.Username, user.Password, user.CreatedAt).Scan(&user.ID)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return ErrUserExists
+	}
+	return err
+}
+
+func (r *SQLiteUserRepository) List() ([]domain.User, error) {
+	rows, err := r.db.Query("SELECT " + userColumns + " FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Password, &user.Disabled, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *SQLiteUserRepository) SetDisabled(id int, disabled bool) error {
+	return r.update("UPDATE users SET disabled = ? WHERE id = ?", disabled, id)
+}
+
+func (r *SQLiteUserRepository) SetPassword(id int, hash string) error {
+	return r.update("UPDATE users SET password = ? WHERE id = ?", hash, id)
+}
+
+func (r *SQLiteUserRepository) update(query string, value any, id int) error {
+	res, err := r.db.Exec(query, value, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -1,45 +1,114 @@
 package repository
 
 import (
-	"fmt"
+	"errors"
 	"forum-app/auth-service/internal/domain"
 	"golang.org/x/crypto/bcrypt"
-	_ "golang.org/x/crypto/bcrypt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("username is taken")
 )
 
 type UserRepository interface {
 	FindByUsername(username string) (*domain.User, error)
 	FindByID(id int) (*domain.User, error)
+	// Create stores a new user and sets its ID. Usernames are unique
+	// regardless of case.
+	Create(user *domain.User) error
+	List() ([]domain.User, error)
+	SetDisabled(id int, disabled bool) error
+	// SetPassword replaces the user's password hash.
+	SetPassword(id int, hash string) error
 }
 
 // InMemoryUserRepository (example)
 type InMemoryUserRepository struct {
-	users map[string]domain.User
+	mu     sync.RWMutex
+	users  map[string]domain.User
+	nextID int
 }
 
 func NewInMemoryUserRepository() UserRepository {
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	now := time.Now()
 	return &InMemoryUserRepository{
 		users: map[string]domain.User{
-			"user1": {ID: 1, Username: "user1", Password: string(hashedPassword)},
-			"user2": {ID: 2, Username: "user2", Password: string(hashedPassword)},
+			"user1": {ID: 1, Username: "user1", Password: string(hashedPassword), CreatedAt: now},
+			"user2": {ID: 2, Username: "user2", Password: string(hashedPassword), CreatedAt: now},
 		},
+		nextID: 3,
 	}
 }
 
 func (r *InMemoryUserRepository) FindByUsername(username string) (*domain.User, error) {
-	user, ok := r.users[username]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[strings.ToLower(username)]
 	if !ok {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 	return &user, nil
 }
 
 func (r *InMemoryUserRepository) FindByID(id int) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	for _, user := range r.users {
 		if user.ID == id {
 			return &user, nil
 		}
 	}
-	return nil, fmt.Errorf("user not found")
+	return nil, ErrUserNotFound
+}
+
+func (r *InMemoryUserRepository) Create(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(user.Username)
+	if _, ok := r.users[key]; ok {
+		return ErrUserExists
+	}
+	user.ID = r.nextID
+	user.CreatedAt = time.Now()
+	r.nextID++
+	r.users[key] = *user
+	return nil
+}
+
+func (r *InMemoryUserRepository) List() ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *InMemoryUserRepository) SetDisabled(id int, disabled bool) error {
+	return r.update(id, func(user *domain.User) { user.Disabled = disabled })
+}
+
+func (r *InMemoryUserRepository) SetPassword(id int, hash string) error {
+	return r.update(id, func(user *domain.User) { user.Password = hash })
+}
+
+func (r *InMemoryUserRepository) update(id int, fn func(user *domain.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, user := range r.users {
+		if user.ID == id {
+			fn(&user)
+			r.users[key] = user
+			return nil
+		}
+	}
+	return ErrUserNotFound
 }
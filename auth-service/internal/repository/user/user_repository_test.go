@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"forum-app/auth-service/internal/domain"
+)
+
+func TestInMemoryUserRepositoryConcurrent(t *testing.T) {
+	r := NewInMemoryUserRepository()
+	const workers = 8
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ids := make(map[int]bool)
+	created := 0
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				user := &domain.User{Username: fmt.Sprintf("user-%d-%d", w, i), Password: "hash"}
+				if err := r.Create(user); err != nil {
+					t.Error(err)
+					return
+				}
+				// Only one of the workers gets the shared name, whatever its case
+				shared := &domain.User{Username: fmt.Sprintf("Shared-%d", i)}
+				if w%2 == 0 {
+					shared.Username = fmt.Sprintf("shared-%d", i)
+				}
+				err := r.Create(shared)
+				if err != nil && !errors.Is(err, ErrUserExists) {
+					t.Error(err)
+				}
+
+				mu.Lock()
+				if ids[user.ID] {
+					t.Errorf("ID %d given out twice", user.ID)
+				}
+				ids[user.ID] = true
+				if err == nil {
+					created++
+				}
+				mu.Unlock()
+
+				if err := r.SetDisabled(user.ID, true); err != nil {
+					t.Error(err)
+				}
+				if found, err := r.FindByUsername(user.Username); err != nil || !found.Disabled {
+					t.Errorf("FindByUsername(%s) = %+v, %v", user.Username, found, err)
+				}
+				if _, err := r.List(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 50 {
+		t.Errorf("%d shared users created, want 50", created)
+	}
+	users, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two seeded users, the workers' own and the shared ones
+	if want := 2 + workers*50 + 50; len(users) != want {
+		t.Errorf("List returned %d users, want %d", len(users), want)
+	}
+}
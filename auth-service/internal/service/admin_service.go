@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+	"forum-app/auth-service/internal/repository"
+	userRepository "forum-app/auth-service/internal/repository/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidUser     = errors.New("username must be 3-32 characters and the password at least 8")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 32
+	minPasswordLength = 8
+)
+
+// AdminService carries out operator tasks on users, signing keys and
+// sessions. Sessions are the users' refresh tokens.
+type AdminService struct {
+	userRepository   userRepository.UserRepository
+	keyRepository    repository.KeyRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+func NewAdminService(userRepo userRepository.UserRepository, keyRepo repository.KeyRepository, refreshTokenRepo repository.RefreshTokenRepository) *AdminService {
+	return &AdminService{
+		userRepository:   userRepo,
+		keyRepository:    keyRepo,
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+func (s *AdminService) CreateUser(username, password string) (*domain.User, error) {
+	username = strings.TrimSpace(username)
+	if len(username) < minUsernameLength || len(username) > maxUsernameLength || len(password) < minPasswordLength {
+		return nil, ErrInvalidUser
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{Username: username, Password: string(hash)}
+	if err := s.userRepository.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AdminService) ListUsers() ([]domain.User, error) {
+	return s.userRepository.List()
+}
+
+// DisableUser locks the user out and ends all their sessions. Access tokens
+// already issued stay valid until they expire.
+func (s *AdminService) DisableUser(ctx context.Context, username string) error {
+	user, err := s.userRepository.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepository.SetDisabled(user.ID, true); err != nil {
+		return err
+	}
+	_, err = s.refreshTokenRepo.DeleteByUser(ctx, user.ID)
+	return err
+}
+
+func (s *AdminService) EnableUser(username string) error {
+	user, err := s.userRepository.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+	return s.userRepository.SetDisabled(user.ID, false)
+}
+
+// ResetPassword sets a new password and ends the user's sessions.
+func (s *AdminService) ResetPassword(ctx context.Context, username, password string) error {
+	if len(password) < minPasswordLength {
+		return ErrInvalidUser
+	}
+	user, err := s.userRepository.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepository.SetPassword(user.ID, string(hash)); err != nil {
+		return err
+	}
+	_, err = s.refreshTokenRepo.DeleteByUser(ctx, user.ID)
+	return err
+}
+
+func (s *AdminService) ListKeys() ([]domain.SigningKey, error) {
+	return s.keyRepository.ListKeys()
+}
+
+// RotateKey activates the next key. Access tokens signed with the previous
+// key stay valid until they expire; running instances switch to the new key
+// within a minute.
+func (s *AdminService) RotateKey() error {
+	_, err := s.keyRepository.RotateKey(time.Time{})
+	return err
+}
+
+func (s *AdminService) RetireKey(id int64) error {
+	return s.keyRepository.RetireKey(id)
+}
+
+func (s *AdminService) ListSessions(ctx context.Context, username string) ([]domain.RefreshToken, error) {
+	user, err := s.userRepository.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.refreshTokenRepo.ListByUser(ctx, user.ID)
+}
+
+// RevokeSession ends one of the user's sessions, given by the refresh
+// token's ID.
+func (s *AdminService) RevokeSession(ctx context.Context, username, sessionID string) error {
+	sessions, err := s.ListSessions(ctx, username)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID != sessionID {
+			continue
+		}
+		err := s.refreshTokenRepo.Delete(ctx, session.Token)
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			// Used or revoked since it was listed.
+			break
+		}
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+}
+
+// RevokeSessions ends all of the user's sessions and returns how many.
+func (s *AdminService) RevokeSessions(ctx context.Context, username string) (int64, error) {
+	user, err := s.userRepository.FindByUsername(username)
+	if err != nil {
+		return 0, err
+	}
+	return s.refreshTokenRepo.DeleteByUser(ctx, user.ID)
+}
@@ -2,46 +2,63 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"forum-app/auth-service/internal/config"
 	"forum-app/auth-service/internal/domain"
 	"forum-app/auth-service/internal/repository"
 	userRepository "forum-app/auth-service/internal/repository/user"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrTooManyAttempts = errors.New("too many login attempts, try again later")
+
 type AuthService interface {
 	Login(ctx context.Context, username, password string) (*domain.TokenDetails, error)
+	// Logout revokes the access token until it expires and deletes the
+	// refresh token, if given.
+	Logout(ctx context.Context, accessToken, refreshToken string) error
 	RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenDetails, error)
 	VerifyAccessToken(tokenString string) (*domain.AccessDetails, error)
 	GenerateTokens(user *domain.User) (*domain.TokenDetails, error)
 	CreateRefreshToken(userID int) (string, error)
+	FindUser(username string) (*domain.User, error)
 }
 
 type AuthServiceImpl struct {
 	userRepository   userRepository.UserRepository
-	keyRepository    repository.KeyRepository
+	tokens           *TokenCodec
 	refreshTokenRepo repository.RefreshTokenRepository
-	config           *config.Config
+	trustLevelRepo   repository.TrustLevelRepository
+	rateLimitRepo    repository.RateLimitRepository
+	denylistRepo     repository.DenylistRepository
+	config           *config.Live
 }
 
-func NewAuthService(userRepo userRepository.UserRepository, keyRepo repository.KeyRepository, refreshTokenRepo repository.RefreshTokenRepository, cfg *config.Config) AuthService {
+func NewAuthService(userRepo userRepository.UserRepository, tokens *TokenCodec, refreshTokenRepo repository.RefreshTokenRepository, trustLevelRepo repository.TrustLevelRepository, rateLimitRepo repository.RateLimitRepository, denylistRepo repository.DenylistRepository, cfg *config.Live) AuthService {
 	return &AuthServiceImpl{
 		userRepository:   userRepo,
-		keyRepository:    keyRepo,
+		tokens:           tokens,
 		refreshTokenRepo: refreshTokenRepo,
+		trustLevelRepo:   trustLevelRepo,
+		rateLimitRepo:    rateLimitRepo,
+		denylistRepo:     denylistRepo,
 		config:           cfg,
 	}
 }
 
 func (s *AuthServiceImpl) Login(ctx context.Context, username, password string) (*domain.TokenDetails, error) {
+	if err := s.limitLogin(ctx, username); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepository.FindByUsername(username)
-	if err != nil {
+	if err != nil || user.Disabled {
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
@@ -58,39 +75,88 @@ func (s *AuthServiceImpl) Login(ctx context.Context, username, password string)
 	return tokens, nil
 }
 
+// limitLogin counts attempts per username, so password guessing is slowed
+// down no matter how many addresses it comes from. If the store fails, logins
+// are let through rather than locking everyone out.
+func (s *AuthServiceImpl) limitLogin(ctx context.Context, username string) error {
+	cfg := s.config.Get()
+	if cfg.LoginRateLimit <= 0 {
+		return nil
+	}
+
+	attempts, err := s.rateLimitRepo.Hit(ctx, "login:"+strings.ToLower(username), cfg.LoginRateWindow)
+	if err != nil {
+		log.Printf("Failed to count login attempts: %v", err)
+		return nil
+	}
+	if attempts > int64(cfg.LoginRateLimit) {
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+func (s *AuthServiceImpl) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	accessDetails, err := s.VerifyAccessToken(accessToken)
+	if err != nil {
+		return err
+	}
+	if err := s.denylistRepo.Deny(ctx, accessDetails.AccessUuid, accessDetails.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := s.refreshTokenRepo.Get(ctx, refreshToken)
+	if err != nil || stored.UserID != accessDetails.UserId {
+		// Unknown or foreign refresh tokens are left alone.
+		return nil
+	}
+	// The token may have been used up meanwhile, which is just as good.
+	err = s.refreshTokenRepo.Delete(ctx, refreshToken)
+	if err != nil && !errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return err
+	}
+	return nil
+}
+
 func (s *AuthServiceImpl) GenerateTokens(user *domain.User) (*domain.TokenDetails, error) {
+	cfg := s.config.Get()
 	td := &domain.TokenDetails{}
-	td.AtExpires = time.Now().Add(s.config.AccessTokenTTL)
+	td.AtExpires = time.Now().Add(cfg.AccessTokenTTL)
 	td.AccessUuid = uuid.New().String()
 
-	td.RtExpires = time.Now().Add(s.config.RefreshTokenTTL)
+	td.RtExpires = time.Now().Add(cfg.RefreshTokenTTL)
 	td.RefreshUuid = uuid.New().String()
 
-	var err error
-	//Creating Access Token
-	atClaims := jwt.MapClaims{}
-	atClaims["authorized"] = true
-	atClaims["access_uuid"] = td.AccessUuid
-	atClaims["user_id"] = user.ID
-	atClaims["exp"] = td.AtExpires.Unix()
-	accessKey, err := s.keyRepository.GetCurrentKey()
+	// A missing trust level must not lock users out; they get level 0 until
+	// their next token.
+	trustLevel, err := s.trustLevelRepo.GetTrustLevel(user.ID)
 	if err != nil {
-		return nil, err
+		log.Printf("Failed to get trust level of user %d: %v", user.ID, err)
+		trustLevel = 0
+	}
+
+	//Creating Access Token
+	accessClaims := &AccessClaims{
+		AccessUuid: td.AccessUuid,
+		UserID:     user.ID,
+		TrustLevel: trustLevel,
+		Authorized: true,
 	}
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	td.AccessToken, err = at.SignedString([]byte(accessKey))
+	accessClaims.ExpiresAt = td.AtExpires.Unix()
+	td.AccessToken, err = s.tokens.EncodeAccess(accessClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	//Creating Refresh Token
-	rtClaims := jwt.MapClaims{}
-	rtClaims["refresh_uuid"] = td.RefreshUuid
-	rtClaims["user_id"] = user.ID
-	rtClaims["exp"] = td.RtExpires.Unix()
-
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtClaims)
-	td.RefreshToken, err = rt.SignedString([]byte(s.config.JWTSigningKey))
+	refreshClaims := &RefreshClaims{
+		RefreshUuid: td.RefreshUuid,
+		UserID:      user.ID,
+	}
+	refreshClaims.ExpiresAt = td.RtExpires.Unix()
+	td.RefreshToken, err = s.tokens.EncodeRefresh(refreshClaims)
 	if err != nil {
 		return nil, err
 	}
@@ -111,155 +177,76 @@ func (s *AuthServiceImpl) GenerateTokens(user *domain.User) (*domain.TokenDetail
 	return td, nil
 }
 
+// RefreshToken trades a refresh token for new tokens. Each refresh token can
+// be used once; one no longer stored was used, revoked or has expired.
 func (s *AuthServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenDetails, error) {
-	//Verify the token
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		//Make sure that the token method conform to "SigningMethodHMAC"
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWTSigningKey), nil
-	})
+	claims, err := s.tokens.DecodeRefresh(refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	//Is token valid?
-	if _, ok := token.Claims.(jwt.Claims); !ok && !token.Valid {
-		return nil, err
-	}
-
-	//Since token is valid, get the claims
-	claims, ok := token.Claims.(jwt.MapClaims) //the token claims should conform to MapClaims
-	if ok && token.Valid {
-
-		//Get the user id from the token claims
-		refreshUuid, ok := claims["refresh_uuid"].(string) //convert the interface to string
-		if !ok {
-			return nil, err
-		}
-
-		userID, err := s.extractRefreshTokenMetadata(refreshToken)
-		if err != nil {
-			return nil, err
-		}
-
-		// Lookup refresh token in MongoDB
-		storedRefreshToken, err := s.refreshTokenRepo.Get(context.Background(), refreshToken)
-		if err != nil {
-			return nil, fmt.Errorf("invalid refresh token: %w", err)
-		}
-
-		if storedRefreshToken.ID != refreshUuid {
-			return nil, fmt.Errorf("invalid refresh token uuid")
-		}
-
-		// Delete the old refresh token
-		err = s.refreshTokenRepo.Delete(context.Background(), refreshToken)
-		if err != nil {
-			log.Printf("Failed to delete refresh token: %v", err)
-		}
-
-		//Get the user details from the User id
-		user, err := s.userRepository.FindByID(userID)
-		if err != nil {
-			return nil, err
-		}
-
-		//Generate new tokens
-		ts, err := s.GenerateTokens(user)
-		if err != nil {
-			return nil, err
-		}
-		return ts, nil
-	} else {
-		return nil, err
+	// Lookup refresh token in MongoDB
+	storedRefreshToken, err := s.refreshTokenRepo.Get(ctx, refreshToken)
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil, ErrTokenRevoked
 	}
-}
-
-func (s *AuthServiceImpl) extractRefreshTokenMetadata(tokenString string) (int, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWTSigningKey), nil
-	})
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("failed to look up refresh token: %w", err)
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			return 0, err
-		}
-		return int(userID), nil
+	if storedRefreshToken.ID != claims.RefreshUuid || storedRefreshToken.UserID != claims.UserID {
+		return nil, ErrTokenRevoked
 	}
-	return 0, err
-}
 
-func (s *AuthServiceImpl) VerifyAccessToken(tokenString string) (*domain.AccessDetails, error) {
-	accessKey, err := s.keyRepository.GetCurrentKey()
+	// Refresh tokens are single use; of concurrent refreshes only the one
+	// that deletes the token goes on.
+	err = s.refreshTokenRepo.Delete(ctx, refreshToken)
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil, ErrTokenRevoked
+	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to delete refresh token: %w", err)
+	}
+
+	//Get the user details from the User id
+	user, err := s.userRepository.FindByID(claims.UserID)
+	if errors.Is(err, userRepository.ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: user no longer exists", ErrTokenRevoked)
 	}
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		//Make sure that the token method conform to "SigningMethodHMAC"
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(accessKey), nil
-	})
 	if err != nil {
 		return nil, err
 	}
-	//Is token valid?
-	if _, ok := token.Claims.(jwt.Claims); !ok && !token.Valid {
-		return nil, err
+	if user.Disabled {
+		return nil, fmt.Errorf("%w: user is disabled", ErrTokenRevoked)
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		accessUuid, ok := claims["access_uuid"].(string)
-		if !ok {
-			return nil, err
-		}
-		userId, err := s.extractTokenMetadata(tokenString)
-		if err != nil {
-			return nil, err
-		}
-
-		return &domain.AccessDetails{
-			AccessUuid: accessUuid,
-			UserId:     userId,
-		}, nil
-	}
-	return nil, err
+
+	//Generate new tokens
+	return s.GenerateTokens(user)
 }
 
-func (s *AuthServiceImpl) extractTokenMetadata(tokenString string) (int, error) {
-	accessKey, err := s.keyRepository.GetCurrentKey()
+func (s *AuthServiceImpl) VerifyAccessToken(tokenString string) (*domain.AccessDetails, error) {
+	claims, err := s.tokens.DecodeAccess(tokenString)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(accessKey), nil
-	})
+	denied, err := s.denylistRepo.IsDenied(context.Background(), claims.AccessUuid)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			return 0, err
-		}
-		return int(userID), nil
+	if denied {
+		return nil, ErrTokenRevoked
 	}
-	return 0, err
+
+	return &domain.AccessDetails{
+		AccessUuid: claims.AccessUuid,
+		UserId:     claims.UserID,
+		TrustLevel: claims.TrustLevel,
+		ExpiresAt:  time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+func (s *AuthServiceImpl) FindUser(username string) (*domain.User, error) {
+	return s.userRepository.FindByUsername(username)
 }
 
 func (s *AuthServiceImpl) CreateRefreshToken(userID int) (string, error) {
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"forum-app/auth-service/internal/config"
+	"forum-app/auth-service/internal/domain"
+	"forum-app/auth-service/internal/repository"
+	userRepository "forum-app/auth-service/internal/repository/user"
+)
+
+// testEnv is what the service runs on in tests: the in-memory stores of
+// AUTH_STORAGE=memory, with the seeded users user1 and user2.
+type testEnv struct {
+	auth          AuthService
+	users         userRepository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	keys          *KeyManager
+	codec         *TokenCodec
+	config        *config.Live
+}
+
+// newTestEnv loads the configuration from defaults and env, which are set
+// on top of AUTH_STORAGE=memory and a signing key.
+func newTestEnv(t *testing.T, env ...string) *testEnv {
+	t.Helper()
+	t.Setenv("AUTH_STORAGE", "memory")
+	t.Setenv("JWT_SIGNING_KEY", "0123456789abcdef0123456789abcdef")
+	t.Setenv("CORE_SERVICE_URL", "")
+	for i := 0; i+1 < len(env); i += 2 {
+		t.Setenv(env[i], env[i+1])
+	}
+
+	loader, _, err := config.NewLoader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Storage != "memory" {
+		t.Fatalf("storage = %q, want memory", cfg.Storage)
+	}
+	live := config.NewLive(loader, cfg)
+
+	e := &testEnv{
+		users:         userRepository.NewInMemoryUserRepository(),
+		refreshTokens: repository.NewInMemoryRefreshTokenRepository(),
+		config:        live,
+	}
+	e.keys = NewKeyManager(repository.NewInMemoryKeyRepository(), live)
+	e.codec = NewTokenCodec(e.keys, live)
+	e.auth = NewAuthService(e.users, e.codec, e.refreshTokens, repository.NewStaticTrustLevelRepository(2),
+		repository.NewInMemoryRateLimitRepository(), repository.NewInMemoryDenylistRepository(), live)
+	return e
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEnv(t)
+
+	tokens, err := e.auth.Login(ctx, "user1", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	access, err := e.auth.VerifyAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if access.UserId != 1 || access.TrustLevel != 2 {
+		t.Errorf("access token for user %d at level %d, want user 1 at level 2", access.UserId, access.TrustLevel)
+	}
+	stored, err := e.refreshTokens.Get(ctx, tokens.RefreshToken)
+	if err != nil || stored.UserID != 1 || stored.ID != tokens.RefreshUuid {
+		t.Errorf("stored refresh token = %+v, %v", stored, err)
+	}
+
+	// Usernames are not case sensitive
+	if _, err := e.auth.Login(ctx, "USER1", "password"); err != nil {
+		t.Errorf("Login with upper case username: %v", err)
+	}
+
+	for _, tc := range []struct{ username, password string }{
+		{"user1", "wrong"},
+		{"nobody", "password"},
+		{"user1", ""},
+	} {
+		if _, err := e.auth.Login(ctx, tc.username, tc.password); err == nil {
+			t.Errorf("Login(%q, %q) succeeded", tc.username, tc.password)
+		}
+	}
+}
+
+func TestLoginDisabledUser(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEnv(t)
+	tokens, err := e.auth.Login(ctx, "user2", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if err := e.users.SetDisabled(2, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.auth.Login(ctx, "user2", "password"); err == nil {
+		t.Error("disabled user logged in")
+	}
+	if _, err := e.auth.RefreshToken(ctx, tokens.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("RefreshToken of a disabled user = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEnv(t, "LOGIN_RATE_LIMIT", "2")
+
+	for i := range 2 {
+		if _, err := e.auth.Login(ctx, "user1", "wrong"); err == nil || errors.Is(err, ErrTooManyAttempts) {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+	}
+	if _, err := e.auth.Login(ctx, "User1", "password"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("third attempt = %v, want ErrTooManyAttempts", err)
+	}
+	// Other users are not affected
+	if _, err := e.auth.Login(ctx, "user2", "password"); err != nil {
+		t.Errorf("Login of another user: %v", err)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEnv(t)
+	tokens, err := e.auth.Login(ctx, "user1", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	refreshed, err := e.auth.RefreshToken(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken || refreshed.AccessToken == tokens.AccessToken {
+		t.Error("RefreshToken returned the old tokens")
+	}
+	if _, err := e.auth.VerifyAccessToken(refreshed.AccessToken); err != nil {
+		t.Errorf("VerifyAccessToken of the new access token: %v", err)
+	}
+
+	// The old refresh token was used up
+	if _, err := e.auth.RefreshToken(ctx, tokens.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("second use of a refresh token = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := e.auth.RefreshToken(ctx, refreshed.RefreshToken); err != nil {
+		t.Errorf("RefreshToken with the new refresh token: %v", err)
+	}
+
+	if _, err := e.auth.RefreshToken(ctx, "not a token"); !errors.Is(err, ErrTokenMalformed) {
+		t.Errorf("RefreshToken of garbage = %v, want ErrTokenMalformed", err)
+	}
+}
+
+// lookupBarrier holds every Get until all expected lookups are done, so
+// concurrent refreshes all find the token before any of them deletes it.
+type lookupBarrier struct {
+	repository.RefreshTokenRepository
+	lookups sync.WaitGroup
+}
+
+func (b *lookupBarrier) Get(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	stored, err := b.RefreshTokenRepository.Get(ctx, token)
+	b.lookups.Done()
+	b.lookups.Wait()
+	return stored, err
+}
+
+func TestRefreshTokenSingleUse(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEnv(t)
+	tokens, err := e.auth.Login(ctx, "user1", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	const attempts = 8
+	barrier := &lookupBarrier{RefreshTokenRepository: e.refreshTokens}
+	barrier.lookups.Add(attempts)
+	auth := NewAuthService(e.users, e.codec, barrier, repository.NewStaticTrustLevelRepository(2),
+		repository.NewInMemoryRateLimitRepository(), repository.NewInMemoryDenylistRepository(), e.config)
+
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := auth.RefreshToken(ctx, tokens.RefreshToken)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrTokenRevoked):
+			t.Errorf("RefreshToken = %v, want ErrTokenRevoked", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d of %d concurrent refreshes succeeded, want 1", succeeded, attempts)
+	}
+	if sessions, err := e.refreshTokens.ListByUser(ctx, 1); err != nil || len(sessions) != 1 {
+		t.Errorf("user has %d sessions, %v; want 1", len(sessions), err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	e := newTestEnv(t)
+	tokens, err := e.auth.Login(ctx, "user1", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	other, err := e.auth.Login(ctx, "user2", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	// Someone else's refresh token is left alone
+	if err := e.auth.Logout(ctx, tokens.AccessToken, other.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := e.auth.VerifyAccessToken(tokens.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("VerifyAccessToken after logout = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := e.refreshTokens.Get(ctx, other.RefreshToken); err != nil {
+		t.Errorf("other user's refresh token: %v", err)
+	}
+	if err := e.auth.Logout(ctx, tokens.AccessToken, ""); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("second Logout = %v, want ErrTokenRevoked", err)
+	}
+
+	again, err := e.auth.Login(ctx, "user1", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if err := e.auth.Logout(ctx, again.AccessToken, again.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := e.auth.RefreshToken(ctx, again.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("RefreshToken after logout = %v, want ErrTokenRevoked", err)
+	}
+	// The other session is still signed in
+	if _, err := e.auth.VerifyAccessToken(other.AccessToken); err != nil {
+		t.Errorf("VerifyAccessToken of another session: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"forum-app/auth-service/internal/config"
+	"forum-app/auth-service/internal/repository"
+)
+
+// ErrUnknownKey is returned for tokens signed with a key that is retired or
+// never existed.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+const (
+	// keyRefreshInterval is how long a key set is used before it is loaded
+	// again, so keys rotated by other instances are picked up. The next key
+	// is published a whole rotation ahead of that.
+	keyRefreshInterval = time.Minute
+	// keyMissRefreshInterval limits reloads for tokens naming unknown keys.
+	keyMissRefreshInterval = 5 * time.Second
+)
+
+// KeyManager runs the signing keys' life cycle: it rotates them on schedule
+// or on request, retires replaced keys once no token signed with them can
+// still be valid and deletes retired keys after the retention period. It
+// also caches the keys for signing and verifying tokens.
+type KeyManager struct {
+	keyRepository repository.KeyRepository
+	config        *config.Live
+
+	mu       sync.RWMutex
+	activeID int64
+	keys     map[int64]string
+	loadedAt time.Time
+}
+
+func NewKeyManager(keyRepo repository.KeyRepository, cfg *config.Live) *KeyManager {
+	return &KeyManager{keyRepository: keyRepo, config: cfg}
+}
+
+// SigningKey returns the active key and its ID.
+func (m *KeyManager) SigningKey() (int64, string, error) {
+	m.mu.RLock()
+	id, key, fresh := m.activeID, m.keys[m.activeID], time.Since(m.loadedAt) < keyRefreshInterval
+	m.mu.RUnlock()
+	if fresh {
+		return id, key, nil
+	}
+
+	if err := m.load(); err != nil {
+		return 0, "", err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.activeID, m.keys[m.activeID], nil
+}
+
+// VerificationKey returns the key with the given ID, which may be the next
+// key another instance has started signing with already.
+func (m *KeyManager) VerificationKey(id int64) (string, error) {
+	m.mu.RLock()
+	key, ok := m.keys[id]
+	stale := time.Since(m.loadedAt) >= keyMissRefreshInterval
+	m.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return "", ErrUnknownKey
+	}
+
+	if err := m.load(); err != nil {
+		return "", err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if key, ok = m.keys[id]; !ok {
+		return "", ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (m *KeyManager) load() error {
+	activeID, keys, err := m.keyRepository.GetSigningKeys()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeID, m.keys, m.loadedAt = activeID, keys, time.Now()
+	return nil
+}
+
+// Rotate activates the next key now and returns its ID.
+func (m *KeyManager) Rotate() (int64, error) {
+	if _, err := m.keyRepository.RotateKey(time.Time{}); err != nil {
+		return 0, err
+	}
+	if err := m.load(); err != nil {
+		return 0, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.activeID, nil
+}
+
+// Run maintains the keys every minute until ctx is done.
+func (m *KeyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(keyRefreshInterval)
+	defer ticker.Stop()
+	for {
+		m.maintain(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *KeyManager) maintain(now time.Time) {
+	cfg := m.config.Get()
+
+	if cfg.KeyRotationInterval > 0 {
+		if _, err := m.keyRepository.RotateKey(now.Add(-cfg.KeyRotationInterval)); err != nil {
+			log.Printf("Error rotating key: %v", err)
+		}
+	}
+
+	// Other instances may sign with a replaced key until they reload theirs,
+	// and tokens are accepted for the leeway after they expire
+	n, err := m.keyRepository.RetireKeys(now.Add(-cfg.AccessTokenTTL - cfg.TokenLeeway - keyRefreshInterval))
+	if err != nil {
+		log.Printf("Error retiring keys: %v", err)
+	} else if n > 0 {
+		log.Printf("Retired %d signing keys", n)
+	}
+
+	if cfg.KeyRetention > 0 {
+		n, err := m.keyRepository.DeleteRetiredKeys(now.Add(-cfg.KeyRetention))
+		if err != nil {
+			log.Printf("Error deleting retired keys: %v", err)
+		} else if n > 0 {
+			log.Printf("Deleted %d retired signing keys", n)
+		}
+	}
+
+	if err := m.load(); err != nil {
+		log.Printf("Error loading signing keys: %v", err)
+	}
+}
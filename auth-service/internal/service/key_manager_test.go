@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"forum-app/auth-service/internal/domain"
+	"forum-app/auth-service/internal/repository"
+)
+
+// countingKeyRepository counts how often the keys are loaded.
+type countingKeyRepository struct {
+	repository.KeyRepository
+	loads atomic.Int32
+}
+
+func (r *countingKeyRepository) GetSigningKeys() (int64, map[int64]string, error) {
+	r.loads.Add(1)
+	return r.KeyRepository.GetSigningKeys()
+}
+
+// age makes the manager's cache look loaded d ago.
+func (m *KeyManager) age(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loadedAt = m.loadedAt.Add(-d)
+}
+
+func (e *testEnv) accessToken(t *testing.T) string {
+	t.Helper()
+	access, err := e.codec.EncodeAccess(&AccessClaims{AccessUuid: "a", UserID: 1,
+		RegisteredClaims: RegisteredClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return access
+}
+
+func TestKeyManagerRotation(t *testing.T) {
+	e := newTestEnv(t, "ACCESS_TOKEN_TTL", "15m", "TOKEN_LEEWAY", "30s", "KEY_ROTATION_INTERVAL", "0")
+	oldID, _, err := e.keys.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := e.accessToken(t)
+
+	start := time.Now()
+	newID, err := e.keys.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newID == oldID {
+		t.Fatal("Rotate kept the active key")
+	}
+	if id, _, _ := e.keys.SigningKey(); id != newID {
+		t.Errorf("signing with key %d after rotation, want %d", id, newID)
+	}
+
+	// The previous key is kept for as long as tokens it signed may be valid
+	// and instances that did not reload yet may still sign with it
+	lifetime := 15*time.Minute + 30*time.Second + keyRefreshInterval
+	e.keys.maintain(start.Add(lifetime - time.Second))
+	if _, err := e.codec.DecodeAccess(old); err != nil {
+		t.Errorf("token of the previous key before retirement: %v", err)
+	}
+	e.keys.maintain(time.Now().Add(lifetime + time.Second))
+	if _, err := e.codec.DecodeAccess(old); !errors.Is(err, ErrTokenBadSignature) {
+		t.Errorf("token of a retired key = %v, want ErrTokenBadSignature", err)
+	}
+	if _, err := e.codec.DecodeAccess(e.accessToken(t)); err != nil {
+		t.Errorf("token of the active key: %v", err)
+	}
+}
+
+func TestKeyManagerScheduledRotation(t *testing.T) {
+	e := newTestEnv(t, "KEY_ROTATION_INTERVAL", "24h")
+	id, _, err := e.keys.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.keys.maintain(time.Now().Add(23 * time.Hour))
+	if got, _, _ := e.keys.SigningKey(); got != id {
+		t.Errorf("key rotated before it was due")
+	}
+	e.keys.maintain(time.Now().Add(24*time.Hour + time.Second))
+	if got, _, _ := e.keys.SigningKey(); got == id {
+		t.Errorf("key not rotated when due")
+	}
+}
+
+func TestKeyManagerDeletesRetiredKeys(t *testing.T) {
+	e := newTestEnv(t, "KEY_ROTATION_INTERVAL", "0", "KEY_RETENTION", "24h")
+	keys := repository.NewInMemoryKeyRepository()
+	m := NewKeyManager(keys, e.config)
+	previous, _, err := m.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.RetireKey(previous); err != nil {
+		t.Fatal(err)
+	}
+	state := func() string {
+		t.Helper()
+		list, err := keys.ListKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range list {
+			if k.ID == previous {
+				return k.State()
+			}
+		}
+		return "deleted"
+	}
+
+	retired := time.Now()
+	m.maintain(retired.Add(24*time.Hour - time.Second))
+	if got := state(); got != domain.KeyRetired {
+		t.Errorf("key within retention is %s, want retired", got)
+	}
+	m.maintain(time.Now().Add(24*time.Hour + time.Second))
+	if got := state(); got != "deleted" {
+		t.Errorf("key after retention is %s, want deleted", got)
+	}
+
+	// Without a retention period retired keys are kept
+	e = newTestEnv(t, "KEY_ROTATION_INTERVAL", "0", "KEY_RETENTION", "0")
+	keys = repository.NewInMemoryKeyRepository()
+	m = NewKeyManager(keys, e.config)
+	previous, _, _ = m.SigningKey()
+	m.Rotate()
+	if err := keys.RetireKey(previous); err != nil {
+		t.Fatal(err)
+	}
+	m.maintain(time.Now().Add(10000 * time.Hour))
+	if got := state(); got != domain.KeyRetired {
+		t.Errorf("key without retention is %s, want retired", got)
+	}
+}
+
+// TestKeyManagerRefresh checks that keys rotated by another instance are
+// picked up, without letting tokens with unknown key IDs reload the keys
+// more than once per keyMissRefreshInterval.
+func TestKeyManagerRefresh(t *testing.T) {
+	e := newTestEnv(t)
+	keys := &countingKeyRepository{KeyRepository: repository.NewInMemoryKeyRepository()}
+	m := NewKeyManager(keys, e.config)
+	first, _, err := m.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another instance rotates twice, so the new next key is not cached
+	for range 2 {
+		if _, err := keys.RotateKey(time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	activeID, all, _ := keys.GetSigningKeys()
+	var unknown int64
+	for id := range all {
+		unknown = max(unknown, id)
+	}
+	keys.loads.Store(0)
+
+	for range 10 {
+		if _, err := m.VerificationKey(unknown); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("VerificationKey of an uncached key = %v, want ErrUnknownKey", err)
+		}
+	}
+	if n := keys.loads.Load(); n != 0 {
+		t.Errorf("%d reloads within %v of loading", n, keyMissRefreshInterval)
+	}
+
+	m.age(keyMissRefreshInterval)
+	if _, err := m.VerificationKey(unknown); err != nil {
+		t.Errorf("VerificationKey after %v: %v", keyMissRefreshInterval, err)
+	}
+	for range 10 {
+		m.VerificationKey(unknown + 100)
+	}
+	if n := keys.loads.Load(); n != 1 {
+		t.Errorf("%d reloads for missing keys, want 1", n)
+	}
+
+	// The reload above refreshed the active key too; without it the signing
+	// key is reloaded once keyRefreshInterval has passed
+	if id, _, _ := m.SigningKey(); id != activeID {
+		t.Errorf("signing with key %d, want %d", id, activeID)
+	}
+	keys.RotateKey(time.Time{})
+	if id, _, _ := m.SigningKey(); id != activeID {
+		t.Errorf("signing key %d changed before the cache expired", id)
+	}
+	m.age(keyRefreshInterval)
+	if id, _, _ := m.SigningKey(); id == activeID || id == first {
+		t.Errorf("signing with key %d after the cache expired, want the newly rotated one", id)
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"forum-app/auth-service/internal/config"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// The kinds of errors a token can be rejected with. Errors may add detail,
+// so test for them with errors.Is.
+var (
+	ErrTokenMalformed    = errors.New("token is malformed")
+	ErrTokenBadSignature = errors.New("token signature is invalid")
+	ErrTokenExpired      = errors.New("token has expired")
+	ErrTokenNotYetValid  = errors.New("token is not valid yet")
+	ErrTokenRevoked      = errors.New("token has been revoked")
+)
+
+// IsTokenError tells errors about the token itself from failures of the
+// service.
+func IsTokenError(err error) bool {
+	for _, kind := range []error{ErrTokenMalformed, ErrTokenBadSignature, ErrTokenExpired, ErrTokenNotYetValid, ErrTokenRevoked} {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Token types, kept in the typ claim so one kind of token cannot be passed
+// off as the other.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// RegisteredClaims are the claims all tokens carry. Times are Unix seconds.
+type RegisteredClaims struct {
+	Type      string `json:"typ"`
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (c *RegisteredClaims) registered() *RegisteredClaims {
+	return c
+}
+
+// Valid lets the jwt package accept all claims; TokenCodec checks them
+// itself, with leeway.
+func (c *RegisteredClaims) Valid() error {
+	return nil
+}
+
+type AccessClaims struct {
+	RegisteredClaims
+	AccessUuid string `json:"access_uuid"`
+	UserID     int    `json:"user_id"`
+	TrustLevel int    `json:"trust_level"`
+	Authorized bool   `json:"authorized"`
+}
+
+type RefreshClaims struct {
+	RegisteredClaims
+	RefreshUuid string `json:"refresh_uuid"`
+	UserID      int    `json:"user_id"`
+}
+
+type claims interface {
+	jwt.Claims
+	registered() *RegisteredClaims
+}
+
+// TokenCodec signs and parses all tokens the service issues. Access tokens
+// are signed with the rotating keys and name theirs in the kid header;
+// refresh tokens are signed with the configured JWT signing key.
+type TokenCodec struct {
+	keys   *KeyManager
+	config *config.Live
+}
+
+func NewTokenCodec(keys *KeyManager, cfg *config.Live) *TokenCodec {
+	return &TokenCodec{keys: keys, config: cfg}
+}
+
+// EncodeAccess signs an access token expiring at claims.ExpiresAt.
+func (c *TokenCodec) EncodeAccess(claims *AccessClaims) (string, error) {
+	id, key, err := c.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	return c.encode(claims, TokenTypeAccess, strconv.FormatInt(id, 10), key)
+}
+
+// EncodeRefresh signs a refresh token expiring at claims.ExpiresAt.
+func (c *TokenCodec) EncodeRefresh(claims *RefreshClaims) (string, error) {
+	return c.encode(claims, TokenTypeRefresh, "", c.config.Get().JWTSigningKey)
+}
+
+func (c *TokenCodec) encode(claims claims, typ, kid, key string) (string, error) {
+	cfg := c.config.Get()
+	now := time.Now().Unix()
+	r := claims.registered()
+	r.Type, r.Issuer, r.Audience = typ, cfg.TokenIssuer, cfg.TokenAudience
+	r.IssuedAt, r.NotBefore = now, now
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString([]byte(key))
+}
+
+// DecodeAccess verifies an access token and returns its claims. It does not
+// check whether the token was revoked.
+func (c *TokenCodec) DecodeAccess(tokenString string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	err := c.decode(tokenString, claims, TokenTypeAccess, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		id, err := strconv.ParseInt(kid, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: no key ID", ErrTokenMalformed)
+		}
+		key, err := c.keys.VerificationKey(id)
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, fmt.Errorf("%w: %v", ErrTokenBadSignature, err)
+		}
+		return []byte(key), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.AccessUuid == "" || claims.UserID == 0 {
+		return nil, fmt.Errorf("%w: missing access_uuid or user_id", ErrTokenMalformed)
+	}
+	return claims, nil
+}
+
+// DecodeRefresh verifies a refresh token and returns its claims. It does
+// not check whether the token is still stored.
+func (c *TokenCodec) DecodeRefresh(tokenString string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	err := c.decode(tokenString, claims, TokenTypeRefresh, func(*jwt.Token) (interface{}, error) {
+		return []byte(c.config.Get().JWTSigningKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.RefreshUuid == "" || claims.UserID == 0 {
+		return nil, fmt.Errorf("%w: missing refresh_uuid or user_id", ErrTokenMalformed)
+	}
+	return claims, nil
+}
+
+// decode parses the token into claims, checking its signature with the key
+// from keyFunc and then the registered claims.
+func (c *TokenCodec) decode(tokenString string, claims claims, typ string, keyFunc jwt.Keyfunc) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Only the algorithm tokens are signed with is accepted, never "none"
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("%w: unexpected signing method %v", ErrTokenBadSignature, token.Header["alg"])
+		}
+		return keyFunc(token)
+	})
+	var validationErr *jwt.ValidationError
+	switch {
+	case err == nil:
+	case !errors.As(err, &validationErr):
+		return err
+	case validationErr.Errors&jwt.ValidationErrorMalformed != 0:
+		return fmt.Errorf("%w: %v", ErrTokenMalformed, validationErr)
+	case validationErr.Errors&jwt.ValidationErrorUnverifiable != 0 && validationErr.Inner != nil:
+		// The key lookup failed; its error says why.
+		return validationErr.Inner
+	default:
+		return ErrTokenBadSignature
+	}
+
+	cfg := c.config.Get()
+	r := claims.registered()
+	switch {
+	case r.Type != typ:
+		return fmt.Errorf("%w: typ is %q, want %q", ErrTokenMalformed, r.Type, typ)
+	case r.Issuer != cfg.TokenIssuer:
+		return fmt.Errorf("%w: unexpected issuer %q", ErrTokenMalformed, r.Issuer)
+	case r.Audience != cfg.TokenAudience:
+		return fmt.Errorf("%w: unexpected audience %q", ErrTokenMalformed, r.Audience)
+	case r.ExpiresAt == 0 || r.IssuedAt == 0:
+		return fmt.Errorf("%w: missing exp or iat", ErrTokenMalformed)
+	}
+
+	now, leeway := time.Now(), cfg.TokenLeeway
+	switch {
+	case now.After(time.Unix(r.ExpiresAt, 0).Add(leeway)):
+		return ErrTokenExpired
+	case now.Add(leeway).Before(time.Unix(r.NotBefore, 0)):
+		return ErrTokenNotYetValid
+	case now.Add(leeway).Before(time.Unix(r.IssuedAt, 0)):
+		return fmt.Errorf("%w: issued in the future", ErrTokenNotYetValid)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// registeredClaims returns claims the codec accepts for a token of type typ,
+// valid from now for a minute.
+func (e *testEnv) registeredClaims(typ string) RegisteredClaims {
+	cfg := e.config.Get()
+	now := time.Now().Unix()
+	return RegisteredClaims{
+		Type:      typ,
+		Issuer:    cfg.TokenIssuer,
+		Audience:  cfg.TokenAudience,
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiresAt: now + 60,
+	}
+}
+
+func (e *testEnv) accessClaims(typ string) *AccessClaims {
+	return &AccessClaims{RegisteredClaims: e.registeredClaims(typ), AccessUuid: "access", UserID: 1}
+}
+
+func (e *testEnv) refreshClaims(typ string) *RefreshClaims {
+	return &RefreshClaims{RegisteredClaims: e.registeredClaims(typ), RefreshUuid: "refresh", UserID: 1}
+}
+
+// signAccess signs claims the way access tokens are, with the active key.
+func (e *testEnv) signAccess(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	id, key, err := e.keys.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = strconv.FormatInt(id, 10)
+	s, err := token.SignedString([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// signRefresh signs claims the way refresh tokens are, with the JWT signing
+// key.
+func (e *testEnv) signRefresh(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(e.config.Get().JWTSigningKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestTokenCodecRoundTrip(t *testing.T) {
+	e := newTestEnv(t)
+
+	access, err := e.codec.EncodeAccess(&AccessClaims{AccessUuid: "a", UserID: 1, TrustLevel: 3,
+		RegisteredClaims: RegisteredClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := e.codec.DecodeAccess(access); err != nil || claims.AccessUuid != "a" || claims.TrustLevel != 3 {
+		t.Errorf("DecodeAccess = %+v, %v", claims, err)
+	}
+
+	refresh, err := e.codec.EncodeRefresh(&RefreshClaims{RefreshUuid: "r", UserID: 1,
+		RegisteredClaims: RegisteredClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := e.codec.DecodeRefresh(refresh); err != nil || claims.RefreshUuid != "r" {
+		t.Errorf("DecodeRefresh = %+v, %v", claims, err)
+	}
+}
+
+func TestTokenCodecType(t *testing.T) {
+	e := newTestEnv(t)
+
+	// Both are signed with the right key, only typ gives them away
+	refreshAsAccess := e.signAccess(t, e.accessClaims(TokenTypeRefresh))
+	if _, err := e.codec.DecodeAccess(refreshAsAccess); !errors.Is(err, ErrTokenMalformed) {
+		t.Errorf("DecodeAccess of a refresh typ = %v, want ErrTokenMalformed", err)
+	}
+	accessAsRefresh := e.signRefresh(t, e.refreshClaims(TokenTypeAccess))
+	if _, err := e.codec.DecodeRefresh(accessAsRefresh); !errors.Is(err, ErrTokenMalformed) {
+		t.Errorf("DecodeRefresh of an access typ = %v, want ErrTokenMalformed", err)
+	}
+	if _, err := e.codec.DecodeAccess(e.signAccess(t, e.accessClaims(""))); !errors.Is(err, ErrTokenMalformed) {
+		t.Errorf("DecodeAccess without typ = %v, want ErrTokenMalformed", err)
+	}
+
+	// Issued tokens cannot stand in for each other either
+	tokens, err := e.auth.Login(t.Context(), "user1", "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := e.auth.VerifyAccessToken(tokens.RefreshToken); err == nil {
+		t.Error("refresh token accepted as access token")
+	}
+	if _, err := e.auth.RefreshToken(t.Context(), tokens.AccessToken); err == nil {
+		t.Error("access token accepted as refresh token")
+	}
+}
+
+func TestTokenCodecIssuerAndAudience(t *testing.T) {
+	e := newTestEnv(t)
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*RegisteredClaims)
+	}{
+		{"issuer", func(r *RegisteredClaims) { r.Issuer = "someone-else" }},
+		{"no issuer", func(r *RegisteredClaims) { r.Issuer = "" }},
+		{"audience", func(r *RegisteredClaims) { r.Audience = "another-app" }},
+		{"no audience", func(r *RegisteredClaims) { r.Audience = "" }},
+	} {
+		access := e.accessClaims(TokenTypeAccess)
+		tc.modify(&access.RegisteredClaims)
+		if _, err := e.codec.DecodeAccess(e.signAccess(t, access)); !errors.Is(err, ErrTokenMalformed) {
+			t.Errorf("DecodeAccess with wrong %s = %v, want ErrTokenMalformed", tc.name, err)
+		}
+		refresh := e.refreshClaims(TokenTypeRefresh)
+		tc.modify(&refresh.RegisteredClaims)
+		if _, err := e.codec.DecodeRefresh(e.signRefresh(t, refresh)); !errors.Is(err, ErrTokenMalformed) {
+			t.Errorf("DecodeRefresh with wrong %s = %v, want ErrTokenMalformed", tc.name, err)
+		}
+	}
+
+	// A service sharing the signing key but serving another audience
+	other := newTestEnv(t, "TOKEN_AUDIENCE", "another-app")
+	refresh, err := other.codec.EncodeRefresh(other.refreshClaims(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.codec.DecodeRefresh(refresh); err != nil {
+		t.Fatalf("DecodeRefresh by the issuing service: %v", err)
+	}
+	if _, err := e.codec.DecodeRefresh(refresh); !errors.Is(err, ErrTokenMalformed) {
+		t.Errorf("DecodeRefresh of another audience's token = %v, want ErrTokenMalformed", err)
+	}
+}
+
+func TestTokenCodecLeeway(t *testing.T) {
+	e := newTestEnv(t, "TOKEN_LEEWAY", "30s")
+	now := time.Now().Unix()
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*RegisteredClaims)
+		want   error
+	}{
+		{"expired within leeway", func(r *RegisteredClaims) { r.ExpiresAt = now - 25 }, nil},
+		{"expired beyond leeway", func(r *RegisteredClaims) { r.ExpiresAt = now - 35 }, ErrTokenExpired},
+		{"valid soon within leeway", func(r *RegisteredClaims) { r.NotBefore = now + 25 }, nil},
+		{"valid soon beyond leeway", func(r *RegisteredClaims) { r.NotBefore = now + 35 }, ErrTokenNotYetValid},
+		{"issued ahead within leeway", func(r *RegisteredClaims) { r.IssuedAt, r.NotBefore = now+25, now+25 }, nil},
+		{"issued ahead beyond leeway", func(r *RegisteredClaims) { r.IssuedAt = now + 35 }, ErrTokenNotYetValid},
+		{"no exp", func(r *RegisteredClaims) { r.ExpiresAt = 0 }, ErrTokenMalformed},
+	} {
+		access := e.accessClaims(TokenTypeAccess)
+		tc.modify(&access.RegisteredClaims)
+		_, err := e.codec.DecodeAccess(e.signAccess(t, access))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("DecodeAccess %s = %v, want %v", tc.name, err, tc.want)
+		}
+		refresh := e.refreshClaims(TokenTypeRefresh)
+		tc.modify(&refresh.RegisteredClaims)
+		_, err = e.codec.DecodeRefresh(e.signRefresh(t, refresh))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("DecodeRefresh %s = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	// Without leeway the boundary is exact to the second
+	strict := newTestEnv(t, "TOKEN_LEEWAY", "0s")
+	access := strict.accessClaims(TokenTypeAccess)
+	access.ExpiresAt = time.Now().Unix() - 2
+	if _, err := strict.codec.DecodeAccess(strict.signAccess(t, access)); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("DecodeAccess of a just expired token without leeway = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestTokenCodecSigningMethod(t *testing.T) {
+	e := newTestEnv(t)
+	id, accessKey, err := e.keys.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		method     jwt.SigningMethod
+		accessKey  any
+		refreshKey any
+	}{
+		{jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.UnsafeAllowNoneSignatureType},
+		{jwt.SigningMethodHS384, []byte(accessKey), []byte(e.config.Get().JWTSigningKey)},
+		{jwt.SigningMethodHS512, []byte(accessKey), []byte(e.config.Get().JWTSigningKey)},
+		{jwt.SigningMethodRS256, rsaKey, rsaKey},
+	} {
+		alg := tc.method.Alg()
+
+		token := jwt.NewWithClaims(tc.method, e.accessClaims(TokenTypeAccess))
+		token.Header["kid"] = strconv.FormatInt(id, 10)
+		access, err := token.SignedString(tc.accessKey)
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if _, err := e.codec.DecodeAccess(access); !errors.Is(err, ErrTokenBadSignature) {
+			t.Errorf("DecodeAccess of %s = %v, want ErrTokenBadSignature", alg, err)
+		}
+
+		refresh, err := jwt.NewWithClaims(tc.method, e.refreshClaims(TokenTypeRefresh)).SignedString(tc.refreshKey)
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if _, err := e.codec.DecodeRefresh(refresh); !errors.Is(err, ErrTokenBadSignature) {
+			t.Errorf("DecodeRefresh of %s = %v, want ErrTokenBadSignature", alg, err)
+		}
+	}
+
+	// HS256 with another key
+	refresh, err := jwt.NewWithClaims(jwt.SigningMethodHS256, e.refreshClaims(TokenTypeRefresh)).SignedString([]byte("not-the-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.codec.DecodeRefresh(refresh); !errors.Is(err, ErrTokenBadSignature) {
+		t.Errorf("DecodeRefresh with another key = %v, want ErrTokenBadSignature", err)
+	}
+}
@@ -2,12 +2,13 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"forum-app/auth-service/internal"
 )
 
 func main() {
-	if err := internal.Run(); err != nil {
+	if err := internal.RunCLI(os.Args[1:]); err != nil {
 		log.Fatal(err)
 	}
 }
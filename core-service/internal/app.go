@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"core-service/internal/config"
+	"core-service/internal/controllers/rest"
+	"core-service/internal/handlers"
+	"core-service/internal/notification"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+	"core-service/internal/spam"
+	"core-service/internal/storage"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func Run() error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	db, err := repository.OpenSQLiteDB(cfg.SQLitePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Cancelled when the server has stopped or Run returns early. The
+	// background workers are waited for before the database closes.
+	ctx, cancel := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer workers.Wait()
+	defer cancel()
+
+	// Initialize Repositories
+	categoryRepo := repository.NewSQLiteCategoryRepository(db)
+	topicRepo := repository.NewSQLiteTopicRepository(db)
+	postRepo := repository.NewSQLitePostRepository(db)
+	subscriptionRepo := repository.NewSQLiteSubscriptionRepository(db)
+	notificationRepo := repository.NewSQLiteNotificationRepository(db)
+	messageRepo := repository.NewSQLiteMessageRepository(db)
+	blockRepo := repository.NewSQLiteBlockRepository(db)
+	attachmentRepo := repository.NewSQLiteAttachmentRepository(db)
+	tagRepo := repository.NewSQLiteTagRepository(db)
+	reviewRepo := repository.NewSQLiteReviewRepository(db)
+	spamRepo := repository.NewSQLiteSpamRepository(db)
+	reputationRepo := repository.NewSQLiteReputationRepository(db)
+	voteRepo := repository.NewSQLiteVoteRepository(db)
+	flagRepo := repository.NewSQLiteFlagRepository(db)
+	pollRepo := repository.NewSQLitePollRepository(db)
+	draftRepo := repository.NewSQLiteDraftRepository(db)
+	scheduleRepo := repository.NewSQLiteScheduleRepository(db)
+	readRepo := repository.NewSQLiteReadRepository(db)
+	profileRepo := repository.NewSQLiteProfileRepository(db)
+
+	// Initialize Blob Storage
+	var blobStore storage.BlobStore
+	switch cfg.AttachmentStorage {
+	case "s3":
+		blobStore = storage.NewS3BlobStore(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		blobStore, err = storage.NewLocalBlobStore(cfg.AttachmentDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Initialize Spam Checks
+	blocklist, err := spam.LoadBlocklist(cfg.SpamBlocklistPath)
+	if err != nil {
+		return err
+	}
+	classifier := spam.NewBayesCheck(spamRepo, cfg.SpamThreshold)
+	checks := spam.NewPipeline(
+		blocklist,
+		spam.NewNewAccountCheck(postRepo, cfg.SpamNewAccountAge, cfg.SpamNewAccountPostsPerHour),
+		spam.NewDuplicateCheck(spamRepo, cfg.SpamDuplicateWindow),
+		spam.NewLinkCheck(cfg.SpamMaxLinks, cfg.SpamMaxLinkDensity),
+		classifier,
+	)
+
+	authClient := rest.NewAuthClient(cfg.AuthServiceURL, cfg.AuthServiceToken)
+	hub := realtime.NewHub(cfg.StreamBufferSize)
+
+	// Initialize Use Cases
+	notificationUseCase := usecase.NewNotificationUseCase(authClient, notificationRepo, subscriptionRepo,
+		postRepo, topicRepo, categoryRepo, notification.NewInAppChannel(hub))
+	roles := usecase.NewRoles(cfg.ModeratorIDs)
+	reputationUseCase := usecase.NewReputationUseCase(authClient, reputationRepo, roles)
+	postUseCase := usecase.NewPostUseCase(authClient, postRepo, topicRepo, attachmentRepo, reviewRepo, hub,
+		notificationUseCase, checks, reputationUseCase, roles, cfg.PostEditWindow)
+	voteUseCase := usecase.NewVoteUseCase(authClient, voteRepo, postUseCase, reputationUseCase)
+	flagUseCase := usecase.NewFlagUseCase(authClient, flagRepo, postRepo, postUseCase, reputationUseCase, roles,
+		cfg.FlagHideThreshold)
+	readUseCase := usecase.NewReadUseCase(authClient, readRepo, topicRepo, categoryRepo, postRepo, profileRepo)
+	tagUseCase := usecase.NewTagUseCase(authClient, tagRepo, topicRepo, readUseCase, roles)
+	topicUseCase := usecase.NewTopicUseCase(authClient, categoryRepo, topicRepo, postUseCase, tagUseCase, reputationUseCase, readUseCase, roles)
+	pollUseCase := usecase.NewPollUseCase(authClient, pollRepo, topicRepo, hub, roles)
+	profileUseCase := usecase.NewProfileUseCase(authClient, profileRepo, postRepo, topicRepo, attachmentRepo, postUseCase, tagUseCase, roles)
+	draftUseCase := usecase.NewDraftUseCase(authClient, draftRepo, topicRepo)
+	scheduleUseCase := usecase.NewScheduleUseCase(authClient, scheduleRepo, categoryRepo, postUseCase, topicUseCase)
+	reviewUseCase := usecase.NewReviewUseCase(authClient, reviewRepo, postUseCase, topicUseCase, classifier, roles)
+	streamUseCase := usecase.NewStreamUseCase(authClient, hub, categoryRepo, topicRepo)
+	messageUseCase := usecase.NewMessageUseCase(authClient, messageRepo, blockRepo, hub)
+	attachmentUseCase := usecase.NewAttachmentUseCase(authClient, attachmentRepo, blobStore, reputationUseCase, roles,
+		cfg.MaxUploadBytes)
+	purgeUseCase := usecase.NewPurgeUseCase(topicRepo, postRepo, attachmentRepo, spamRepo, draftRepo, blobStore,
+		cfg.ContentRetention, cfg.DraftRetention)
+
+	// Initialize Gin Router
+	router := gin.Default()
+
+	handlers.SetupTopicRoutes(router, topicUseCase)
+	handlers.SetupTagRoutes(router, tagUseCase)
+	handlers.SetupPostRoutes(router, postUseCase)
+	handlers.SetupPollRoutes(router, pollUseCase)
+	handlers.SetupDraftRoutes(router, draftUseCase, scheduleUseCase)
+	handlers.SetupReadRoutes(router, readUseCase)
+	handlers.SetupProfileRoutes(router, profileUseCase)
+	handlers.SetupReviewRoutes(router, reviewUseCase)
+	handlers.SetupReputationRoutes(router, reputationUseCase, voteUseCase, flagUseCase)
+	handlers.SetupStreamRoutes(router, streamUseCase)
+	handlers.SetupNotificationRoutes(router, notificationUseCase, streamUseCase)
+	handlers.SetupMessageRoutes(router, messageUseCase)
+	handlers.SetupAttachmentRoutes(router, attachmentUseCase, cfg.MaxUploadBytes)
+
+	// Start Notification Digests in the Background
+	digestChannels := []notification.Channel{notification.NewLogEmailChannel(cfg.EmailLogPath)}
+	if cfg.WebhookURL != "" {
+		digestChannels = append(digestChannels, notification.NewWebhookChannel(cfg.WebhookURL))
+	}
+	dispatcher := notification.NewDispatcher(notificationRepo, cfg.DigestInterval, digestChannels...)
+	for _, work := range []func(){
+		func() { dispatcher.Run(ctx) },
+		func() { usecase.PurgeDeletedPeriodically(ctx, purgeUseCase, cfg.PurgeInterval) },
+		func() { usecase.PublishScheduledPeriodically(ctx, scheduleUseCase, cfg.ScheduleInterval) },
+		func() { usecase.FlushReadsPeriodically(ctx, readUseCase, cfg.ReadFlushInterval) },
+	} {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			work()
+		}()
+	}
+
+	// Server setup
+	server := &http.Server{
+		Addr:    cfg.Port,
+		Handler: router,
+	}
+
+	// Graceful shutdown
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		log.Println("Shutting down server...")
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Fatal("Server shutdown:", err)
+		}
+		log.Println("Server gracefully stopped")
+	}()
+
+	log.Println("Starting server on", cfg.Port)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	// Let requests and background jobs finish, then keep what users read in
+	// the last moments before the database closes.
+	<-shutdownDone
+	cancel()
+	workers.Wait()
+	if err := readUseCase.Flush(context.Background()); err != nil {
+		log.Printf("Error saving read markers: %v", err)
+	}
+	return nil
+}
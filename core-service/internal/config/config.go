@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	Port           string
+	SQLitePath     string
+	AuthServiceURL string
+	// AuthServiceToken is presented to the auth-service to look users up.
+	AuthServiceToken string
+	// Events buffered per stream subscriber before it is dropped as too slow.
+	StreamBufferSize int
+	// How often queued notifications are sent out as per-user digests.
+	DigestInterval time.Duration
+	WebhookURL     string
+	EmailLogPath   string
+
+	// Attachments
+	AttachmentStorage string // "local" or "s3"
+	AttachmentDir     string
+	MaxUploadBytes    int64
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+
+	// Moderation
+	ModeratorIDs []int
+	// How long authors may edit their own posts; 0 disables the limit.
+	PostEditWindow time.Duration
+	// How long deleted content can be restored before it is purged for good.
+	ContentRetention time.Duration
+	PurgeInterval    time.Duration
+	// How long untouched drafts are kept.
+	DraftRetention time.Duration
+	// How often the scheduler looks for topics and replies due to publish.
+	ScheduleInterval time.Duration
+	// How often buffered read markers are written.
+	ReadFlushInterval time.Duration
+
+	// Spam checks
+	SpamMaxLinks        int
+	SpamMaxLinkDensity  float64 // share of the text that may be links
+	SpamDuplicateWindow time.Duration
+	// Accounts whose first post is younger than this are rate limited.
+	SpamNewAccountAge          time.Duration
+	SpamNewAccountPostsPerHour int
+	SpamBlocklistPath          string
+	// Classifier score at which posts are held for review.
+	SpamThreshold float64
+
+	// Total flag weight at which a post is hidden until a moderator looks at it.
+	FlagHideThreshold int
+}
+
+// LoadConfig reads the configuration from the environment and .env. It fails
+// on settings the service cannot run with.
+func LoadConfig() (*Config, error) {
+	err := godotenv.Load(".env")
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
+
+	cfg := &Config{
+		Port:             GetString("CORE_SERVICE_PORT", ":8081"),
+		SQLitePath:       GetString("SQLITE_PATH", "./forum.db"),
+		AuthServiceURL:   GetString("AUTH_SERVICE_URL", "http://localhost:8080"),
+		AuthServiceToken: GetString("AUTH_SERVICE_TOKEN", ""),
+		StreamBufferSize: GetInt("STREAM_BUFFER_SIZE", 64),
+		DigestInterval:   GetDuration("NOTIFY_DIGEST_INTERVAL", 10*time.Minute),
+		WebhookURL:       GetString("NOTIFY_WEBHOOK_URL", ""),
+		EmailLogPath:     GetString("NOTIFY_EMAIL_LOG", ""),
+
+		AttachmentStorage: GetString("ATTACHMENT_STORAGE", "local"),
+		AttachmentDir:     GetString("ATTACHMENT_DIR", "./attachments"),
+		MaxUploadBytes:    int64(GetInt("MAX_UPLOAD_BYTES", 10<<20)),
+		S3Endpoint:        GetString("S3_ENDPOINT", "http://localhost:9000"),
+		S3Region:          GetString("S3_REGION", "us-east-1"),
+		S3Bucket:          GetString("S3_BUCKET", "forum-attachments"),
+		S3AccessKey:       GetString("S3_ACCESS_KEY", ""),
+		S3SecretKey:       GetString("S3_SECRET_KEY", ""),
+
+		ModeratorIDs:   GetIntList("MODERATOR_IDS"),
+		PostEditWindow: GetDuration("POST_EDIT_WINDOW", 24*time.Hour),
+
+		ContentRetention:  GetDuration("CONTENT_RETENTION", 30*24*time.Hour),
+		PurgeInterval:     GetDuration("PURGE_INTERVAL", time.Hour),
+		DraftRetention:    GetDuration("DRAFT_RETENTION", 90*24*time.Hour),
+		ScheduleInterval:  GetDuration("SCHEDULE_INTERVAL", 30*time.Second),
+		ReadFlushInterval: GetDuration("READ_FLUSH_INTERVAL", 5*time.Second),
+
+		SpamMaxLinks:               GetInt("SPAM_MAX_LINKS", 5),
+		SpamMaxLinkDensity:         GetFloat("SPAM_MAX_LINK_DENSITY", 0.5),
+		SpamDuplicateWindow:        GetDuration("SPAM_DUPLICATE_WINDOW", 24*time.Hour),
+		SpamNewAccountAge:          GetDuration("SPAM_NEW_ACCOUNT_AGE", 24*time.Hour),
+		SpamNewAccountPostsPerHour: GetInt("SPAM_NEW_ACCOUNT_POSTS_PER_HOUR", 5),
+		SpamBlocklistPath:          GetString("SPAM_BLOCKLIST", ""),
+		SpamThreshold:              GetFloat("SPAM_THRESHOLD", 0.95),
+
+		FlagHideThreshold: GetInt("FLAG_HIDE_THRESHOLD", 5),
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate checks the intervals the background workers tick at, as a ticker
+// cannot run at zero or negative intervals.
+func (c *Config) validate() error {
+	var errs []error
+	for _, interval := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"NOTIFY_DIGEST_INTERVAL", c.DigestInterval},
+		{"PURGE_INTERVAL", c.PurgeInterval},
+		{"SCHEDULE_INTERVAL", c.ScheduleInterval},
+		{"READ_FLUSH_INTERVAL", c.ReadFlushInterval},
+	} {
+		if interval.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %v", interval.key, interval.value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func GetString(key string, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func GetInt(key string, defaultValue int) int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func GetFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func GetDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetIntList parses a comma-separated list of integers, skipping bad entries.
+func GetIntList(key string) []int {
+	var values []int
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigIntervals(t *testing.T) {
+	t.Chdir(t.TempDir())
+	keys := []string{"NOTIFY_DIGEST_INTERVAL", "PURGE_INTERVAL", "SCHEDULE_INTERVAL", "READ_FLUSH_INTERVAL"}
+	for _, key := range keys {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig with defaults: %v", err)
+	}
+	if cfg.ScheduleInterval != 30*time.Second {
+		t.Errorf("SCHEDULE_INTERVAL = %v, want the default 30s", cfg.ScheduleInterval)
+	}
+
+	t.Setenv("PURGE_INTERVAL", "0s")
+	t.Setenv("READ_FLUSH_INTERVAL", "-5s")
+	cfg, err = LoadConfig()
+	if cfg != nil || err == nil {
+		t.Fatalf("LoadConfig with zero and negative intervals = %+v, %v", cfg, err)
+	}
+	for _, want := range []string{"PURGE_INTERVAL must be positive, got 0s", "READ_FLUSH_INTERVAL must be positive, got -5s"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "SCHEDULE_INTERVAL") {
+		t.Errorf("error %q mentions a valid interval", err)
+	}
+}
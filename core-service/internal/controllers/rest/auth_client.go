@@ -3,37 +3,110 @@ package rest
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrUserNotFound = errors.New("user not found")
 )
 
 type AuthClient struct {
-	baseURL string
+	baseURL      string
+	serviceToken string
+	client       *http.Client
+}
+
+// NewAuthClient returns a client for the auth-service at baseURL. The service
+// token authorizes user lookups.
+func NewAuthClient(baseURL, serviceToken string) *AuthClient {
+	return &AuthClient{
+		baseURL:      baseURL,
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: 2 * time.Second},
+	}
 }
 
-func NewAuthClient(baseURL string) *AuthClient {
-	return &AuthClient{baseURL: baseURL}
+type validateResult struct {
+	Valid  bool `json:"valid"`
+	UserID int  `json:"user_id"`
 }
 
 func (c *AuthClient) ValidateToken(token string) (bool, error) {
+	result, err := c.validate(token)
+	if err != nil {
+		return false, err
+	}
+
+	return result.Valid, nil
+}
+
+// ResolveUserID returns the id of the user the access token was issued to.
+func (c *AuthClient) ResolveUserID(token string) (int, error) {
+	result, err := c.validate(token)
+	if err != nil {
+		return 0, err
+	}
+	if !result.Valid {
+		return 0, ErrInvalidToken
+	}
+
+	return result.UserID, nil
+}
+
+// LookupUserID resolves a username to the id the auth-service knows it by.
+func (c *AuthClient) LookupUserID(username string) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/users/"+url.PathEscape(username), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.serviceToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, ErrUserNotFound
+	case resp.StatusCode != http.StatusOK:
+		return 0, fmt.Errorf("auth-service responded with %s", resp.Status)
+	}
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+func (c *AuthClient) validate(token string) (*validateResult, error) {
 	reqBody := map[string]string{"token": token}
 	jsonBody, _ := json.Marshal(reqBody)
 
-	resp, err := http.Post(
+	resp, err := c.client.Post(
 		c.baseURL+"/validate",
 		"application/json",
 		bytes.NewBuffer(jsonBody),
 	)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var result struct {
-		Valid bool `json:"valid"`
-	}
+	var result validateResult
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return result.Valid, nil
+	return &result, nil
 }
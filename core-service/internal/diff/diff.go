@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+type Edit struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+var wordPattern = regexp.MustCompile(`\w+|\s+|[^\w\s]`)
+
+// Words returns a word-level diff of a and b. Adjacent edits of the same kind
+// are merged, so the result alternates between equal, deleted and inserted
+// runs of text.
+func Words(a, b string) []Edit {
+	edits := tokens(wordPattern.FindAllString(a, -1), wordPattern.FindAllString(b, -1))
+
+	var merged []Edit
+	for _, e := range edits {
+		if n := len(merged); n > 0 && merged[n-1].Op == e.Op {
+			merged[n-1].Text += e.Text
+			continue
+		}
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// Unified renders a line-based diff in unified format with the given number
+// of context lines around each change.
+func Unified(a, b, fromLabel, toLabel string, context int) string {
+	edits := tokens(strings.Split(a, "\n"), strings.Split(b, "\n"))
+
+	type line struct {
+		Edit
+		aLine, bLine int
+	}
+	lines := make([]line, len(edits))
+	var changes []int
+	ai, bi := 1, 1
+	for i, e := range edits {
+		lines[i] = line{Edit: e, aLine: ai, bLine: bi}
+		switch e.Op {
+		case OpEqual:
+			ai++
+			bi++
+		case OpDelete:
+			ai++
+			changes = append(changes, i)
+		case OpInsert:
+			bi++
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+
+	for i := 0; i < len(changes); {
+		start := max(0, changes[i]-context)
+		end := changes[i]
+		for i < len(changes) && changes[i]-end <= 2*context {
+			end = changes[i]
+			i++
+		}
+		end = min(len(lines)-1, end+context)
+
+		var aCount, bCount int
+		for _, l := range lines[start : end+1] {
+			if l.Op != OpInsert {
+				aCount++
+			}
+			if l.Op != OpDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", lines[start].aLine, aCount, lines[start].bLine, bCount)
+
+		for _, l := range lines[start : end+1] {
+			prefix := " "
+			switch l.Op {
+			case OpDelete:
+				prefix = "-"
+			case OpInsert:
+				prefix = "+"
+			}
+			out.WriteString(prefix + l.Text + "\n")
+		}
+	}
+	return out.String()
+}
+
+// tokens computes a shortest edit script between two token sequences using
+// Myers' O(ND) algorithm.
+func tokens(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string, offset int) []Edit {
+	x, y := len(a), len(b)
+	var edits []Edit
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{Op: OpEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{Op: OpInsert, Text: b[y-1]})
+				y--
+			} else {
+				edits = append(edits, Edit{Op: OpDelete, Text: a[x-1]})
+				x--
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
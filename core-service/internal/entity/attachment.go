@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Attachment struct {
+	ID           int64      `json:"id"`
+	UploaderID   int        `json:"uploader_id"`
+	PostID       int64      `json:"post_id,omitempty"`
+	Filename     string     `json:"filename"`
+	ContentType  string     `json:"content_type"`
+	Size         int64      `json:"size"`
+	SHA256       string     `json:"sha256"`
+	StorageKey   string     `json:"-"`
+	ThumbnailKey string     `json:"-"`
+	HasThumbnail bool       `json:"has_thumbnail"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy    int        `json:"deleted_by,omitempty"`
+}
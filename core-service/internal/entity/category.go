@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+type Category struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// In Q&A categories topics are questions and replies can be accepted as
+	// their answer.
+	QAMode    bool      `json:"qa_mode"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// Draft is a user's autosaved, unpublished text: a reply to a topic, or with
+// TopicID 0 a new topic. A user has one draft per topic.
+type Draft struct {
+	UserID     int    `json:"user_id"`
+	TopicID    int64  `json:"topic_id"`
+	CategoryID int64  `json:"category_id,omitempty"`
+	ReplyToID  int64  `json:"reply_to_id,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Body       string `json:"body"`
+	// Sequence is chosen by the client and must grow with every save, so an
+	// autosave arriving late cannot overwrite newer text.
+	Sequence  int64     `json:"sequence"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ScheduleStatus string
+
+const (
+	SchedulePending    ScheduleStatus = "pending"
+	SchedulePublishing ScheduleStatus = "publishing"
+	SchedulePublished  ScheduleStatus = "published"
+	ScheduleFailed     ScheduleStatus = "failed"
+)
+
+// ScheduledPost is a topic or reply to be published at PublishAt. Like a
+// PostReview it opens a new topic when it has no TopicID. The content was
+// screened when it was scheduled.
+type ScheduledPost struct {
+	ID         int64          `json:"id"`
+	AuthorID   int            `json:"author_id"`
+	CategoryID int64          `json:"category_id,omitempty"`
+	TopicID    int64          `json:"topic_id,omitempty"`
+	ReplyToID  int64          `json:"reply_to_id,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Body       string         `json:"body"`
+	Tags       []string       `json:"tags,omitempty"`
+	PublishAt  time.Time      `json:"publish_at"`
+	Status     ScheduleStatus `json:"status"`
+	Attempts   int            `json:"attempts"`
+	// PostID is the published post; Error says why publishing failed.
+	PostID    int64     `json:"post_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
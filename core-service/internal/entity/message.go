@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+type Conversation struct {
+	ID            int64     `json:"id"`
+	Subject       string    `json:"subject"`
+	CreatedBy     int       `json:"created_by"`
+	Participants  []int     `json:"participants"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at"`
+}
+
+type Message struct {
+	ID             int64     `json:"id"`
+	ConversationID int64     `json:"conversation_id"`
+	SenderID       int       `json:"sender_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// InboxEntry is a conversation as seen from one participant's inbox.
+type InboxEntry struct {
+	Conversation
+	Unread int `json:"unread"`
+}
+
+type Block struct {
+	UserID    int       `json:"user_id"`
+	BlockedID int       `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
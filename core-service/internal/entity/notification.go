@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationReply   NotificationKind = "reply"
+	NotificationMention NotificationKind = "mention"
+	NotificationWatched NotificationKind = "watched"
+)
+
+type Notification struct {
+	ID        int64            `json:"id"`
+	UserID    int              `json:"user_id"`
+	Kind      NotificationKind `json:"kind"`
+	ActorID   int              `json:"actor_id"`
+	TopicID   int64            `json:"topic_id"`
+	PostID    int64            `json:"post_id"`
+	Read      bool             `json:"read"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Subscription makes a user watch either a topic or a whole category.
+type Subscription struct {
+	UserID     int       `json:"user_id"`
+	TopicID    int64     `json:"topic_id,omitempty"`
+	CategoryID int64     `json:"category_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// PollResults decides when voters get to see a poll's results.
+type PollResults string
+
+const (
+	PollResultsAlways  PollResults = "always"
+	PollResultsOnVote  PollResults = "on_vote"
+	PollResultsOnClose PollResults = "on_close"
+)
+
+// Poll is attached to a topic, at most one per topic. Votes of anonymous
+// polls are still stored per user to allow one ballot each, but voters are
+// never shown.
+type Poll struct {
+	ID        int64        `json:"id"`
+	TopicID   int64        `json:"topic_id"`
+	Question  string       `json:"question"`
+	Multiple  bool         `json:"multiple"`
+	Anonymous bool         `json:"anonymous"`
+	Results   PollResults  `json:"results"`
+	ClosesAt  *time.Time   `json:"closes_at,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	Options   []PollOption `json:"options"`
+
+	// The fields below depend on who is looking at the poll.
+	Closed         bool    `json:"closed"`
+	ResultsVisible bool    `json:"results_visible"`
+	Voters         int     `json:"voters"`
+	MyChoices      []int64 `json:"my_choices,omitempty"`
+}
+
+type PollOption struct {
+	ID    int64  `json:"id"`
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+	// VoterIDs is only filled for public polls whose results are visible.
+	VoterIDs []int `json:"voter_ids,omitempty"`
+}
+
+// IsClosed reports whether the poll no longer accepts votes at the given time.
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+}
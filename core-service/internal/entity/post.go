@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type Post struct {
+	ID        int64  `json:"id"`
+	TopicID   int64  `json:"topic_id"`
+	AuthorID  int    `json:"author_id"`
+	ReplyToID int64  `json:"reply_to_id,omitempty"`
+	Body      string `json:"body"`
+	Score     int    `json:"score"`
+	// Wiki posts may be edited by any member of sufficient trust level.
+	Wiki bool `json:"wiki,omitempty"`
+	// Accepted marks the accepted answer when posts are listed.
+	Accepted  bool      `json:"accepted,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set on soft-deleted posts, which are listed as tombstones
+	// without their body until restored or purged.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy int        `json:"deleted_by,omitempty"`
+
+	// AttachmentIDs lists previously uploaded attachments to link to a new
+	// post; Attachments is filled when posts are read back.
+	AttachmentIDs []int64      `json:"-"`
+	Attachments   []Attachment `json:"attachments,omitempty"`
+	// ScheduledID names the scheduled item a new post publishes; the item
+	// is marked published together with the post.
+	ScheduledID int64 `json:"-"`
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Visibility says who may see a part of a profile. Moderators and the user
+// themselves always see everything.
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityMembers Visibility = "members"
+	VisibilityPrivate Visibility = "private"
+)
+
+type ProfilePrivacy struct {
+	Location Visibility `json:"location"`
+	LastSeen Visibility `json:"last_seen"`
+	// Activity covers the user's topics and posts listed on their profile.
+	Activity Visibility `json:"activity"`
+}
+
+// Profile is the forum-side data about a user, keyed by the auth-service
+// user id. Counts and dates are kept by the forum itself.
+type Profile struct {
+	UserID      int    `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	// AvatarID is an image the user uploaded as an attachment.
+	AvatarID   int64      `json:"avatar_id,omitempty"`
+	AvatarURL  string     `json:"avatar_url,omitempty"`
+	Bio        string     `json:"bio"`
+	Signature  string     `json:"signature"`
+	Location   string     `json:"location,omitempty"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	PostCount  int        `json:"post_count"`
+	TopicCount int        `json:"topic_count"`
+	// Privacy is only shown to the user themselves.
+	Privacy *ProfilePrivacy `json:"privacy,omitempty"`
+}
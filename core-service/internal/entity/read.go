@@ -0,0 +1,16 @@
+package entity
+
+// ReadMarker records that a user has read a topic up to a post.
+type ReadMarker struct {
+	UserID  int
+	TopicID int64
+	PostID  int64
+}
+
+// ReadState tells a user where they left off in a topic. Topics the user
+// never opened have no read state.
+type ReadState struct {
+	LastReadPostID    int64 `json:"last_read_post_id"`
+	UnreadCount       int   `json:"unread_count"`
+	FirstUnreadPostID int64 `json:"first_unread_post_id,omitempty"`
+}
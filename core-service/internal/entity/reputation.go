@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// TrustLevel grows with a user's reputation and time spent on the forum and
+// unlocks capabilities step by step.
+type TrustLevel int
+
+const (
+	TrustNew TrustLevel = iota
+	TrustBasic
+	TrustMember
+	TrustRegular
+)
+
+func (l TrustLevel) String() string {
+	switch l {
+	case TrustBasic:
+		return "basic"
+	case TrustMember:
+		return "member"
+	case TrustRegular:
+		return "regular"
+	default:
+		return "new"
+	}
+}
+
+type ReputationEventKind string
+
+const (
+	ReputationVote           ReputationEventKind = "vote"
+	ReputationAcceptedAnswer ReputationEventKind = "accepted_answer"
+	ReputationDayActive      ReputationEventKind = "day_active"
+)
+
+// ReputationEvent is one entry of the ledger reputation is computed from. An
+// event is identified by its kind, source and actor, so the same vote or
+// accepted answer is counted once and can be taken back.
+type ReputationEvent struct {
+	UserID    int                 `json:"user_id"`
+	Kind      ReputationEventKind `json:"kind"`
+	SourceID  int64               `json:"source_id"`
+	ActorID   int                 `json:"actor_id"`
+	Points    int                 `json:"points"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+type Reputation struct {
+	UserID     int        `json:"user_id"`
+	Points     int        `json:"reputation"`
+	DaysActive int        `json:"days_active"`
+	TrustLevel TrustLevel `json:"trust_level"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type Flag struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	UserID    int       `json:"user_id"`
+	Reason    string    `json:"reason"`
+	Weight    int       `json:"weight"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewSpam     ReviewStatus = "spam"
+	// ReviewRejected is set right away for submissions the checks refused
+	// outright; they are kept only as a record.
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// PostReview records a submission that did not pass the content checks,
+// together with the reasons. A held post is published only once a moderator
+// approves it. Submissions that would open a new topic carry its category
+// and title and have no TopicID.
+type PostReview struct {
+	ID            int64        `json:"id"`
+	AuthorID      int          `json:"author_id"`
+	CategoryID    int64        `json:"category_id,omitempty"`
+	TopicID       int64        `json:"topic_id,omitempty"`
+	ReplyToID     int64        `json:"reply_to_id,omitempty"`
+	Title         string       `json:"title,omitempty"`
+	Body          string       `json:"body"`
+	AttachmentIDs []int64      `json:"attachment_ids,omitempty"`
+	Verdict       string       `json:"verdict"`
+	Reasons       []string     `json:"reasons"`
+	Status        ReviewStatus `json:"status"`
+	ReviewedBy    int          `json:"reviewed_by,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	ReviewedAt    *time.Time   `json:"reviewed_at,omitempty"`
+}
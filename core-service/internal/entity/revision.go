@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// PostRevision is an immutable snapshot of a post body. Revision 1 is the
+// body the post was created with.
+type PostRevision struct {
+	PostID    int64     `json:"post_id"`
+	Number    int       `json:"number"`
+	EditorID  int       `json:"editor_id"`
+	Body      string    `json:"body"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type Tag struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	TopicCount int       `json:"topic_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Synonyms are alternative names that resolve to this tag.
+	Synonyms []string `json:"synonyms,omitempty"`
+}
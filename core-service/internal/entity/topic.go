@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type Topic struct {
+	ID             int64      `json:"id"`
+	CategoryID     int64      `json:"category_id"`
+	AuthorID       int        `json:"author_id"`
+	Title          string     `json:"title"`
+	PostCount      int        `json:"post_count"`
+	Score          int        `json:"score"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy      int        `json:"deleted_by,omitempty"`
+	Tags           []string   `json:"tags"`
+	// AcceptedPostID is the reply accepted as the answer in Q&A categories;
+	// AcceptedAnswer carries that post when a single topic is read.
+	AcceptedPostID int64 `json:"accepted_post_id,omitempty"`
+	AcceptedAnswer *Post `json:"accepted_answer,omitempty"`
+	// Read is the requesting user's read state, if they opened the topic.
+	Read *ReadState `json:"read,omitempty"`
+}
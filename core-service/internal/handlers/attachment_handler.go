@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is allowed on top of the file size limit for the
+// multipart framing around the file.
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	attachments *usecase.AttachmentUseCase
+	maxSize     int64
+}
+
+func NewAttachmentHandler(attachments *usecase.AttachmentUseCase, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{attachments: attachments, maxSize: maxSize}
+}
+
+// Upload expects a multipart/form-data body with the file in the "file"
+// field. Parts are streamed, so nothing beyond the size limit is buffered.
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment, err := h.attachments.Upload(c.Request.Context(), bearerToken(c), part.FileName(), part)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				err = usecase.ErrTooLarge
+			}
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"attachment": attachment})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "file field is required"})
+}
+
+func (h *AttachmentHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.attachments.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachment": attachment})
+}
+
+func (h *AttachmentHandler) Content(c *gin.Context) {
+	h.serve(c, false)
+}
+
+func (h *AttachmentHandler) Thumbnail(c *gin.Context) {
+	h.serve(c, true)
+}
+
+func (h *AttachmentHandler) serve(c *gin.Context, thumbnail bool) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.attachments.Open(c.Request.Context(), id, thumbnail)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer content.Close()
+
+	contentType, size := attachment.ContentType, attachment.Size
+	if thumbnail {
+		contentType, size = "image/"+thumbnailFormat(attachment.ContentType), -1
+	}
+
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, content, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("%s; filename=%q", disposition, attachment.Filename),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=86400",
+	})
+}
+
+func thumbnailFormat(contentType string) string {
+	if contentType == "image/jpeg" {
+		return "jpeg"
+	}
+	return "png"
+}
+
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.attachments.Delete(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AttachmentHandler) Restore(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.attachments.Restore(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachment": attachment})
+}
+
+func SetupAttachmentRoutes(router *gin.Engine, attachments *usecase.AttachmentUseCase, maxSize int64) {
+	handler := NewAttachmentHandler(attachments, maxSize)
+	router.POST("/attachments", handler.Upload)
+	router.GET("/attachments/:id", handler.Get)
+	router.DELETE("/attachments/:id", handler.Delete)
+	router.POST("/attachments/:id/restore", handler.Restore)
+	router.GET("/attachments/:id/content", handler.Content)
+	router.GET("/attachments/:id/thumbnail", handler.Thumbnail)
+}
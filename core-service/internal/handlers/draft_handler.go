@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type DraftHandler struct {
+	drafts    *usecase.DraftUseCase
+	schedules *usecase.ScheduleUseCase
+}
+
+func NewDraftHandler(drafts *usecase.DraftUseCase, schedules *usecase.ScheduleUseCase) *DraftHandler {
+	return &DraftHandler{drafts: drafts, schedules: schedules}
+}
+
+func (h *DraftHandler) ListDrafts(c *gin.Context) {
+	drafts, err := h.drafts.ListDrafts(c.Request.Context(), bearerToken(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drafts": drafts})
+}
+
+// GetDraft takes the topic id, or 0 for the draft of a new topic.
+func (h *DraftHandler) GetDraft(c *gin.Context) {
+	topicID, ok := idParam(c, "topic_id")
+	if !ok {
+		return
+	}
+
+	draft, err := h.drafts.GetDraft(c.Request.Context(), bearerToken(c), topicID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"draft": draft})
+}
+
+type SaveDraftRequest struct {
+	CategoryID int64  `json:"category_id"`
+	ReplyToID  int64  `json:"reply_to_id"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	Sequence   int64  `json:"sequence" binding:"required"`
+}
+
+func (h *DraftHandler) SaveDraft(c *gin.Context) {
+	topicID, ok := idParam(c, "topic_id")
+	if !ok {
+		return
+	}
+
+	var req SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft := &entity.Draft{
+		TopicID:    topicID,
+		CategoryID: req.CategoryID,
+		ReplyToID:  req.ReplyToID,
+		Title:      req.Title,
+		Body:       req.Body,
+		Sequence:   req.Sequence,
+	}
+	saved, err := h.drafts.SaveDraft(c.Request.Context(), bearerToken(c), draft)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"draft": saved})
+}
+
+func (h *DraftHandler) DeleteDraft(c *gin.Context) {
+	topicID, ok := idParam(c, "topic_id")
+	if !ok {
+		return
+	}
+
+	if err := h.drafts.DeleteDraft(c.Request.Context(), bearerToken(c), topicID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DraftHandler) ListScheduled(c *gin.Context) {
+	items, err := h.schedules.ListScheduled(c.Request.Context(), bearerToken(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scheduled": items})
+}
+
+// ScheduleRequest opens a new topic when topic_id is left out.
+type ScheduleRequest struct {
+	CategoryID int64     `json:"category_id"`
+	TopicID    int64     `json:"topic_id"`
+	ReplyToID  int64     `json:"reply_to_id"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body" binding:"required"`
+	Tags       []string  `json:"tags"`
+	PublishAt  time.Time `json:"publish_at" binding:"required"`
+}
+
+func (h *DraftHandler) Schedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := &entity.ScheduledPost{
+		CategoryID: req.CategoryID,
+		TopicID:    req.TopicID,
+		ReplyToID:  req.ReplyToID,
+		Title:      req.Title,
+		Body:       req.Body,
+		Tags:       req.Tags,
+		PublishAt:  req.PublishAt,
+	}
+	if err := h.schedules.Schedule(c.Request.Context(), bearerToken(c), item); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"scheduled": item})
+}
+
+type RescheduleRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+func (h *DraftHandler) Reschedule(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.schedules.Reschedule(c.Request.Context(), bearerToken(c), id, req.PublishAt)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scheduled": item})
+}
+
+func (h *DraftHandler) CancelScheduled(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.schedules.Cancel(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func SetupDraftRoutes(router *gin.Engine, drafts *usecase.DraftUseCase, schedules *usecase.ScheduleUseCase) {
+	handler := NewDraftHandler(drafts, schedules)
+	router.GET("/drafts", handler.ListDrafts)
+	router.GET("/drafts/:topic_id", handler.GetDraft)
+	router.PUT("/drafts/:topic_id", handler.SaveDraft)
+	router.DELETE("/drafts/:topic_id", handler.DeleteDraft)
+	router.GET("/scheduled", handler.ListScheduled)
+	router.POST("/scheduled", handler.Schedule)
+	router.PUT("/scheduled/:id", handler.Reschedule)
+	router.DELETE("/scheduled/:id", handler.CancelScheduled)
+}
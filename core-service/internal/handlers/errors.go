@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+	"core-service/internal/storage"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// respondError maps use case and repository errors to HTTP statuses. Errors
+// it does not know, such as database failures, are logged and reported as
+// a plain internal server error, so their details never reach clients.
+func respondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrHeldForReview):
+		status = http.StatusAccepted
+	case errors.Is(err, usecase.ErrRejected):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, storage.ErrBlobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, usecase.ErrInvalidInput),
+		errors.Is(err, pagination.ErrInvalidCursor),
+		errors.Is(err, pagination.ErrUnsupportedSort),
+		errors.Is(err, pagination.ErrInvalidLimit):
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("Error handling %s %s: %v", c.Request.Method, c.FullPath(), err)
+		c.JSON(status, gin.H{"error": "internal server error"})
+		return
+	}
+	var moderationErr *usecase.ModerationError
+	if errors.As(err, &moderationErr) {
+		c.JSON(status, gin.H{
+			"error":     err.Error(),
+			"review_id": moderationErr.Review.ID,
+			"reasons":   moderationErr.Review.Reasons,
+		})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// bearerToken returns the token from a "Bearer <token>" Authorization header.
+func bearerToken(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
+
+func idParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+func pageRequest(c *gin.Context, allowed ...pagination.Sort) (pagination.Request, bool) {
+	req, err := pagination.NewRequest(c.Query("sort"), c.Query("limit"), c.Query("cursor"), allowed...)
+	if err != nil {
+		respondError(c, err)
+		return pagination.Request{}, false
+	}
+	return req, true
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"core-service/internal/repository"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, tc := range []struct {
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{repository.ErrNotFound, http.StatusNotFound, repository.ErrNotFound.Error()},
+		{fmt.Errorf("%w: title too long", usecase.ErrInvalidInput), http.StatusBadRequest, "invalid input: title too long"},
+		{errors.New("sqlite: constraint failed: UNIQUE constraint failed: topics.id"), http.StatusInternalServerError, "internal server error"},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		respondError(c, tc.err)
+
+		var body struct{ Error string }
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tc.wantStatus || body.Error != tc.wantError {
+			t.Errorf("respondError(%v) = %d %q, want %d %q", tc.err, w.Code, body.Error, tc.wantStatus, tc.wantError)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type MessageHandler struct {
+	messages *usecase.MessageUseCase
+}
+
+func NewMessageHandler(messages *usecase.MessageUseCase) *MessageHandler {
+	return &MessageHandler{messages: messages}
+}
+
+type StartConversationRequest struct {
+	Subject      string `json:"subject"`
+	Participants []int  `json:"participants" binding:"required"`
+	Body         string `json:"body" binding:"required"`
+}
+
+func (h *MessageHandler) StartConversation(c *gin.Context) {
+	var req StartConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conv, msg, err := h.messages.StartConversation(c.Request.Context(), bearerToken(c), req.Subject, req.Participants, req.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"conversation": conv, "message": msg})
+}
+
+func (h *MessageHandler) Inbox(c *gin.Context) {
+	req, ok := pageRequest(c, pagination.SortActivity)
+	if !ok {
+		return
+	}
+
+	page, unread, err := h.messages.Inbox(c.Request.Context(), bearerToken(c), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": page.Items, "next_cursor": page.NextCursor, "unread": unread})
+}
+
+func (h *MessageHandler) GetConversation(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	conv, err := h.messages.GetConversation(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"conversation": conv})
+}
+
+func (h *MessageHandler) ListMessages(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c, pagination.SortNewest, pagination.SortOldest)
+	if !ok {
+		return
+	}
+
+	page, err := h.messages.ListMessages(c.Request.Context(), bearerToken(c), id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	msg, err := h.messages.SendMessage(c.Request.Context(), bearerToken(c), id, req.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": msg})
+}
+
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.messages.MarkRead(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MessageHandler) ListBlocked(c *gin.Context) {
+	blocks, err := h.messages.ListBlocked(c.Request.Context(), bearerToken(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocks": blocks})
+}
+
+func (h *MessageHandler) Block(c *gin.Context) {
+	blockedID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if c.Request.Method == http.MethodDelete {
+		err = h.messages.Unblock(c.Request.Context(), bearerToken(c), blockedID)
+	} else {
+		err = h.messages.Block(c.Request.Context(), bearerToken(c), blockedID)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func SetupMessageRoutes(router *gin.Engine, messages *usecase.MessageUseCase) {
+	handler := NewMessageHandler(messages)
+	router.GET("/conversations", handler.Inbox)
+	router.POST("/conversations", handler.StartConversation)
+	router.GET("/conversations/:id", handler.GetConversation)
+	router.GET("/conversations/:id/messages", handler.ListMessages)
+	router.POST("/conversations/:id/messages", handler.SendMessage)
+	router.POST("/conversations/:id/read", handler.MarkRead)
+
+	router.GET("/blocks", handler.ListBlocked)
+	router.PUT("/blocks/:id", handler.Block)
+	router.DELETE("/blocks/:id", handler.Block)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notifications *usecase.NotificationUseCase
+	streams       *usecase.StreamUseCase
+}
+
+func NewNotificationHandler(notifications *usecase.NotificationUseCase, streams *usecase.StreamUseCase) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications, streams: streams}
+}
+
+func (h *NotificationHandler) List(c *gin.Context) {
+	req, ok := pageRequest(c, pagination.SortNewest)
+	if !ok {
+		return
+	}
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	page, unread, err := h.notifications.List(c.Request.Context(), bearerToken(c), unreadOnly, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": page.Items, "next_cursor": page.NextCursor, "unread": unread})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.notifications.MarkRead(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	if err := h.notifications.MarkAllRead(c.Request.Context(), bearerToken(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	sub, err := h.streams.SubscribeUser(c.Request.Context(), streamToken(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	streamEvents(c, sub)
+}
+
+func (h *NotificationHandler) WatchTopic(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	watch := c.Request.Method != http.MethodDelete
+	if err := h.notifications.WatchTopic(c.Request.Context(), bearerToken(c), topicID, watch); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) WatchCategory(c *gin.Context) {
+	categoryID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	watch := c.Request.Method != http.MethodDelete
+	if err := h.notifications.WatchCategory(c.Request.Context(), bearerToken(c), categoryID, watch); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func SetupNotificationRoutes(router *gin.Engine, notifications *usecase.NotificationUseCase, streams *usecase.StreamUseCase) {
+	handler := NewNotificationHandler(notifications, streams)
+	router.GET("/notifications", handler.List)
+	router.GET("/notifications/stream", handler.Stream)
+	router.POST("/notifications/:id/read", handler.MarkRead)
+	router.POST("/notifications/read-all", handler.MarkAllRead)
+
+	router.PUT("/topics/:id/subscription", handler.WatchTopic)
+	router.DELETE("/topics/:id/subscription", handler.WatchTopic)
+	router.PUT("/categories/:id/subscription", handler.WatchCategory)
+	router.DELETE("/categories/:id/subscription", handler.WatchCategory)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type PollHandler struct {
+	polls *usecase.PollUseCase
+}
+
+func NewPollHandler(polls *usecase.PollUseCase) *PollHandler {
+	return &PollHandler{polls: polls}
+}
+
+type CreatePollRequest struct {
+	Question  string             `json:"question" binding:"required"`
+	Options   []string           `json:"options" binding:"required"`
+	Multiple  bool               `json:"multiple"`
+	Anonymous bool               `json:"anonymous"`
+	Results   entity.PollResults `json:"results"`
+	ClosesAt  *time.Time         `json:"closes_at"`
+}
+
+func (h *PollHandler) CreatePoll(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	poll := &entity.Poll{
+		TopicID:   topicID,
+		Question:  req.Question,
+		Multiple:  req.Multiple,
+		Anonymous: req.Anonymous,
+		Results:   req.Results,
+		ClosesAt:  req.ClosesAt,
+	}
+	for _, text := range req.Options {
+		poll.Options = append(poll.Options, entity.PollOption{Text: text})
+	}
+	if err := h.polls.CreatePoll(c.Request.Context(), bearerToken(c), poll); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"poll": poll})
+}
+
+// GetPoll works for guests too; with a token the response includes the
+// caller's own choices.
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	poll, err := h.polls.GetPoll(c.Request.Context(), bearerToken(c), topicID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"poll": poll})
+}
+
+type PollVoteRequest struct {
+	OptionIDs []int64 `json:"option_ids" binding:"required"`
+}
+
+func (h *PollHandler) Vote(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PollVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	poll, err := h.polls.Vote(c.Request.Context(), bearerToken(c), topicID, req.OptionIDs)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"poll": poll})
+}
+
+func (h *PollHandler) WithdrawVote(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	poll, err := h.polls.WithdrawVote(c.Request.Context(), bearerToken(c), topicID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"poll": poll})
+}
+
+func (h *PollHandler) ClosePoll(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	poll, err := h.polls.ClosePoll(c.Request.Context(), bearerToken(c), topicID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"poll": poll})
+}
+
+func SetupPollRoutes(router *gin.Engine, polls *usecase.PollUseCase) {
+	handler := NewPollHandler(polls)
+	router.POST("/topics/:id/poll", handler.CreatePoll)
+	router.GET("/topics/:id/poll", handler.GetPoll)
+	router.PUT("/topics/:id/poll/vote", handler.Vote)
+	router.DELETE("/topics/:id/poll/vote", handler.WithdrawVote)
+	router.POST("/topics/:id/poll/close", handler.ClosePoll)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type PostHandler struct {
+	posts *usecase.PostUseCase
+}
+
+func NewPostHandler(posts *usecase.PostUseCase) *PostHandler {
+	return &PostHandler{posts: posts}
+}
+
+type CreatePostRequest struct {
+	Body          string  `json:"body" binding:"required"`
+	ReplyToID     int64   `json:"reply_to_id"`
+	AttachmentIDs []int64 `json:"attachment_ids"`
+}
+
+func (h *PostHandler) CreatePost(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CreatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	post := &entity.Post{TopicID: topicID, ReplyToID: req.ReplyToID, Body: req.Body, AttachmentIDs: req.AttachmentIDs}
+	if err := h.posts.CreatePost(bearerToken(c), post); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"post": post})
+}
+
+func (h *PostHandler) ListTopicPosts(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c, pagination.SortOldest, pagination.SortNewest, pagination.SortActivity, pagination.SortScore)
+	if !ok {
+		return
+	}
+
+	page, err := h.posts.ListTopicPosts(c.Request.Context(), topicID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type EditPostRequest struct {
+	Body   string `json:"body" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+func (h *PostHandler) EditPost(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req EditPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	post, err := h.posts.EditPost(c.Request.Context(), bearerToken(c), id, req.Body, req.Reason)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"post": post})
+}
+
+func (h *PostHandler) DeletePost(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.posts.DeletePost(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	post, err := h.posts.RestorePost(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"post": post})
+}
+
+type SetWikiRequest struct {
+	Wiki bool `json:"wiki"`
+}
+
+func (h *PostHandler) SetWiki(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SetWikiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	post, err := h.posts.SetWiki(c.Request.Context(), bearerToken(c), id, req.Wiki)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"post": post})
+}
+
+// ListRevisions returns the revision history; ?from=1&to=3 adds a diff between
+// the two revisions, in unified form or with ?mode=words word by word.
+func (h *PostHandler) ListRevisions(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var from, to int
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var errFrom, errTo error
+		from, errFrom = strconv.Atoi(c.Query("from"))
+		to, errTo = strconv.Atoi(c.Query("to"))
+		if errFrom != nil || errTo != nil || from <= 0 || to <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must both be revision numbers"})
+			return
+		}
+	}
+
+	revisions, d, err := h.posts.Revisions(c.Request.Context(), id, from, to, c.Query("mode") == "words")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := gin.H{"revisions": revisions}
+	if d != nil {
+		response["diff"] = d
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func SetupPostRoutes(router *gin.Engine, posts *usecase.PostUseCase) {
+	handler := NewPostHandler(posts)
+	router.GET("/topics/:id/posts", handler.ListTopicPosts)
+	router.POST("/topics/:id/posts", handler.CreatePost)
+	router.PUT("/posts/:id", handler.EditPost)
+	router.DELETE("/posts/:id", handler.DeletePost)
+	router.POST("/posts/:id/restore", handler.RestorePost)
+	router.PUT("/posts/:id/wiki", handler.SetWiki)
+	router.GET("/posts/:id/revisions", handler.ListRevisions)
+}
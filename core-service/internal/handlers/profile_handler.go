@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	profiles *usecase.ProfileUseCase
+}
+
+func NewProfileHandler(profiles *usecase.ProfileUseCase) *ProfileHandler {
+	return &ProfileHandler{profiles: profiles}
+}
+
+func (h *ProfileHandler) GetOwnProfile(c *gin.Context) {
+	profile, err := h.profiles.GetOwnProfile(c.Request.Context(), bearerToken(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+// UpdateProfileRequest replaces all editable fields; privacy settings are
+// left as they are when omitted.
+type UpdateProfileRequest struct {
+	DisplayName string                 `json:"display_name"`
+	AvatarID    int64                  `json:"avatar_id"`
+	Bio         string                 `json:"bio"`
+	Signature   string                 `json:"signature"`
+	Location    string                 `json:"location"`
+	Privacy     *entity.ProfilePrivacy `json:"privacy"`
+}
+
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	update := &entity.Profile{
+		DisplayName: req.DisplayName,
+		AvatarID:    req.AvatarID,
+		Bio:         req.Bio,
+		Signature:   req.Signature,
+		Location:    req.Location,
+		Privacy:     req.Privacy,
+	}
+	profile, err := h.profiles.UpdateProfile(c.Request.Context(), bearerToken(c), update)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+func (h *ProfileHandler) GetProfilePage(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.profiles.GetProfilePage(c.Request.Context(), bearerToken(c), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *ProfileHandler) ListTopics(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c, pagination.SortNewest, pagination.SortActivity, pagination.SortScore)
+	if !ok {
+		return
+	}
+
+	page, err := h.profiles.ListTopics(c.Request.Context(), bearerToken(c), userID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *ProfileHandler) ListActivity(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c, pagination.SortNewest, pagination.SortScore)
+	if !ok {
+		return
+	}
+
+	page, err := h.profiles.ListPosts(c.Request.Context(), bearerToken(c), userID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func userIDParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func SetupProfileRoutes(router *gin.Engine, profiles *usecase.ProfileUseCase) {
+	handler := NewProfileHandler(profiles)
+	router.GET("/profile", handler.GetOwnProfile)
+	router.PUT("/profile", handler.UpdateProfile)
+	router.GET("/users/:id/profile", handler.GetProfilePage)
+	router.GET("/users/:id/topics", handler.ListTopics)
+	router.GET("/users/:id/activity", handler.ListActivity)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ReadHandler struct {
+	reads *usecase.ReadUseCase
+}
+
+func NewReadHandler(reads *usecase.ReadUseCase) *ReadHandler {
+	return &ReadHandler{reads: reads}
+}
+
+type MarkReadRequest struct {
+	PostID int64 `json:"post_id" binding:"required"`
+}
+
+// MarkRead is called by clients as posts scroll into view. Markers are
+// written in batches, hence 202.
+func (h *ReadHandler) MarkRead(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.reads.MarkRead(c.Request.Context(), bearerToken(c), topicID, req.PostID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (h *ReadHandler) MarkCategoryRead(c *gin.Context) {
+	categoryID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.reads.MarkCategoryRead(c.Request.Context(), bearerToken(c), categoryID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ReadHandler) ListUnread(c *gin.Context) {
+	req, ok := pageRequest(c, pagination.SortActivity, pagination.SortNewest)
+	if !ok {
+		return
+	}
+
+	page, err := h.reads.ListUnread(c.Request.Context(), bearerToken(c), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func SetupReadRoutes(router *gin.Engine, reads *usecase.ReadUseCase) {
+	handler := NewReadHandler(reads)
+	router.GET("/unread", handler.ListUnread)
+	router.POST("/topics/:id/read", handler.MarkRead)
+	router.POST("/categories/:id/read", handler.MarkCategoryRead)
+}
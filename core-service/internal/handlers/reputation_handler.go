@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ReputationHandler struct {
+	reputation *usecase.ReputationUseCase
+	votes      *usecase.VoteUseCase
+	flags      *usecase.FlagUseCase
+}
+
+func NewReputationHandler(reputation *usecase.ReputationUseCase, votes *usecase.VoteUseCase, flags *usecase.FlagUseCase) *ReputationHandler {
+	return &ReputationHandler{reputation: reputation, votes: votes, flags: flags}
+}
+
+// GetReputation is public; the auth-service reads the trust level from here
+// to put it into token claims.
+func (h *ReputationHandler) GetReputation(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	rep, err := h.reputation.Get(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	level, err := h.reputation.TrustLevel(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	rep.TrustLevel = level
+
+	c.JSON(http.StatusOK, gin.H{"reputation": rep, "trust_level_name": level.String()})
+}
+
+func (h *ReputationHandler) Recompute(c *gin.Context) {
+	count, err := h.reputation.Recompute(c.Request.Context(), bearerToken(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": count})
+}
+
+type VoteRequest struct {
+	// 1 for an upvote, -1 for a downvote, 0 to take the vote back.
+	Value int `json:"value"`
+}
+
+func (h *ReputationHandler) Vote(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	post, err := h.votes.Vote(c.Request.Context(), bearerToken(c), id, req.Value)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"post": post})
+}
+
+type FlagRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (h *ReputationHandler) FlagPost(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req FlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag, err := h.flags.FlagPost(c.Request.Context(), bearerToken(c), id, req.Reason)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"flag": flag})
+}
+
+func (h *ReputationHandler) ListFlags(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	flags, err := h.flags.ListFlags(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+func SetupReputationRoutes(router *gin.Engine, reputation *usecase.ReputationUseCase, votes *usecase.VoteUseCase, flags *usecase.FlagUseCase) {
+	handler := NewReputationHandler(reputation, votes, flags)
+	router.GET("/users/:id/reputation", handler.GetReputation)
+	router.POST("/reputation/recompute", handler.Recompute)
+	router.PUT("/posts/:id/vote", handler.Vote)
+	router.POST("/posts/:id/flags", handler.FlagPost)
+	router.GET("/posts/:id/flags", handler.ListFlags)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewHandler struct {
+	reviews *usecase.ReviewUseCase
+}
+
+func NewReviewHandler(reviews *usecase.ReviewUseCase) *ReviewHandler {
+	return &ReviewHandler{reviews: reviews}
+}
+
+// ListReviews returns the moderation queue, or with ?status= the reviews
+// that ended up approved, spam or rejected.
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	req, ok := pageRequest(c, pagination.SortNewest)
+	if !ok {
+		return
+	}
+
+	status := entity.ReviewStatus(c.Query("status"))
+	page, err := h.reviews.ListReviews(c.Request.Context(), bearerToken(c), status, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *ReviewHandler) Approve(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	post, err := h.reviews.Approve(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"post": post})
+}
+
+func (h *ReviewHandler) MarkSpam(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.reviews.MarkSpam(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ReviewHandler) MarkPostSpam(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.reviews.MarkPostSpam(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func SetupReviewRoutes(router *gin.Engine, reviews *usecase.ReviewUseCase) {
+	handler := NewReviewHandler(reviews)
+	router.GET("/reviews", handler.ListReviews)
+	router.POST("/reviews/:id/approve", handler.Approve)
+	router.POST("/reviews/:id/spam", handler.MarkSpam)
+	router.POST("/posts/:id/spam", handler.MarkPostSpam)
+}
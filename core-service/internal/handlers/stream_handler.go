@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"core-service/internal/realtime"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	streams *usecase.StreamUseCase
+}
+
+func NewStreamHandler(streams *usecase.StreamUseCase) *StreamHandler {
+	return &StreamHandler{streams: streams}
+}
+
+// streamToken accepts the access token as a query parameter as well, since
+// browsers' EventSource cannot set the Authorization header.
+func streamToken(c *gin.Context) string {
+	if token := c.Query("access_token"); token != "" {
+		return token
+	}
+	return bearerToken(c)
+}
+
+func (h *StreamHandler) TopicStream(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.streams.SubscribeTopic(c.Request.Context(), streamToken(c), topicID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	streamEvents(c, sub)
+}
+
+func (h *StreamHandler) CategoryStream(c *gin.Context) {
+	categoryID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.streams.SubscribeCategory(c.Request.Context(), streamToken(c), categoryID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	streamEvents(c, sub)
+}
+
+func (h *StreamHandler) Typing(c *gin.Context) {
+	topicID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.streams.Typing(c.Request.Context(), bearerToken(c), topicID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// streamEvents writes subscription events as Server-Sent Events until the
+// client goes away or the hub drops the subscription for falling behind.
+func streamEvents(c *gin.Context, sub *realtime.Subscription) {
+	defer sub.Close()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					c.SSEvent("lagged", gin.H{"reconnect": true})
+				}
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"at": time.Now().UTC()})
+			return true
+		}
+	})
+}
+
+func SetupStreamRoutes(router *gin.Engine, streams *usecase.StreamUseCase) {
+	handler := NewStreamHandler(streams)
+	router.GET("/topics/:id/stream", handler.TopicStream)
+	router.GET("/categories/:id/stream", handler.CategoryStream)
+	router.POST("/topics/:id/typing", handler.Typing)
+}
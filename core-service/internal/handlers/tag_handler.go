@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"core-service/internal/pagination"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tags *usecase.TagUseCase
+}
+
+func NewTagHandler(tags *usecase.TagUseCase) *TagHandler {
+	return &TagHandler{tags: tags}
+}
+
+// Cloud returns the most used tags with their topic counts.
+func (h *TagHandler) Cloud(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	tags, err := h.tags.Cloud(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// Autocomplete suggests tags for ?q=<prefix>.
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	tags, err := h.tags.Autocomplete(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (h *TagHandler) GetTag(c *gin.Context) {
+	tag, err := h.tags.GetTag(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+func (h *TagHandler) ListTagTopics(c *gin.Context) {
+	req, ok := pageRequest(c, pagination.SortActivity, pagination.SortNewest, pagination.SortScore)
+	if !ok {
+		return
+	}
+
+	page, err := h.tags.ListTagTopics(c.Request.Context(), bearerToken(c), c.Param("name"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type SetTopicTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (h *TagHandler) SetTopicTags(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SetTopicTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.tags.SetTopicTags(c.Request.Context(), bearerToken(c), id, req.Tags)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+type AddSynonymRequest struct {
+	Synonym string `json:"synonym" binding:"required"`
+}
+
+func (h *TagHandler) AddSynonym(c *gin.Context) {
+	var req AddSynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tags.AddSynonym(c.Request.Context(), bearerToken(c), c.Param("name"), req.Synonym)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+func (h *TagHandler) RemoveSynonym(c *gin.Context) {
+	if err := h.tags.RemoveSynonym(c.Request.Context(), bearerToken(c), c.Param("synonym")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type MergeTagsRequest struct {
+	Into string `json:"into" binding:"required"`
+}
+
+// MergeTags folds the tag in the path into the one named in the body.
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	var req MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tags.MergeTags(c.Request.Context(), bearerToken(c), c.Param("name"), req.Into)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tag": tag})
+}
+
+func SetupTagRoutes(router *gin.Engine, tags *usecase.TagUseCase) {
+	handler := NewTagHandler(tags)
+	router.GET("/tags", handler.Cloud)
+	router.GET("/tags/autocomplete", handler.Autocomplete)
+	router.GET("/tags/:name", handler.GetTag)
+	router.GET("/tags/:name/topics", handler.ListTagTopics)
+	router.POST("/tags/:name/synonyms", handler.AddSynonym)
+	router.DELETE("/tags/:name/synonyms/:synonym", handler.RemoveSynonym)
+	router.POST("/tags/:name/merge", handler.MergeTags)
+	router.PUT("/topics/:id/tags", handler.SetTopicTags)
+}
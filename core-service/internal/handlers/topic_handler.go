@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+	"core-service/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type TopicHandler struct {
+	topics *usecase.TopicUseCase
+}
+
+func NewTopicHandler(topics *usecase.TopicUseCase) *TopicHandler {
+	return &TopicHandler{topics: topics}
+}
+
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	QAMode      bool   `json:"qa_mode"`
+}
+
+func (h *TopicHandler) CreateCategory(c *gin.Context) {
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := &entity.Category{Name: req.Name, Description: req.Description, QAMode: req.QAMode}
+	if err := h.topics.CreateCategory(bearerToken(c), category); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"category": category})
+}
+
+func (h *TopicHandler) ListCategories(c *gin.Context) {
+	categories, err := h.topics.ListCategories(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+type CreateTopicRequest struct {
+	Title string   `json:"title" binding:"required"`
+	Body  string   `json:"body" binding:"required"`
+	Tags  []string `json:"tags"`
+}
+
+func (h *TopicHandler) CreateTopic(c *gin.Context) {
+	categoryID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CreateTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic := &entity.Topic{CategoryID: categoryID, Title: req.Title}
+	post, err := h.topics.CreateTopic(bearerToken(c), topic, req.Body, req.Tags)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"topic": topic, "post": post})
+}
+
+func (h *TopicHandler) ListCategoryTopics(c *gin.Context) {
+	categoryID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c, pagination.SortActivity, pagination.SortNewest, pagination.SortScore)
+	if !ok {
+		return
+	}
+
+	filter := repository.TopicFilter(c.Query("status"))
+	page, err := h.topics.ListCategoryTopics(c.Request.Context(), bearerToken(c), categoryID, filter, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *TopicHandler) ListUnanswered(c *gin.Context) {
+	req, ok := pageRequest(c, pagination.SortNewest, pagination.SortActivity, pagination.SortScore)
+	if !ok {
+		return
+	}
+
+	page, err := h.topics.ListUnanswered(c.Request.Context(), bearerToken(c), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+type SetQAModeRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (h *TopicHandler) SetQAMode(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SetQAModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.topics.SetQAMode(c.Request.Context(), bearerToken(c), id, req.Enabled)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"category": category})
+}
+
+type AcceptAnswerRequest struct {
+	PostID int64 `json:"post_id" binding:"required"`
+}
+
+func (h *TopicHandler) AcceptAnswer(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AcceptAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic, err := h.topics.AcceptAnswer(c.Request.Context(), bearerToken(c), id, req.PostID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+func (h *TopicHandler) ClearAcceptedAnswer(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	topic, err := h.topics.ClearAcceptedAnswer(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+func (h *TopicHandler) GetTopic(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	topic, err := h.topics.GetTopic(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+func (h *TopicHandler) DeleteTopic(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.topics.DeleteTopic(c.Request.Context(), bearerToken(c), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TopicHandler) RestoreTopic(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	topic, err := h.topics.RestoreTopic(c.Request.Context(), bearerToken(c), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+func SetupTopicRoutes(router *gin.Engine, topics *usecase.TopicUseCase) {
+	handler := NewTopicHandler(topics)
+	router.GET("/categories", handler.ListCategories)
+	router.POST("/categories", handler.CreateCategory)
+	router.PUT("/categories/:id/qa", handler.SetQAMode)
+	router.GET("/categories/:id/topics", handler.ListCategoryTopics)
+	router.POST("/categories/:id/topics", handler.CreateTopic)
+	router.GET("/topics/unanswered", handler.ListUnanswered)
+	router.GET("/topics/:id", handler.GetTopic)
+	router.DELETE("/topics/:id", handler.DeleteTopic)
+	router.POST("/topics/:id/restore", handler.RestoreTopic)
+	router.PUT("/topics/:id/accepted-answer", handler.AcceptAnswer)
+	router.DELETE("/topics/:id/accepted-answer", handler.ClearAcceptedAnswer)
+}
@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/realtime"
+)
+
+// Channel delivers notifications to a user through some medium.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, userID int, batch []entity.Notification) error
+}
+
+// InAppChannel pushes notifications to the user's open event stream.
+type InAppChannel struct {
+	hub *realtime.Hub
+}
+
+func NewInAppChannel(hub *realtime.Hub) *InAppChannel {
+	return &InAppChannel{hub: hub}
+}
+
+func (c *InAppChannel) Name() string { return "in-app" }
+
+func (c *InAppChannel) Deliver(_ context.Context, userID int, batch []entity.Notification) error {
+	for _, n := range batch {
+		c.hub.Publish(realtime.Event{
+			Type:        realtime.EventNotification,
+			TopicID:     n.TopicID,
+			UserID:      n.ActorID,
+			RecipientID: userID,
+			Payload:     n,
+		})
+	}
+	return nil
+}
+
+// WebhookChannel posts each user's batch as JSON to a fixed URL.
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *WebhookChannel) Name() string { return "webhook" }
+
+func (c *WebhookChannel) Deliver(ctx context.Context, userID int, batch []entity.Notification) error {
+	body, err := json.Marshal(map[string]any{"user_id": userID, "notifications": batch})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// LogEmailChannel is a development stand-in for email: digests are written
+// to a file, or to the log when no path is set.
+type LogEmailChannel struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLogEmailChannel(path string) *LogEmailChannel {
+	return &LogEmailChannel{path: path}
+}
+
+func (c *LogEmailChannel) Name() string { return "email" }
+
+func (c *LogEmailChannel) Deliver(_ context.Context, userID int, batch []entity.Notification) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "To: user %d\nSubject: %d new notifications\n\n", userID, len(batch))
+	for _, n := range batch {
+		fmt.Fprintf(&buf, "- %s from user %d in topic %d (post %d)\n", n.Kind, n.ActorID, n.TopicID, n.PostID)
+	}
+	buf.WriteString("\n")
+
+	if c.path == "" {
+		log.Print(buf.String())
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(buf.Bytes())
+	return err
+}
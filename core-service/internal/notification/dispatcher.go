@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+)
+
+const digestBatchSize = 500
+
+// Dispatcher periodically collects undelivered notifications and sends each
+// user a single digest per channel.
+type Dispatcher struct {
+	repo     repository.NotificationRepository
+	channels []Channel
+	interval time.Duration
+}
+
+func NewDispatcher(repo repository.NotificationRepository, interval time.Duration, channels ...Channel) *Dispatcher {
+	return &Dispatcher{repo: repo, channels: channels, interval: interval}
+}
+
+// Run flushes digests every interval until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Flush(ctx); err != nil {
+				log.Printf("Error delivering notification digests: %v", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	for {
+		pending, err := d.repo.ListUndelivered(ctx, digestBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		byUser := make(map[int][]entity.Notification)
+		for _, n := range pending {
+			byUser[n.UserID] = append(byUser[n.UserID], n)
+		}
+
+		// A failing channel is logged but does not hold back the others;
+		// digests are best effort and are not retried.
+		ids := make([]int64, 0, len(pending))
+		for userID, batch := range byUser {
+			for _, ch := range d.channels {
+				if err := ch.Deliver(ctx, userID, batch); err != nil {
+					log.Printf("Error delivering %s digest to user %d: %v", ch.Name(), userID, err)
+				}
+			}
+			for _, n := range batch {
+				ids = append(ids, n.ID)
+			}
+		}
+
+		if err := d.repo.MarkDelivered(ctx, ids); err != nil {
+			return err
+		}
+		if len(pending) < digestBatchSize {
+			return nil
+		}
+	}
+}
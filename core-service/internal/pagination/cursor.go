@@ -0,0 +1,126 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+type Sort string
+
+const (
+	SortNewest   Sort = "newest"
+	SortOldest   Sort = "oldest"
+	SortActivity Sort = "activity"
+	SortScore    Sort = "score"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrUnsupportedSort = errors.New("unsupported sort")
+	ErrInvalidLimit    = errors.New("invalid limit")
+)
+
+// Cursor points at the last row of a page. Key holds the value of the sort
+// column (unix nanoseconds for time columns) and ID breaks ties, so the pair
+// is unique and rows inserted while a client is paging never shift its
+// position.
+type Cursor struct {
+	Sort Sort  `json:"s"`
+	Key  int64 `json:"k"`
+	ID   int64 `json:"i"`
+}
+
+// Encode turns the cursor into an opaque token for clients.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort == "" || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+type Request struct {
+	Sort  Sort
+	Limit int
+	After *Cursor
+}
+
+// NewRequest builds a page request from raw query parameters. The first
+// allowed sort is used when none is given, and a cursor is only accepted for
+// the sort it was issued for.
+func NewRequest(sort, limit, cursor string, allowed ...Sort) (Request, error) {
+	req := Request{Sort: allowed[0], Limit: DefaultLimit}
+
+	if sort != "" {
+		req.Sort = ""
+		for _, s := range allowed {
+			if Sort(sort) == s {
+				req.Sort = s
+				break
+			}
+		}
+		if req.Sort == "" {
+			return Request{}, ErrUnsupportedSort
+		}
+	}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return Request{}, ErrInvalidLimit
+		}
+		if n > MaxLimit {
+			n = MaxLimit
+		}
+		req.Limit = n
+	}
+
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return Request{}, err
+		}
+		if c.Sort != req.Sort {
+			return Request{}, ErrInvalidCursor
+		}
+		req.After = c
+	}
+
+	return req, nil
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPage trims the extra row a repository fetched past the limit and, if it
+// was present, builds the cursor for the next page from the last kept item.
+func NewPage[T any](items []T, req Request, cursorOf func(T) Cursor) Page[T] {
+	page := Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) > req.Limit {
+		page.Items = items[:req.Limit]
+		page.NextCursor = cursorOf(page.Items[req.Limit-1]).Encode()
+	}
+	return page
+}
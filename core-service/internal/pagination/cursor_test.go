@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Sort: SortScore, Key: -3, ID: 42}
+	got, err := DecodeCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if *got != want {
+		t.Errorf("DecodeCursor = %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+	valid := Cursor{Sort: SortNewest, Key: 7, ID: 7}.Encode()
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	for name, token := range map[string]string{
+		"not base64":     "%%%",
+		"padded base64":  base64.URLEncoding.EncodeToString([]byte(`{"s":"newest","k":7,"i":7}`)),
+		"truncated":      valid[:len(valid)-3],
+		"flipped byte":   valid[:4] + "_" + valid[5:],
+		"not json":       raw("newest:7:7"),
+		"wrong types":    raw(`{"s":"newest","k":"7","i":7}`),
+		"no sort":        raw(`{"k":7,"i":7}`),
+		"no id":          raw(`{"s":"newest","k":7}`),
+		"negative id":    raw(`{"s":"newest","k":7,"i":-1}`),
+		"empty json":     raw(`{}`),
+		"json array":     raw(`["newest",7,7]`),
+		"trailing bytes": valid + "AAAA",
+	} {
+		if c, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: DecodeCursor(%q) = %+v, %v, want ErrInvalidCursor", name, token, c, err)
+		}
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	newest := Cursor{Sort: SortNewest, Key: 9, ID: 9}.Encode()
+
+	req, err := NewRequest("", "", "", SortNewest, SortScore)
+	if err != nil || req.Sort != SortNewest || req.Limit != DefaultLimit || req.After != nil {
+		t.Errorf("defaults: got %+v, %v", req, err)
+	}
+
+	req, err = NewRequest("newest", "500", newest, SortNewest, SortScore)
+	if err != nil || req.Limit != MaxLimit || req.After == nil || req.After.ID != 9 {
+		t.Errorf("with cursor: got %+v, %v", req, err)
+	}
+
+	for _, tc := range []struct {
+		name, sort, limit, cursor string
+		want                      error
+	}{
+		{"unknown sort", "oldest", "", "", ErrUnsupportedSort},
+		{"zero limit", "", "0", "", ErrInvalidLimit},
+		{"bad limit", "", "ten", "", ErrInvalidLimit},
+		{"malformed cursor", "", "", "%%%", ErrInvalidCursor},
+		// A cursor's key means something else under another sort.
+		{"cursor of another sort", "score", "", newest, ErrInvalidCursor},
+		{"cursor sort changed", "score", "", Cursor{Sort: "oldest", Key: 9, ID: 9}.Encode(), ErrInvalidCursor},
+	} {
+		if _, err := NewRequest(tc.sort, tc.limit, tc.cursor, SortNewest, SortScore); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	req := Request{Sort: SortNewest, Limit: 2}
+	cursorOf := func(id int64) Cursor { return Cursor{Sort: SortNewest, Key: id, ID: id} }
+
+	page := NewPage([]int64{5, 4, 3}, req, cursorOf)
+	if len(page.Items) != 2 || page.NextCursor != cursorOf(4).Encode() {
+		t.Errorf("full page: got %+v", page)
+	}
+
+	page = NewPage([]int64{5, 4}, req, cursorOf)
+	if len(page.Items) != 2 || page.NextCursor != "" {
+		t.Errorf("last page: got %+v", page)
+	}
+
+	page = NewPage[int64](nil, req, cursorOf)
+	if page.Items == nil || page.NextCursor != "" {
+		t.Errorf("empty page: got %+v", page)
+	}
+}
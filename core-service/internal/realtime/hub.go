@@ -0,0 +1,143 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventPostCreated  EventType = "post.created"
+	EventPostEdited   EventType = "post.edited"
+	EventPostDeleted  EventType = "post.deleted"
+	EventPostRestored EventType = "post.restored"
+	EventReaction     EventType = "post.reaction"
+	EventTyping       EventType = "typing"
+	EventNotification EventType = "notification"
+	EventMessage      EventType = "message.created"
+	EventAnswer       EventType = "topic.answer"
+	EventPollUpdated  EventType = "poll.updated"
+)
+
+type Event struct {
+	Type       EventType `json:"type"`
+	TopicID    int64     `json:"topic_id"`
+	CategoryID int64     `json:"category_id"`
+	UserID     int       `json:"user_id"`
+	// RecipientID addresses the event to a single user's stream instead of
+	// a topic or category.
+	RecipientID int       `json:"recipient_id,omitempty"`
+	Payload     any       `json:"payload,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Subscription receives the events of one topic or one category. Events are
+// buffered; a subscriber that falls a full buffer behind is dropped rather
+// than allowed to block publishers, and Lagged reports why it was closed so
+// the client can reconnect and refetch.
+type Subscription struct {
+	hub        *Hub
+	userID     int
+	topicID    int64
+	categoryID int64
+	events     chan Event
+	lagged     bool
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Lagged() bool {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+	return s.lagged
+}
+
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s, false)
+}
+
+func (s *Subscription) matches(e Event) bool {
+	if s.userID != 0 || e.RecipientID != 0 {
+		return e.RecipientID == s.userID
+	}
+	if s.topicID != 0 {
+		return e.TopicID == s.topicID
+	}
+	return e.CategoryID == s.categoryID
+}
+
+// Hub is an in-process publish/subscribe broker for forum events.
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	buffer int
+}
+
+func NewHub(buffer int) *Hub {
+	return &Hub{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+func (h *Hub) SubscribeTopic(topicID int64) *Subscription {
+	return h.subscribe(&Subscription{topicID: topicID})
+}
+
+func (h *Hub) SubscribeCategory(categoryID int64) *Subscription {
+	return h.subscribe(&Subscription{categoryID: categoryID})
+}
+
+func (h *Hub) SubscribeUser(userID int) *Subscription {
+	return h.subscribe(&Subscription{userID: userID})
+}
+
+func (h *Hub) subscribe(s *Subscription) *Subscription {
+	s.hub = h
+	s.events = make(chan Event, h.buffer)
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Publish never blocks: subscribers whose buffer is full are disconnected.
+func (h *Hub) Publish(e Event) {
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+
+	var slow []*Subscription
+	h.mu.RLock()
+	for s := range h.subs {
+		if !s.matches(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			slow = append(slow, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range slow {
+		h.unsubscribe(s, true)
+	}
+}
+
+func (h *Hub) unsubscribe(s *Subscription, lagged bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	s.lagged = lagged
+	close(s.events)
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, a *entity.Attachment) error
+	GetByID(ctx context.Context, id int64) (*entity.Attachment, error)
+	// Linkable reports whether all the attachments were uploaded by the user
+	// and are not yet attached to a post.
+	Linkable(ctx context.Context, uploaderID int, ids []int64) (bool, error)
+	ListByPosts(ctx context.Context, postIDs []int64) (map[int64][]entity.Attachment, error)
+	SoftDelete(ctx context.Context, id int64, by int) error
+	Restore(ctx context.Context, id int64) error
+	// ListPurgeable returns attachments deleted before the given time, and
+	// uploads that were never linked to a post by then unless they are in
+	// use as an avatar.
+	ListPurgeable(ctx context.Context, before time.Time, limit int) ([]entity.Attachment, error)
+	Purge(ctx context.Context, ids []int64) error
+}
+
+const attachmentColumns = "id, uploader_id, post_id, filename, content_type, size, sha256, storage_key, thumbnail_key, created_at, deleted_at, deleted_by"
+
+type SQLiteAttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &SQLiteAttachmentRepository{db: db}
+}
+
+func (r *SQLiteAttachmentRepository) Create(ctx context.Context, a *entity.Attachment) error {
+	a.CreatedAt = time.Now().UTC()
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO attachments (uploader_id, filename, content_type, size, sha256, storage_key, thumbnail_key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.UploaderID, a.Filename, a.ContentType, a.Size, a.SHA256, a.StorageKey, a.ThumbnailKey, a.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	a.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteAttachmentRepository) GetByID(ctx context.Context, id int64) (*entity.Attachment, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+attachmentColumns+" FROM attachments WHERE id = ?", id)
+	a, err := scanAttachment(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return a, nil
+}
+
+func (r *SQLiteAttachmentRepository) Linkable(ctx context.Context, uploaderID int, ids []int64) (bool, error) {
+	if len(ids) == 0 {
+		return true, nil
+	}
+
+	args := append([]any{uploaderID}, int64Args(ids)...)
+
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM attachments WHERE uploader_id = ? AND post_id = 0 AND id IN ("+placeholders(len(ids))+")",
+		args...).Scan(&count)
+	return count == len(ids), err
+}
+
+func (r *SQLiteAttachmentRepository) ListByPosts(ctx context.Context, postIDs []int64) (map[int64][]entity.Attachment, error) {
+	result := make(map[int64][]entity.Attachment)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+attachmentColumns+" FROM attachments WHERE deleted_at IS NULL AND post_id IN ("+placeholders(len(postIDs))+") ORDER BY id",
+		int64Args(postIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[a.PostID] = append(result[a.PostID], *a)
+	}
+	return result, rows.Err()
+}
+
+func (r *SQLiteAttachmentRepository) SoftDelete(ctx context.Context, id int64, by int) error {
+	return softDelete(ctx, r.db, "attachments", id, by)
+}
+
+func (r *SQLiteAttachmentRepository) Restore(ctx context.Context, id int64) error {
+	return restore(ctx, r.db, "attachments", id)
+}
+
+func (r *SQLiteAttachmentRepository) ListPurgeable(ctx context.Context, before time.Time, limit int) ([]entity.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+attachmentColumns+` FROM attachments
+		WHERE (deleted_at IS NOT NULL AND deleted_at < ?)
+			OR (post_id = 0 AND created_at < ? AND id NOT IN (SELECT avatar_id FROM profiles))
+		ORDER BY id LIMIT ?`,
+		before.UTC(), before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []entity.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *SQLiteAttachmentRepository) Purge(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM attachments WHERE id IN ("+placeholders(len(ids))+")", int64Args(ids)...)
+	return err
+}
+
+func scanAttachment(row rowScanner) (*entity.Attachment, error) {
+	var a entity.Attachment
+	var deletedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.UploaderID, &a.PostID, &a.Filename, &a.ContentType, &a.Size, &a.SHA256,
+		&a.StorageKey, &a.ThumbnailKey, &a.CreatedAt, &deletedAt, &a.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	a.DeletedAt = nullTime(deletedAt)
+	a.HasThumbnail = a.ThumbnailKey != ""
+	return &a, nil
+}
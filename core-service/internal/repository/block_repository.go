@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type BlockRepository interface {
+	Block(ctx context.Context, block *entity.Block) error
+	Unblock(ctx context.Context, userID, blockedID int) error
+	ListBlocked(ctx context.Context, userID int) ([]entity.Block, error)
+	// AnyBlocks reports whether any of the users has blocked blockedID.
+	AnyBlocks(ctx context.Context, userIDs []int, blockedID int) (bool, error)
+}
+
+type SQLiteBlockRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteBlockRepository(db *sql.DB) BlockRepository {
+	return &SQLiteBlockRepository{db: db}
+}
+
+func (r *SQLiteBlockRepository) Block(ctx context.Context, block *entity.Block) error {
+	block.CreatedAt = time.Now().UTC()
+	_, err := r.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO user_blocks (user_id, blocked_id, created_at) VALUES (?, ?, ?)",
+		block.UserID, block.BlockedID, block.CreatedAt)
+	return err
+}
+
+func (r *SQLiteBlockRepository) Unblock(ctx context.Context, userID, blockedID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM user_blocks WHERE user_id = ? AND blocked_id = ?", userID, blockedID)
+	return err
+}
+
+func (r *SQLiteBlockRepository) ListBlocked(ctx context.Context, userID int) ([]entity.Block, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT user_id, blocked_id, created_at FROM user_blocks WHERE user_id = ? ORDER BY created_at DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []entity.Block
+	for rows.Next() {
+		var b entity.Block
+		if err := rows.Scan(&b.UserID, &b.BlockedID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
+func (r *SQLiteBlockRepository) AnyBlocks(ctx context.Context, userIDs []int, blockedID int) (bool, error) {
+	if len(userIDs) == 0 {
+		return false, nil
+	}
+
+	args := make([]any, 0, len(userIDs)+1)
+	args = append(args, blockedID)
+	for _, id := range userIDs {
+		args = append(args, id)
+	}
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocked_id = ? AND user_id IN ("+placeholders(len(userIDs))+"))",
+		args...).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *entity.Category) error
+	GetByID(ctx context.Context, id int64) (*entity.Category, error)
+	List(ctx context.Context) ([]entity.Category, error)
+	SetQAMode(ctx context.Context, id int64, enabled bool) error
+}
+
+type SQLiteCategoryRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCategoryRepository(db *sql.DB) CategoryRepository {
+	return &SQLiteCategoryRepository{db: db}
+}
+
+func (r *SQLiteCategoryRepository) Create(ctx context.Context, category *entity.Category) error {
+	category.CreatedAt = time.Now().UTC()
+
+	res, err := r.db.ExecContext(ctx,
+		"INSERT INTO categories (name, description, qa_mode, created_at) VALUES (?, ?, ?, ?)",
+		category.Name, category.Description, category.QAMode, category.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	category.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteCategoryRepository) GetByID(ctx context.Context, id int64) (*entity.Category, error) {
+	var c entity.Category
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, name, description, qa_mode, created_at FROM categories WHERE id = ?", id).
+		Scan(&c.ID, &c.Name, &c.Description, &c.QAMode, &c.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &c, nil
+}
+
+func (r *SQLiteCategoryRepository) List(ctx context.Context) ([]entity.Category, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, description, qa_mode, created_at FROM categories ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []entity.Category
+	for rows.Next() {
+		var c entity.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.QAMode, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *SQLiteCategoryRepository) SetQAMode(ctx context.Context, id int64, enabled bool) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE categories SET qa_mode = ? WHERE id = ?", enabled, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type DraftRepository interface {
+	// Save stores the draft unless a save with the same or a higher sequence
+	// got there first, and returns the draft as stored.
+	Save(ctx context.Context, draft *entity.Draft) (*entity.Draft, error)
+	Get(ctx context.Context, userID int, topicID int64) (*entity.Draft, error)
+	ListByUser(ctx context.Context, userID int) ([]entity.Draft, error)
+	Delete(ctx context.Context, userID int, topicID int64) error
+	// DeleteOlderThan drops drafts nobody touched since before.
+	DeleteOlderThan(ctx context.Context, before time.Time) error
+}
+
+const draftColumns = "user_id, topic_id, category_id, reply_to_id, title, body, sequence, updated_at"
+
+type SQLiteDraftRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteDraftRepository(db *sql.DB) DraftRepository {
+	return &SQLiteDraftRepository{db: db}
+}
+
+func (r *SQLiteDraftRepository) Save(ctx context.Context, draft *entity.Draft) (*entity.Draft, error) {
+	draft.UpdatedAt = time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO drafts (`+draftColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, topic_id) DO UPDATE SET
+			category_id = excluded.category_id, reply_to_id = excluded.reply_to_id, title = excluded.title,
+			body = excluded.body, sequence = excluded.sequence, updated_at = excluded.updated_at
+		WHERE excluded.sequence > drafts.sequence`,
+		draft.UserID, draft.TopicID, draft.CategoryID, draft.ReplyToID, draft.Title, draft.Body,
+		draft.Sequence, draft.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, draft.UserID, draft.TopicID)
+}
+
+func (r *SQLiteDraftRepository) Get(ctx context.Context, userID int, topicID int64) (*entity.Draft, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+draftColumns+" FROM drafts WHERE user_id = ? AND topic_id = ?", userID, topicID)
+	draft, err := scanDraft(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return draft, nil
+}
+
+func (r *SQLiteDraftRepository) ListByUser(ctx context.Context, userID int) ([]entity.Draft, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+draftColumns+" FROM drafts WHERE user_id = ? ORDER BY updated_at DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drafts := []entity.Draft{}
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, *draft)
+	}
+	return drafts, rows.Err()
+}
+
+func (r *SQLiteDraftRepository) Delete(ctx context.Context, userID int, topicID int64) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM drafts WHERE user_id = ? AND topic_id = ?", userID, topicID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteDraftRepository) DeleteOlderThan(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM drafts WHERE updated_at < ?", before.UTC())
+	return err
+}
+
+func scanDraft(row rowScanner) (*entity.Draft, error) {
+	var d entity.Draft
+	err := row.Scan(&d.UserID, &d.TopicID, &d.CategoryID, &d.ReplyToID, &d.Title, &d.Body, &d.Sequence, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
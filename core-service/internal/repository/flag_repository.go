@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type FlagRepository interface {
+	// Create records a flag and returns the post's total flag weight. A user
+	// flagging the same post again only updates the reason.
+	Create(ctx context.Context, flag *entity.Flag) (int, error)
+	ListByPost(ctx context.Context, postID int64) ([]entity.Flag, error)
+}
+
+type SQLiteFlagRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteFlagRepository(db *sql.DB) FlagRepository {
+	return &SQLiteFlagRepository{db: db}
+}
+
+func (r *SQLiteFlagRepository) Create(ctx context.Context, flag *entity.Flag) (int, error) {
+	flag.CreatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO post_flags (post_id, user_id, reason, weight, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET reason = excluded.reason
+		RETURNING id`,
+		flag.PostID, flag.UserID, flag.Reason, flag.Weight, flag.CreatedAt).Scan(&flag.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(weight), 0) FROM post_flags WHERE post_id = ?", flag.PostID).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, tx.Commit()
+}
+
+func (r *SQLiteFlagRepository) ListByPost(ctx context.Context, postID int64) ([]entity.Flag, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, post_id, user_id, reason, weight, created_at FROM post_flags WHERE post_id = ? ORDER BY id",
+		postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := []entity.Flag{}
+	for rows.Next() {
+		var f entity.Flag
+		if err := rows.Scan(&f.ID, &f.PostID, &f.UserID, &f.Reason, &f.Weight, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
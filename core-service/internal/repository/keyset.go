@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"core-service/internal/pagination"
+)
+
+// keyset describes how a listing is ordered: by column, then by id to make
+// the order total. Rows are addressed by (column, id) instead of an offset,
+// so the cost of a page does not grow with its depth and concurrent inserts
+// never cause rows to be skipped or repeated.
+type keyset struct {
+	column string
+	id     string
+	desc   bool
+	isTime bool
+}
+
+func (k keyset) after(c *pagination.Cursor) (string, []any) {
+	if c == nil {
+		return "", nil
+	}
+
+	op := ">"
+	if k.desc {
+		op = "<"
+	}
+	key := k.arg(c.Key)
+	clause := fmt.Sprintf(" AND (%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", k.column, op, k.id)
+	return clause, []any{key, key, c.ID}
+}
+
+func (k keyset) orderBy() string {
+	dir := "ASC"
+	if k.desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s, %s %s", k.column, dir, k.id, dir)
+}
+
+func (k keyset) arg(key int64) any {
+	if k.isTime {
+		return time.Unix(0, key).UTC()
+	}
+	return key
+}
+
+func sortFor(sorts map[pagination.Sort]keyset, sort pagination.Sort) (keyset, error) {
+	k, ok := sorts[sort]
+	if !ok {
+		return keyset{}, pagination.ErrUnsupportedSort
+	}
+	return k, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLiteDB(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestTopicPagingWithConcurrentInserts pages through every topic sort while
+// another writer keeps adding topics, and checks that each topic that existed
+// before paging started is listed exactly once, in order.
+func TestTopicPagingWithConcurrentInserts(t *testing.T) {
+	for _, sort := range []pagination.Sort{pagination.SortNewest, pagination.SortActivity, pagination.SortScore} {
+		t.Run(string(sort), func(t *testing.T) {
+			ctx := context.Background()
+			db := openTestDB(t)
+			categories := NewSQLiteCategoryRepository(db)
+			topics := NewSQLiteTopicRepository(db)
+
+			category := &entity.Category{Name: "general"}
+			if err := categories.Create(ctx, category); err != nil {
+				t.Fatalf("create category: %v", err)
+			}
+			create := func(i int) int64 {
+				topic := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "topic"}
+				if err := topics.Create(ctx, topic); err != nil {
+					t.Errorf("create topic: %v", err)
+					return 0
+				}
+				// Few distinct scores, so pages end inside runs of ties
+				if _, err := db.ExecContext(ctx, "UPDATE topics SET score = ? WHERE id = ?", i%3, topic.ID); err != nil {
+					t.Errorf("set score: %v", err)
+				}
+				return topic.ID
+			}
+
+			existing := make(map[int64]bool)
+			for i := range 50 {
+				existing[create(i)] = true
+			}
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					create(i)
+					time.Sleep(time.Millisecond)
+				}
+			}()
+			defer func() {
+				close(stop)
+				wg.Wait()
+			}()
+
+			seen := make(map[int64]bool)
+			var last *entity.Topic
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > 100 {
+					t.Fatal("paging did not end")
+				}
+				req, err := pagination.NewRequest(string(sort), "7", cursor, pagination.SortNewest, pagination.SortActivity, pagination.SortScore)
+				if err != nil {
+					t.Fatalf("NewRequest: %v", err)
+				}
+				page, err := topics.ListByCategory(ctx, category.ID, TopicsAll, req)
+				if err != nil {
+					t.Fatalf("ListByCategory: %v", err)
+				}
+				for _, topic := range page.Items {
+					if seen[topic.ID] {
+						t.Errorf("topic %d listed twice", topic.ID)
+					}
+					seen[topic.ID] = true
+					if last != nil && !ordered(sort, last, &topic) {
+						t.Errorf("topic %d listed after %d", topic.ID, last.ID)
+					}
+					last = &topic
+				}
+				// Make sure rows land between pages, not only during them
+				create(pages)
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			for id := range existing {
+				if !seen[id] {
+					t.Errorf("topic %d was skipped", id)
+				}
+			}
+		})
+	}
+}
+
+// ordered reports whether b correctly follows a in a listing by sort.
+func ordered(sort pagination.Sort, a, b *entity.Topic) bool {
+	var ka, kb int64
+	switch sort {
+	case pagination.SortActivity:
+		ka, kb = a.LastActivityAt.UnixNano(), b.LastActivityAt.UnixNano()
+	case pagination.SortScore:
+		ka, kb = int64(a.Score), int64(b.Score)
+	}
+	return ka > kb || ka == kb && a.ID > b.ID
+}
+
+func TestTopicPagingRejectsUnsupportedSort(t *testing.T) {
+	ctx := context.Background()
+	topics := NewSQLiteTopicRepository(openTestDB(t))
+
+	req := pagination.Request{Sort: pagination.SortScore, Limit: 10, After: &pagination.Cursor{Sort: pagination.SortScore, Key: 1, ID: 1}}
+	if _, err := topics.ListByCategory(ctx, 1, TopicsAll, req); err != nil {
+		t.Fatalf("ListByCategory: %v", err)
+	}
+	req.Sort = pagination.SortOldest
+	if _, err := topics.ListByCategory(ctx, 1, TopicsAll, req); err != pagination.ErrUnsupportedSort {
+		t.Errorf("ListByCategory with sort oldest = %v, want ErrUnsupportedSort", err)
+	}
+}
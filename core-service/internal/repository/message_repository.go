@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+)
+
+type MessageRepository interface {
+	// CreateConversation stores the conversation together with its first
+	// message, so no conversation is ever left empty.
+	CreateConversation(ctx context.Context, conv *entity.Conversation, first *entity.Message) error
+	GetConversation(ctx context.Context, id int64) (*entity.Conversation, error)
+	IsParticipant(ctx context.Context, conversationID int64, userID int) (bool, error)
+	// AddMessage stores the message and marks the conversation read up to it
+	// for the sender.
+	AddMessage(ctx context.Context, msg *entity.Message) error
+	ListMessages(ctx context.Context, conversationID int64, req pagination.Request) (pagination.Page[entity.Message], error)
+	Inbox(ctx context.Context, userID int, req pagination.Request) (pagination.Page[entity.InboxEntry], error)
+	MarkRead(ctx context.Context, conversationID int64, userID int) error
+	UnreadTotal(ctx context.Context, userID int) (int, error)
+}
+
+var messageSorts = map[pagination.Sort]keyset{
+	pagination.SortNewest: {column: "id", id: "id", desc: true},
+	pagination.SortOldest: {column: "id", id: "id"},
+}
+
+var inboxKeyset = keyset{column: "c.last_message_at", id: "c.id", desc: true, isTime: true}
+
+type SQLiteMessageRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteMessageRepository(db *sql.DB) MessageRepository {
+	return &SQLiteMessageRepository{db: db}
+}
+
+func (r *SQLiteMessageRepository) CreateConversation(ctx context.Context, conv *entity.Conversation, first *entity.Message) error {
+	now := time.Now().UTC()
+	conv.CreatedAt = now
+	conv.LastMessageAt = now
+	first.CreatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO conversations (subject, created_by, created_at, last_message_at) VALUES (?, ?, ?, ?)",
+		conv.Subject, conv.CreatedBy, conv.CreatedAt, conv.LastMessageAt)
+	if err != nil {
+		return err
+	}
+	conv.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range conv.Participants {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO conversation_participants (conversation_id, user_id) VALUES (?, ?)", conv.ID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	first.ConversationID = conv.ID
+	if err := addMessage(ctx, tx, first); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteMessageRepository) GetConversation(ctx context.Context, id int64) (*entity.Conversation, error) {
+	var c entity.Conversation
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, subject, created_by, created_at, last_message_at FROM conversations WHERE id = ?", id).
+		Scan(&c.ID, &c.Subject, &c.CreatedBy, &c.CreatedAt, &c.LastMessageAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	participants, err := r.participants(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+	c.Participants = participants[id]
+	return &c, nil
+}
+
+func (r *SQLiteMessageRepository) IsParticipant(ctx context.Context, conversationID int64, userID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM conversation_participants WHERE conversation_id = ? AND user_id = ?)",
+		conversationID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *SQLiteMessageRepository) AddMessage(ctx context.Context, msg *entity.Message) error {
+	msg.CreatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addMessage(ctx, tx, msg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addMessage stores msg in tx, bumps its conversation and marks it read for
+// the sender.
+func addMessage(ctx context.Context, tx *sql.Tx, msg *entity.Message) error {
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO messages (conversation_id, sender_id, body, created_at) VALUES (?, ?, ?, ?)",
+		msg.ConversationID, msg.SenderID, msg.Body, msg.CreatedAt)
+	if err != nil {
+		return err
+	}
+	msg.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE conversations SET last_message_at = ? WHERE id = ?", msg.CreatedAt, msg.ConversationID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE conversation_participants SET last_read_message_id = ? WHERE conversation_id = ? AND user_id = ?",
+		msg.ID, msg.ConversationID, msg.SenderID)
+	return err
+}
+
+func (r *SQLiteMessageRepository) ListMessages(ctx context.Context, conversationID int64, req pagination.Request) (pagination.Page[entity.Message], error) {
+	k, err := sortFor(messageSorts, req.Sort)
+	if err != nil {
+		return pagination.Page[entity.Message]{}, err
+	}
+
+	where, args := k.after(req.After)
+	query := "SELECT id, conversation_id, sender_id, body, created_at FROM messages WHERE conversation_id = ?" +
+		where + k.orderBy() + " LIMIT ?"
+	args = append([]any{conversationID}, args...)
+	args = append(args, req.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[entity.Message]{}, err
+	}
+	defer rows.Close()
+
+	var messages []entity.Message
+	for rows.Next() {
+		var m entity.Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.CreatedAt); err != nil {
+			return pagination.Page[entity.Message]{}, err
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[entity.Message]{}, err
+	}
+
+	return pagination.NewPage(messages, req, func(m entity.Message) pagination.Cursor {
+		return pagination.Cursor{Sort: req.Sort, Key: m.ID, ID: m.ID}
+	}), nil
+}
+
+func (r *SQLiteMessageRepository) Inbox(ctx context.Context, userID int, req pagination.Request) (pagination.Page[entity.InboxEntry], error) {
+	where, args := inboxKeyset.after(req.After)
+	query := `
+		SELECT c.id, c.subject, c.created_by, c.created_at, c.last_message_at,
+			(SELECT COUNT(*) FROM messages m
+			 WHERE m.conversation_id = c.id AND m.id > p.last_read_message_id AND m.sender_id != p.user_id)
+		FROM conversations c
+		JOIN conversation_participants p ON p.conversation_id = c.id
+		WHERE p.user_id = ?` + where + inboxKeyset.orderBy() + " LIMIT ?"
+	args = append([]any{userID}, args...)
+	args = append(args, req.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[entity.InboxEntry]{}, err
+	}
+	defer rows.Close()
+
+	var entries []entity.InboxEntry
+	var ids []int64
+	for rows.Next() {
+		var e entity.InboxEntry
+		if err := rows.Scan(&e.ID, &e.Subject, &e.CreatedBy, &e.CreatedAt, &e.LastMessageAt, &e.Unread); err != nil {
+			return pagination.Page[entity.InboxEntry]{}, err
+		}
+		entries = append(entries, e)
+		ids = append(ids, e.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[entity.InboxEntry]{}, err
+	}
+
+	participants, err := r.participants(ctx, ids)
+	if err != nil {
+		return pagination.Page[entity.InboxEntry]{}, err
+	}
+	for i := range entries {
+		entries[i].Participants = participants[entries[i].ID]
+	}
+
+	return pagination.NewPage(entries, req, func(e entity.InboxEntry) pagination.Cursor {
+		return pagination.Cursor{Sort: req.Sort, Key: e.LastMessageAt.UnixNano(), ID: e.ID}
+	}), nil
+}
+
+func (r *SQLiteMessageRepository) MarkRead(ctx context.Context, conversationID int64, userID int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE conversation_participants
+		SET last_read_message_id = (SELECT COALESCE(MAX(id), 0) FROM messages WHERE conversation_id = ?)
+		WHERE conversation_id = ? AND user_id = ?`,
+		conversationID, conversationID, userID)
+	return err
+}
+
+func (r *SQLiteMessageRepository) UnreadTotal(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM messages m
+		JOIN conversation_participants p ON p.conversation_id = m.conversation_id
+		WHERE p.user_id = ? AND m.id > p.last_read_message_id AND m.sender_id != p.user_id`,
+		userID).Scan(&count)
+	return count, err
+}
+
+func (r *SQLiteMessageRepository) participants(ctx context.Context, conversationIDs []int64) (map[int64][]int, error) {
+	result := make(map[int64][]int)
+	if len(conversationIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT conversation_id, user_id FROM conversation_participants WHERE conversation_id IN ("+placeholders(len(conversationIDs))+") ORDER BY user_id",
+		int64Args(conversationIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var convID int64
+		var userID int
+		if err := rows.Scan(&convID, &userID); err != nil {
+			return nil, err
+		}
+		result[convID] = append(result[convID], userID)
+	}
+	return result, rows.Err()
+}
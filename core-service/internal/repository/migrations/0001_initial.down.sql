@@ -0,0 +1,29 @@
+DROP TABLE IF EXISTS profiles;
+DROP TABLE IF EXISTS topic_reads;
+DROP TABLE IF EXISTS scheduled_posts;
+DROP TABLE IF EXISTS drafts;
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+DROP TABLE IF EXISTS post_flags;
+DROP TABLE IF EXISTS post_votes;
+DROP TABLE IF EXISTS reputation;
+DROP TABLE IF EXISTS reputation_events;
+DROP TABLE IF EXISTS spam_corpus;
+DROP TABLE IF EXISTS spam_tokens;
+DROP TABLE IF EXISTS spam_fingerprints;
+DROP TABLE IF EXISTS post_reviews;
+DROP TABLE IF EXISTS topic_tags;
+DROP TABLE IF EXISTS tag_synonyms;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS user_blocks;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversation_participants;
+DROP TABLE IF EXISTS conversations;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS post_revisions;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS topics;
+DROP TABLE IF EXISTS categories;
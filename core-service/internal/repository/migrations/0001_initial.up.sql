@@ -0,0 +1,315 @@
+-- category
+CREATE TABLE IF NOT EXISTS categories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	description TEXT NOT NULL DEFAULT '',
+	qa_mode BOOLEAN NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL
+);
+
+-- topic
+CREATE TABLE IF NOT EXISTS topics (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	category_id INTEGER NOT NULL REFERENCES categories(id),
+	author_id INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	post_count INTEGER NOT NULL DEFAULT 0,
+	score INTEGER NOT NULL DEFAULT 0,
+	accepted_post_id INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	last_activity_at DATETIME NOT NULL,
+	deleted_at DATETIME,
+	deleted_by INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_topics_category_activity ON topics (category_id, last_activity_at, id);
+CREATE INDEX IF NOT EXISTS idx_topics_category_score ON topics (category_id, score, id);
+
+-- post
+CREATE TABLE IF NOT EXISTS posts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	topic_id INTEGER NOT NULL REFERENCES topics(id),
+	author_id INTEGER NOT NULL,
+	reply_to_id INTEGER NOT NULL DEFAULT 0,
+	body TEXT NOT NULL,
+	score INTEGER NOT NULL DEFAULT 0,
+	wiki BOOLEAN NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	deleted_at DATETIME,
+	deleted_by INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_posts_topic ON posts (topic_id, id);
+CREATE INDEX IF NOT EXISTS idx_posts_topic_score ON posts (topic_id, score, id);
+CREATE INDEX IF NOT EXISTS idx_posts_author ON posts (author_id, id);
+CREATE TABLE IF NOT EXISTS post_revisions (
+	post_id INTEGER NOT NULL REFERENCES posts(id),
+	number INTEGER NOT NULL,
+	editor_id INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (post_id, number)
+);
+CREATE TRIGGER IF NOT EXISTS post_revisions_immutable
+BEFORE UPDATE ON post_revisions
+BEGIN
+	SELECT RAISE(ABORT, 'post revisions are immutable');
+END;
+
+-- subscription
+CREATE TABLE IF NOT EXISTS subscriptions (
+	user_id INTEGER NOT NULL,
+	topic_id INTEGER NOT NULL DEFAULT 0,
+	category_id INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (user_id, topic_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_topic ON subscriptions (topic_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_category ON subscriptions (category_id);
+
+-- notification
+CREATE TABLE IF NOT EXISTS notifications (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	actor_id INTEGER NOT NULL,
+	topic_id INTEGER NOT NULL,
+	post_id INTEGER NOT NULL,
+	is_read BOOLEAN NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	delivered_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, is_read, id);
+CREATE INDEX IF NOT EXISTS idx_notifications_undelivered ON notifications (id) WHERE delivered_at IS NULL;
+
+-- message
+CREATE TABLE IF NOT EXISTS conversations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	subject TEXT NOT NULL DEFAULT '',
+	created_by INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	last_message_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS conversation_participants (
+	conversation_id INTEGER NOT NULL REFERENCES conversations(id),
+	user_id INTEGER NOT NULL,
+	last_read_message_id INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (conversation_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_user ON conversation_participants (user_id);
+CREATE TABLE IF NOT EXISTS messages (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	conversation_id INTEGER NOT NULL REFERENCES conversations(id),
+	sender_id INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages (conversation_id, id);
+
+-- block
+CREATE TABLE IF NOT EXISTS user_blocks (
+	user_id INTEGER NOT NULL,
+	blocked_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (user_id, blocked_id)
+);
+
+-- attachment
+CREATE TABLE IF NOT EXISTS attachments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	uploader_id INTEGER NOT NULL,
+	post_id INTEGER NOT NULL DEFAULT 0,
+	filename TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	sha256 TEXT NOT NULL,
+	storage_key TEXT NOT NULL,
+	thumbnail_key TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	deleted_at DATETIME,
+	deleted_by INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_post ON attachments (post_id);
+
+-- tag
+CREATE TABLE IF NOT EXISTS tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	created_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS tag_synonyms (
+	name TEXT PRIMARY KEY,
+	tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_tag_synonyms_tag ON tag_synonyms (tag_id);
+CREATE TABLE IF NOT EXISTS topic_tags (
+	topic_id INTEGER NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+	tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (topic_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_topic_tags_tag ON topic_tags (tag_id, topic_id);
+
+-- review
+CREATE TABLE IF NOT EXISTS post_reviews (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	author_id INTEGER NOT NULL,
+	category_id INTEGER NOT NULL DEFAULT 0,
+	topic_id INTEGER NOT NULL DEFAULT 0,
+	reply_to_id INTEGER NOT NULL DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL,
+	attachment_ids TEXT NOT NULL DEFAULT '[]',
+	verdict TEXT NOT NULL,
+	reasons TEXT NOT NULL DEFAULT '[]',
+	status TEXT NOT NULL,
+	reviewed_by INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	reviewed_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_post_reviews_status ON post_reviews (status, id);
+
+-- spam
+CREATE TABLE IF NOT EXISTS spam_fingerprints (
+	fingerprint TEXT NOT NULL,
+	author_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_spam_fingerprints ON spam_fingerprints (fingerprint, created_at);
+CREATE INDEX IF NOT EXISTS idx_spam_fingerprints_created ON spam_fingerprints (created_at);
+CREATE TABLE IF NOT EXISTS spam_tokens (
+	token TEXT PRIMARY KEY,
+	spam INTEGER NOT NULL DEFAULT 0,
+	ham INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS spam_corpus (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	spam_docs INTEGER NOT NULL DEFAULT 0,
+	ham_docs INTEGER NOT NULL DEFAULT 0
+);
+INSERT OR IGNORE INTO spam_corpus (id) VALUES (1);
+
+-- reputation
+CREATE TABLE IF NOT EXISTS reputation_events (
+	user_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	source_id INTEGER NOT NULL,
+	actor_id INTEGER NOT NULL,
+	points INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (kind, source_id, actor_id)
+);
+CREATE INDEX IF NOT EXISTS idx_reputation_events_user ON reputation_events (user_id);
+CREATE TABLE IF NOT EXISTS reputation (
+	user_id INTEGER PRIMARY KEY,
+	points INTEGER NOT NULL,
+	days_active INTEGER NOT NULL,
+	trust_level INTEGER NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+-- vote
+CREATE TABLE IF NOT EXISTS post_votes (
+	post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	value INTEGER NOT NULL CHECK (value IN (-1, 1)),
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (post_id, user_id)
+);
+
+-- flag
+CREATE TABLE IF NOT EXISTS post_flags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	reason TEXT NOT NULL,
+	weight INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	UNIQUE (post_id, user_id)
+);
+
+-- poll
+CREATE TABLE IF NOT EXISTS polls (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	topic_id INTEGER NOT NULL UNIQUE REFERENCES topics(id) ON DELETE CASCADE,
+	question TEXT NOT NULL,
+	multiple BOOLEAN NOT NULL DEFAULT 0,
+	anonymous BOOLEAN NOT NULL DEFAULT 0,
+	results TEXT NOT NULL,
+	closes_at DATETIME,
+	created_at DATETIME NOT NULL
+);
+CREATE TABLE IF NOT EXISTS poll_options (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	text TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS poll_votes (
+	poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+	option_id INTEGER NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (poll_id, user_id, option_id)
+);
+CREATE INDEX IF NOT EXISTS idx_poll_options_poll ON poll_options (poll_id, position);
+CREATE INDEX IF NOT EXISTS idx_poll_votes_option ON poll_votes (option_id);
+
+-- draft
+CREATE TABLE IF NOT EXISTS drafts (
+	user_id INTEGER NOT NULL,
+	topic_id INTEGER NOT NULL,
+	category_id INTEGER NOT NULL DEFAULT 0,
+	reply_to_id INTEGER NOT NULL DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL,
+	sequence INTEGER NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (user_id, topic_id)
+);
+CREATE INDEX IF NOT EXISTS idx_drafts_updated ON drafts (updated_at);
+
+-- schedule
+CREATE TABLE IF NOT EXISTS scheduled_posts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	author_id INTEGER NOT NULL,
+	category_id INTEGER NOT NULL DEFAULT 0,
+	topic_id INTEGER NOT NULL DEFAULT 0,
+	reply_to_id INTEGER NOT NULL DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL,
+	tags TEXT NOT NULL DEFAULT '[]',
+	publish_at DATETIME NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	claimed_at DATETIME,
+	post_id INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_scheduled_posts_due ON scheduled_posts (status, publish_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_posts_author ON scheduled_posts (author_id, publish_at);
+
+-- read
+CREATE TABLE IF NOT EXISTS topic_reads (
+	user_id INTEGER NOT NULL,
+	topic_id INTEGER NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+	last_read_post_id INTEGER NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (user_id, topic_id)
+);
+CREATE INDEX IF NOT EXISTS idx_topic_reads_topic ON topic_reads (topic_id);
+
+-- profile
+CREATE TABLE IF NOT EXISTS profiles (
+	user_id INTEGER PRIMARY KEY,
+	display_name TEXT NOT NULL DEFAULT '',
+	avatar_id INTEGER NOT NULL DEFAULT 0,
+	bio TEXT NOT NULL DEFAULT '',
+	signature TEXT NOT NULL DEFAULT '',
+	location TEXT NOT NULL DEFAULT '',
+	location_visibility TEXT NOT NULL DEFAULT 'public',
+	last_seen_visibility TEXT NOT NULL DEFAULT 'public',
+	activity_visibility TEXT NOT NULL DEFAULT 'public',
+	joined_at DATETIME NOT NULL,
+	last_seen_at DATETIME
+);
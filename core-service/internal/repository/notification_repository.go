@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n *entity.Notification) error
+	ListByUser(ctx context.Context, userID int, unreadOnly bool, req pagination.Request) (pagination.Page[entity.Notification], error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID int, id int64) error
+	MarkAllRead(ctx context.Context, userID int) error
+	// ListUndelivered returns notifications not yet handed to the digest
+	// channels, oldest first.
+	ListUndelivered(ctx context.Context, limit int) ([]entity.Notification, error)
+	MarkDelivered(ctx context.Context, ids []int64) error
+}
+
+const notificationColumns = "id, user_id, kind, actor_id, topic_id, post_id, is_read, created_at"
+
+var notificationKeyset = keyset{column: "id", id: "id", desc: true}
+
+type SQLiteNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteNotificationRepository(db *sql.DB) NotificationRepository {
+	return &SQLiteNotificationRepository{db: db}
+}
+
+func (r *SQLiteNotificationRepository) Create(ctx context.Context, n *entity.Notification) error {
+	n.CreatedAt = time.Now().UTC()
+
+	res, err := r.db.ExecContext(ctx,
+		"INSERT INTO notifications (user_id, kind, actor_id, topic_id, post_id, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		n.UserID, n.Kind, n.ActorID, n.TopicID, n.PostID, n.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	n.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteNotificationRepository) ListByUser(ctx context.Context, userID int, unreadOnly bool, req pagination.Request) (pagination.Page[entity.Notification], error) {
+	filter := ""
+	if unreadOnly {
+		filter = " AND is_read = 0"
+	}
+
+	where, args := notificationKeyset.after(req.After)
+	query := "SELECT " + notificationColumns + " FROM notifications WHERE user_id = ?" + filter + where +
+		notificationKeyset.orderBy() + " LIMIT ?"
+	args = append([]any{userID}, args...)
+	args = append(args, req.Limit+1)
+
+	notifications, err := r.query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[entity.Notification]{}, err
+	}
+	return pagination.NewPage(notifications, req, func(n entity.Notification) pagination.Cursor {
+		return pagination.Cursor{Sort: req.Sort, Key: n.ID, ID: n.ID}
+	}), nil
+}
+
+func (r *SQLiteNotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM notifications WHERE user_id = ? AND is_read = 0", userID).Scan(&count)
+	return count, err
+}
+
+func (r *SQLiteNotificationRepository) MarkRead(ctx context.Context, userID int, id int64) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE notifications SET is_read = 1 WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteNotificationRepository) MarkAllRead(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE notifications SET is_read = 1 WHERE user_id = ? AND is_read = 0", userID)
+	return err
+}
+
+func (r *SQLiteNotificationRepository) ListUndelivered(ctx context.Context, limit int) ([]entity.Notification, error) {
+	return r.query(ctx,
+		"SELECT "+notificationColumns+" FROM notifications WHERE delivered_at IS NULL ORDER BY id LIMIT ?", limit)
+}
+
+func (r *SQLiteNotificationRepository) MarkDelivered(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := append([]any{time.Now().UTC()}, int64Args(ids)...)
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE notifications SET delivered_at = ? WHERE id IN ("+placeholders(len(ids))+")", args...)
+	return err
+}
+
+func (r *SQLiteNotificationRepository) query(ctx context.Context, query string, args ...any) ([]entity.Notification, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []entity.Notification
+	for rows.Next() {
+		var n entity.Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.ActorID, &n.TopicID, &n.PostID, &n.Read, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
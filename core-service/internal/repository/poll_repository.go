@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+var (
+	// ErrPollExists is returned when a topic already has a poll.
+	ErrPollExists = errors.New("topic already has a poll")
+	// ErrInvalidChoice is returned when a ballot names options of another poll.
+	ErrInvalidChoice = errors.New("invalid poll choice")
+	ErrPollClosed    = errors.New("poll is closed")
+)
+
+type PollRepository interface {
+	// Create stores a poll with its options; a topic has at most one poll.
+	Create(ctx context.Context, poll *entity.Poll) error
+	// GetByTopic returns the poll with its options but without vote counts.
+	GetByTopic(ctx context.Context, topicID int64) (*entity.Poll, error)
+	// Vote replaces the user's ballot with the given options; no options
+	// withdraws it.
+	Vote(ctx context.Context, pollID int64, userID int, optionIDs []int64) error
+	// Tally fills in vote counts, the number of voters and, if withVoters is
+	// set, who voted for each option, all from one consistent snapshot.
+	Tally(ctx context.Context, poll *entity.Poll, withVoters bool) error
+	Choices(ctx context.Context, pollID int64, userID int) ([]int64, error)
+	Close(ctx context.Context, pollID int64, at time.Time) error
+}
+
+type SQLitePollRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePollRepository(db *sql.DB) PollRepository {
+	return &SQLitePollRepository{db: db}
+}
+
+func (r *SQLitePollRepository) Create(ctx context.Context, poll *entity.Poll) error {
+	poll.CreatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO polls (topic_id, question, multiple, anonymous, results, closes_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (topic_id) DO NOTHING RETURNING id`,
+		poll.TopicID, poll.Question, poll.Multiple, poll.Anonymous, poll.Results, poll.ClosesAt, poll.CreatedAt).Scan(&poll.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPollExists
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range poll.Options {
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO poll_options (poll_id, position, text) VALUES (?, ?, ?) RETURNING id",
+			poll.ID, i, poll.Options[i].Text).Scan(&poll.Options[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLitePollRepository) GetByTopic(ctx context.Context, topicID int64) (*entity.Poll, error) {
+	var poll entity.Poll
+	var closesAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, topic_id, question, multiple, anonymous, results, closes_at, created_at
+		FROM polls WHERE topic_id = ?`, topicID).Scan(
+		&poll.ID, &poll.TopicID, &poll.Question, &poll.Multiple, &poll.Anonymous, &poll.Results, &closesAt, &poll.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	poll.ClosesAt = nullTime(closesAt)
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, text FROM poll_options WHERE poll_id = ? ORDER BY position", poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o entity.PollOption
+		if err := rows.Scan(&o.ID, &o.Text); err != nil {
+			return nil, err
+		}
+		poll.Options = append(poll.Options, o)
+	}
+	return &poll, rows.Err()
+}
+
+// Vote runs in one transaction, so concurrent ballots of the same user never
+// leave a mix of both behind and counts never see half a ballot. The closing
+// time is checked after the first write, once the transaction holds the
+// database's write lock, so no vote slips in after a poll was closed.
+func (r *SQLitePollRepository) Vote(ctx context.Context, pollID int64, userID int, optionIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM poll_votes WHERE poll_id = ? AND user_id = ?", pollID, userID); err != nil {
+		return err
+	}
+
+	var closesAt sql.NullTime
+	if err := tx.QueryRowContext(ctx, "SELECT closes_at FROM polls WHERE id = ?", pollID).Scan(&closesAt); err != nil {
+		return notFound(err)
+	}
+	if closesAt.Valid && !time.Now().Before(closesAt.Time) {
+		return ErrPollClosed
+	}
+
+	if len(optionIDs) > 0 {
+		var valid int
+		args := append([]any{pollID}, int64Args(optionIDs)...)
+		err := tx.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM poll_options WHERE poll_id = ? AND id IN ("+placeholders(len(optionIDs))+")",
+			args...).Scan(&valid)
+		if err != nil {
+			return err
+		}
+		if valid != len(optionIDs) {
+			return ErrInvalidChoice
+		}
+
+		now := time.Now().UTC()
+		for _, optionID := range optionIDs {
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO poll_votes (poll_id, option_id, user_id, created_at) VALUES (?, ?, ?, ?)",
+				pollID, optionID, userID, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLitePollRepository) Tally(ctx context.Context, poll *entity.Poll, withVoters bool) error {
+	// A read transaction gives all queries below the same snapshot.
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(DISTINCT user_id) FROM poll_votes WHERE poll_id = ?", poll.ID).Scan(&poll.Voters)
+	if err != nil {
+		return err
+	}
+
+	query := "SELECT option_id, user_id FROM poll_votes WHERE poll_id = ? ORDER BY created_at, user_id"
+	if !withVoters {
+		query = "SELECT option_id, COUNT(*) FROM poll_votes WHERE poll_id = ? GROUP BY option_id"
+	}
+	rows, err := tx.QueryContext(ctx, query, poll.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int64]*entity.PollOption, len(poll.Options))
+	for i := range poll.Options {
+		poll.Options[i].Votes, poll.Options[i].VoterIDs = 0, nil
+		index[poll.Options[i].ID] = &poll.Options[i]
+	}
+	for rows.Next() {
+		var optionID int64
+		var value int
+		if err := rows.Scan(&optionID, &value); err != nil {
+			return err
+		}
+		o, ok := index[optionID]
+		if !ok {
+			continue
+		}
+		if withVoters {
+			o.Votes++
+			o.VoterIDs = append(o.VoterIDs, value)
+		} else {
+			o.Votes = value
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SQLitePollRepository) Choices(ctx context.Context, pollID int64, userID int) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT option_id FROM poll_votes WHERE poll_id = ? AND user_id = ? ORDER BY option_id", pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Close moves the closing time up to at; polls already closed keep theirs.
+func (r *SQLitePollRepository) Close(ctx context.Context, pollID int64, at time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE polls SET closes_at = ? WHERE id = ? AND (closes_at IS NULL OR closes_at > ?)",
+		at.UTC(), pollID, at.UTC())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		var exists bool
+		err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM polls WHERE id = ?)", pollID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+	}
+	return nil
+}
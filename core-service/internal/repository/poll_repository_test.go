@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+// TestPollConcurrentVoting has every user change their ballot several times
+// at once while others vote and results are counted. Each ballot names two
+// options, so every consistent tally counts twice as many votes as voters.
+func TestPollConcurrentVoting(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	categories := NewSQLiteCategoryRepository(db)
+	topics := NewSQLiteTopicRepository(db)
+	polls := NewSQLitePollRepository(db)
+
+	category := &entity.Category{Name: "general"}
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	topic := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "topic"}
+	if err := topics.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+	poll := &entity.Poll{TopicID: topic.ID, Question: "Which two?", Multiple: true, Results: entity.PollResultsAlways,
+		Options: []entity.PollOption{{Text: "a"}, {Text: "b"}, {Text: "c"}}}
+	if err := polls.Create(ctx, poll); err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := poll.Options[0].ID, poll.Options[1].ID, poll.Options[2].ID
+	ballots := [][]int64{{a, b}, {b, c}, {a, c}}
+
+	const users = 10
+	stop := make(chan struct{})
+	var tallies sync.WaitGroup
+	tallies.Add(1)
+	go func() {
+		defer tallies.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			snapshot := &entity.Poll{ID: poll.ID, Options: slices.Clone(poll.Options)}
+			if err := polls.Tally(ctx, snapshot, true); err != nil {
+				t.Errorf("Tally: %v", err)
+				return
+			}
+			votes := 0
+			for _, o := range snapshot.Options {
+				votes += o.Votes
+			}
+			if votes != 2*snapshot.Voters {
+				t.Errorf("tally counts %d votes from %d voters", votes, snapshot.Voters)
+				return
+			}
+		}
+	}()
+
+	var voters sync.WaitGroup
+	for user := 1; user <= users; user++ {
+		for _, ballot := range ballots {
+			voters.Add(1)
+			go func() {
+				defer voters.Done()
+				if err := polls.Vote(ctx, poll.ID, user, ballot); err != nil {
+					t.Errorf("Vote: %v", err)
+				}
+			}()
+		}
+	}
+	voters.Wait()
+	close(stop)
+	tallies.Wait()
+
+	// Each user is left with exactly one of their ballots, never a mix
+	counted := make(map[int64]int)
+	for user := 1; user <= users; user++ {
+		choices, err := polls.Choices(ctx, poll.ID, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.ContainsFunc(ballots, func(ballot []int64) bool {
+			return slices.Equal(slices.Sorted(slices.Values(ballot)), choices)
+		}) {
+			t.Errorf("user %d is left with %v", user, choices)
+		}
+		for _, id := range choices {
+			counted[id]++
+		}
+	}
+
+	if err := polls.Tally(ctx, poll, true); err != nil {
+		t.Fatal(err)
+	}
+	if poll.Voters != users {
+		t.Errorf("%d voters, want %d", poll.Voters, users)
+	}
+	for _, o := range poll.Options {
+		if o.Votes != counted[o.ID] || len(o.VoterIDs) != o.Votes {
+			t.Errorf("option %s: %d votes by %v, want %d", o.Text, o.Votes, o.VoterIDs, counted[o.ID])
+		}
+	}
+}
+
+func TestPollClose(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	categories := NewSQLiteCategoryRepository(db)
+	topics := NewSQLiteTopicRepository(db)
+	polls := NewSQLitePollRepository(db)
+
+	category := &entity.Category{Name: "general"}
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	topic := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "topic"}
+	if err := topics.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+	poll := &entity.Poll{TopicID: topic.ID, Question: "Yes?", Results: entity.PollResultsAlways,
+		Options: []entity.PollOption{{Text: "yes"}, {Text: "no"}}}
+	if err := polls.Create(ctx, poll); err != nil {
+		t.Fatal(err)
+	}
+	yes := poll.Options[0].ID
+	if err := polls.Vote(ctx, poll.ID, 1, []int64{yes}); err != nil {
+		t.Fatal(err)
+	}
+
+	closedAt := time.Now().Add(-time.Minute)
+	if err := polls.Close(ctx, poll.ID, closedAt); err != nil {
+		t.Fatal(err)
+	}
+	// Closing again later keeps the first closing time
+	if err := polls.Close(ctx, poll.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := polls.GetByTopic(ctx, topic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ClosesAt == nil || !got.ClosesAt.Equal(closedAt.UTC()) {
+		t.Errorf("closes_at = %v, want %v", got.ClosesAt, closedAt)
+	}
+
+	// Neither new ballots nor withdrawals are accepted
+	if err := polls.Vote(ctx, poll.ID, 2, []int64{yes}); !errors.Is(err, ErrPollClosed) {
+		t.Errorf("Vote on a closed poll = %v, want ErrPollClosed", err)
+	}
+	if err := polls.Vote(ctx, poll.ID, 1, nil); !errors.Is(err, ErrPollClosed) {
+		t.Errorf("withdrawal from a closed poll = %v, want ErrPollClosed", err)
+	}
+	if choices, err := polls.Choices(ctx, poll.ID, 1); err != nil || !slices.Equal(choices, []int64{yes}) {
+		t.Errorf("choices after closing = %v, %v", choices, err)
+	}
+	if err := polls.Close(ctx, poll.ID+1, time.Now()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Close of a missing poll = %v, want ErrNotFound", err)
+	}
+}
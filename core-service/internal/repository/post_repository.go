@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+)
+
+type PostRepository interface {
+	// Create stores a new post, links the attachments in post.AttachmentIDs
+	// its author uploaded to it and bumps the topic's post count and activity
+	// time, all in one transaction.
+	Create(ctx context.Context, post *entity.Post) error
+	GetByID(ctx context.Context, id int64) (*entity.Post, error)
+	ListByTopic(ctx context.Context, topicID int64, req pagination.Request) (pagination.Page[entity.Post], error)
+	ListByAuthor(ctx context.Context, authorID int, req pagination.Request) (pagination.Page[entity.Post], error)
+	// Edit replaces the post body and records the change as a new revision.
+	Edit(ctx context.Context, post *entity.Post, editorID int, reason string) (*entity.PostRevision, error)
+	ListRevisions(ctx context.Context, postID int64) ([]entity.PostRevision, error)
+	SoftDelete(ctx context.Context, id int64, by int) error
+	Restore(ctx context.Context, id int64) error
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]int64, error)
+	ListIDsByTopic(ctx context.Context, topicID int64) ([]int64, error)
+	// FirstPostAt returns when the author first posted, or ErrNotFound.
+	FirstPostAt(ctx context.Context, authorID int) (time.Time, error)
+	CountByAuthorSince(ctx context.Context, authorID int, since time.Time) (int, error)
+	SetWiki(ctx context.Context, id int64, wiki bool) error
+	// Purge permanently removes posts and their revisions. Their attachments
+	// are marked deleted so the attachment purge picks them up.
+	Purge(ctx context.Context, ids []int64) error
+}
+
+var postSorts = map[pagination.Sort]keyset{
+	pagination.SortOldest:   {column: "id", id: "id"},
+	pagination.SortNewest:   {column: "id", id: "id", desc: true},
+	pagination.SortActivity: {column: "updated_at", id: "id", desc: true, isTime: true},
+	pagination.SortScore:    {column: "score", id: "id", desc: true},
+}
+
+const postColumns = "id, topic_id, author_id, reply_to_id, body, score, wiki, created_at, updated_at, deleted_at, deleted_by"
+
+type SQLitePostRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePostRepository(db *sql.DB) PostRepository {
+	return &SQLitePostRepository{db: db}
+}
+
+func (r *SQLitePostRepository) Create(ctx context.Context, post *entity.Post) error {
+	now := time.Now().UTC()
+	post.CreatedAt = now
+	post.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO posts (topic_id, author_id, reply_to_id, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		post.TopicID, post.AuthorID, post.ReplyToID, post.Body, post.CreatedAt, post.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	post.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if len(post.AttachmentIDs) > 0 {
+		args := append([]any{post.ID, post.AuthorID}, int64Args(post.AttachmentIDs)...)
+		_, err = tx.ExecContext(ctx,
+			"UPDATE attachments SET post_id = ? WHERE uploader_id = ? AND post_id = 0 AND id IN ("+placeholders(len(post.AttachmentIDs))+")",
+			args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE topics SET post_count = post_count + 1, last_activity_at = ? WHERE id = ?",
+		post.CreatedAt, post.TopicID)
+	if err != nil {
+		return err
+	}
+
+	// Marking the scheduled item in the same transaction means a crash can
+	// never leave a post behind whose item is claimed and published again.
+	if post.ScheduledID != 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE scheduled_posts SET status = ?, post_id = ?, error = '', claimed_at = NULL WHERE id = ?",
+			entity.SchedulePublished, post.ID, post.ScheduledID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLitePostRepository) GetByID(ctx context.Context, id int64) (*entity.Post, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+postColumns+" FROM posts WHERE id = ?", id)
+	post, err := scanPost(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return post, nil
+}
+
+func (r *SQLitePostRepository) ListByTopic(ctx context.Context, topicID int64, req pagination.Request) (pagination.Page[entity.Post], error) {
+	return r.list(ctx, "topic_id = ?", topicID, req)
+}
+
+func (r *SQLitePostRepository) ListByAuthor(ctx context.Context, authorID int, req pagination.Request) (pagination.Page[entity.Post], error) {
+	return r.list(ctx, "author_id = ? AND deleted_at IS NULL", authorID, req)
+}
+
+func (r *SQLitePostRepository) SoftDelete(ctx context.Context, id int64, by int) error {
+	return softDelete(ctx, r.db, "posts", id, by)
+}
+
+func (r *SQLitePostRepository) Restore(ctx context.Context, id int64) error {
+	return restore(ctx, r.db, "posts", id)
+}
+
+func (r *SQLitePostRepository) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	return deletedBefore(ctx, r.db, "posts", before, limit)
+}
+
+func (r *SQLitePostRepository) ListIDsByTopic(ctx context.Context, topicID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM posts WHERE topic_id = ? ORDER BY id", topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *SQLitePostRepository) FirstPostAt(ctx context.Context, authorID int) (time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRowContext(ctx,
+		"SELECT created_at FROM posts WHERE author_id = ? ORDER BY id LIMIT 1", authorID).Scan(&at)
+	if err != nil {
+		return time.Time{}, notFound(err)
+	}
+	return at, nil
+}
+
+func (r *SQLitePostRepository) CountByAuthorSince(ctx context.Context, authorID int, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM posts WHERE author_id = ? AND created_at >= ?", authorID, since.UTC()).Scan(&count)
+	return count, err
+}
+
+func (r *SQLitePostRepository) SetWiki(ctx context.Context, id int64, wiki bool) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE posts SET wiki = ? WHERE id = ?", wiki, id)
+	return err
+}
+
+func (r *SQLitePostRepository) Purge(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	in := " IN (" + placeholders(len(ids)) + ")"
+	args := int64Args(ids)
+	_, err = tx.ExecContext(ctx,
+		"UPDATE attachments SET deleted_at = ? WHERE deleted_at IS NULL AND post_id"+in,
+		append([]any{time.Unix(0, 0).UTC()}, args...)...)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		"DELETE FROM post_revisions WHERE post_id" + in,
+		"DELETE FROM notifications WHERE post_id" + in,
+		"DELETE FROM posts WHERE id" + in,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLitePostRepository) Edit(ctx context.Context, post *entity.Post, editorID int, reason string) (*entity.PostRevision, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Posts written before their first edit have no revisions yet; keep the
+	// original body as revision 1.
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO post_revisions (post_id, number, editor_id, body, reason, created_at)
+		SELECT id, 1, author_id, body, '', created_at FROM posts
+		WHERE id = ? AND NOT EXISTS (SELECT 1 FROM post_revisions WHERE post_id = ?)`,
+		post.ID, post.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev := &entity.PostRevision{
+		PostID:    post.ID,
+		EditorID:  editorID,
+		Body:      post.Body,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(number), 0) + 1 FROM post_revisions WHERE post_id = ?", post.ID).Scan(&rev.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO post_revisions (post_id, number, editor_id, body, reason, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		rev.PostID, rev.Number, rev.EditorID, rev.Body, rev.Reason, rev.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE posts SET body = ?, updated_at = ? WHERE id = ?", post.Body, rev.CreatedAt, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	post.UpdatedAt = rev.CreatedAt
+	return rev, nil
+}
+
+// ListRevisions returns the post's revisions oldest first. A post that was
+// never edited has the single implicit revision of its current body.
+func (r *SQLitePostRepository) ListRevisions(ctx context.Context, postID int64) ([]entity.PostRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT post_id, number, editor_id, body, reason, created_at FROM post_revisions WHERE post_id = ?
+		UNION ALL
+		SELECT id, 1, author_id, body, '', created_at FROM posts
+		WHERE id = ? AND NOT EXISTS (SELECT 1 FROM post_revisions WHERE post_id = ?)
+		ORDER BY 2`,
+		postID, postID, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []entity.PostRevision
+	for rows.Next() {
+		var rev entity.PostRevision
+		if err := rows.Scan(&rev.PostID, &rev.Number, &rev.EditorID, &rev.Body, &rev.Reason, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *SQLitePostRepository) list(ctx context.Context, filter string, value any, req pagination.Request) (pagination.Page[entity.Post], error) {
+	k, err := sortFor(postSorts, req.Sort)
+	if err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+
+	where, args := k.after(req.After)
+	query := "SELECT " + postColumns + " FROM posts WHERE " + filter + where + k.orderBy() + " LIMIT ?"
+	args = append([]any{value}, args...)
+	args = append(args, req.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+	defer rows.Close()
+
+	var posts []entity.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return pagination.Page[entity.Post]{}, err
+		}
+		posts = append(posts, *post)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+
+	return pagination.NewPage(posts, req, func(p entity.Post) pagination.Cursor {
+		return postCursor(p, req.Sort)
+	}), nil
+}
+
+func scanPost(row rowScanner) (*entity.Post, error) {
+	var p entity.Post
+	var deletedAt sql.NullTime
+	err := row.Scan(&p.ID, &p.TopicID, &p.AuthorID, &p.ReplyToID, &p.Body, &p.Score, &p.Wiki, &p.CreatedAt, &p.UpdatedAt,
+		&deletedAt, &p.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	p.DeletedAt = nullTime(deletedAt)
+	return &p, nil
+}
+
+func postCursor(p entity.Post, sort pagination.Sort) pagination.Cursor {
+	c := pagination.Cursor{Sort: sort, Key: p.ID, ID: p.ID}
+	switch sort {
+	case pagination.SortActivity:
+		c.Key = p.UpdatedAt.UnixNano()
+	case pagination.SortScore:
+		c.Key = int64(p.Score)
+	}
+	return c
+}
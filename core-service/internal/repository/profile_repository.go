@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type ProfileRepository interface {
+	// Get returns the profile with the user's live post and topic counts.
+	Get(ctx context.Context, userID int) (*entity.Profile, error)
+	// Save creates or updates the editable part of a profile.
+	Save(ctx context.Context, profile *entity.Profile) error
+	// Touch records that the users were seen at the given time, creating
+	// their profiles if needed.
+	Touch(ctx context.Context, userIDs []int, at time.Time) error
+}
+
+type SQLiteProfileRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteProfileRepository(db *sql.DB) ProfileRepository {
+	return &SQLiteProfileRepository{db: db}
+}
+
+func (r *SQLiteProfileRepository) Get(ctx context.Context, userID int) (*entity.Profile, error) {
+	var p entity.Profile
+	var privacy entity.ProfilePrivacy
+	var lastSeen sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, display_name, avatar_id, bio, signature, location,
+			location_visibility, last_seen_visibility, activity_visibility, joined_at, last_seen_at,
+			(SELECT COUNT(*) FROM posts WHERE author_id = profiles.user_id AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM topics WHERE author_id = profiles.user_id AND deleted_at IS NULL)
+		FROM profiles WHERE user_id = ?`, userID).Scan(
+		&p.UserID, &p.DisplayName, &p.AvatarID, &p.Bio, &p.Signature, &p.Location,
+		&privacy.Location, &privacy.LastSeen, &privacy.Activity, &p.JoinedAt, &lastSeen,
+		&p.PostCount, &p.TopicCount)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	p.LastSeenAt = nullTime(lastSeen)
+	p.Privacy = &privacy
+	return &p, nil
+}
+
+func (r *SQLiteProfileRepository) Save(ctx context.Context, p *entity.Profile) error {
+	if p.JoinedAt.IsZero() {
+		p.JoinedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO profiles (user_id, display_name, avatar_id, bio, signature, location,
+			location_visibility, last_seen_visibility, activity_visibility, joined_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			display_name = excluded.display_name, avatar_id = excluded.avatar_id, bio = excluded.bio,
+			signature = excluded.signature, location = excluded.location,
+			location_visibility = excluded.location_visibility,
+			last_seen_visibility = excluded.last_seen_visibility,
+			activity_visibility = excluded.activity_visibility`,
+		p.UserID, p.DisplayName, p.AvatarID, p.Bio, p.Signature, p.Location,
+		p.Privacy.Location, p.Privacy.LastSeen, p.Privacy.Activity, p.JoinedAt.UTC())
+	return err
+}
+
+func (r *SQLiteProfileRepository) Touch(ctx context.Context, userIDs []int, at time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO profiles (user_id, joined_at, last_seen_at) VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET last_seen_at = excluded.last_seen_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, userID := range userIDs {
+		if _, err := stmt.ExecContext(ctx, userID, at.UTC(), at.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type ReadRepository interface {
+	// SaveMarkers stores a batch of read markers. Markers only ever move
+	// forward, so batches may be saved in any order. Markers for topics that
+	// no longer exist are skipped.
+	SaveMarkers(ctx context.Context, markers []entity.ReadMarker) error
+	// States returns the user's read state for those of the topics they
+	// opened before.
+	States(ctx context.Context, userID int, topicIDs []int64) (map[int64]entity.ReadState, error)
+	// MarkCategoryRead marks every topic of the category as read up to its
+	// latest post.
+	MarkCategoryRead(ctx context.Context, userID int, categoryID int64) error
+}
+
+type SQLiteReadRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReadRepository(db *sql.DB) ReadRepository {
+	return &SQLiteReadRepository{db: db}
+}
+
+// upsertReadMarker skips topics purged since they were read; their marker
+// would fail the foreign key and with it the whole batch.
+const upsertReadMarker = `
+	INSERT INTO topic_reads (user_id, topic_id, last_read_post_id, updated_at)
+	SELECT ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM topics WHERE id = ?)
+	ON CONFLICT (user_id, topic_id) DO UPDATE SET
+		last_read_post_id = excluded.last_read_post_id, updated_at = excluded.updated_at
+	WHERE excluded.last_read_post_id > topic_reads.last_read_post_id`
+
+// SaveMarkers writes the whole batch in one transaction, which costs SQLite
+// a single commit instead of one per marker.
+func (r *SQLiteReadRepository) SaveMarkers(ctx context.Context, markers []entity.ReadMarker) error {
+	if len(markers) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, upsertReadMarker)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, m := range markers {
+		if _, err := stmt.ExecContext(ctx, m.UserID, m.TopicID, m.PostID, now, m.TopicID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteReadRepository) States(ctx context.Context, userID int, topicIDs []int64) (map[int64]entity.ReadState, error) {
+	states := make(map[int64]entity.ReadState, len(topicIDs))
+	if len(topicIDs) == 0 {
+		return states, nil
+	}
+
+	args := append([]any{userID}, int64Args(topicIDs)...)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.topic_id, r.last_read_post_id, COUNT(p.id), COALESCE(MIN(p.id), 0)
+		FROM topic_reads r
+		LEFT JOIN posts p ON p.topic_id = r.topic_id AND p.id > r.last_read_post_id AND p.deleted_at IS NULL
+		WHERE r.user_id = ? AND r.topic_id IN (`+placeholders(len(topicIDs))+`)
+		GROUP BY r.topic_id, r.last_read_post_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var topicID int64
+		var s entity.ReadState
+		if err := rows.Scan(&topicID, &s.LastReadPostID, &s.UnreadCount, &s.FirstUnreadPostID); err != nil {
+			return nil, err
+		}
+		states[topicID] = s
+	}
+	return states, rows.Err()
+}
+
+func (r *SQLiteReadRepository) MarkCategoryRead(ctx context.Context, userID int, categoryID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO topic_reads (user_id, topic_id, last_read_post_id, updated_at)
+		SELECT ?, p.topic_id, MAX(p.id), ? FROM posts p
+		JOIN topics t ON t.id = p.topic_id
+		WHERE t.category_id = ? AND t.deleted_at IS NULL
+		GROUP BY p.topic_id
+		ON CONFLICT (user_id, topic_id) DO UPDATE SET
+			last_read_post_id = excluded.last_read_post_id, updated_at = excluded.updated_at
+		WHERE excluded.last_read_post_id > topic_reads.last_read_post_id`,
+		userID, time.Now().UTC(), categoryID)
+	return err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"core-service/internal/entity"
+)
+
+func TestSaveMarkersSkipsPurgedTopics(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	categories := NewSQLiteCategoryRepository(db)
+	topics := NewSQLiteTopicRepository(db)
+	reads := NewSQLiteReadRepository(db)
+
+	category := &entity.Category{Name: "general"}
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	kept := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "kept"}
+	purged := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "purged"}
+	for _, topic := range []*entity.Topic{kept, purged} {
+		if err := topics.Create(ctx, topic); err != nil {
+			t.Fatalf("create topic: %v", err)
+		}
+	}
+	if err := topics.Purge(ctx, purged.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+
+	err := reads.SaveMarkers(ctx, []entity.ReadMarker{
+		{UserID: 7, TopicID: purged.ID, PostID: 3},
+		{UserID: 7, TopicID: kept.ID, PostID: 5},
+	})
+	if err != nil {
+		t.Fatalf("SaveMarkers with a purged topic: %v", err)
+	}
+	states, err := reads.States(ctx, 7, []int64{kept.ID, purged.ID})
+	if err != nil {
+		t.Fatalf("States: %v", err)
+	}
+	if len(states) != 1 || states[kept.ID].LastReadPostID != 5 {
+		t.Errorf("States = %+v, want post 5 read in topic %d only", states, kept.ID)
+	}
+
+	// Markers never move back
+	if err := reads.SaveMarkers(ctx, []entity.ReadMarker{{UserID: 7, TopicID: kept.ID, PostID: 4}}); err != nil {
+		t.Fatalf("SaveMarkers: %v", err)
+	}
+	if states, _ := reads.States(ctx, 7, []int64{kept.ID}); states[kept.ID].LastReadPostID != 5 {
+		t.Errorf("marker moved back to %d", states[kept.ID].LastReadPostID)
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type ReputationRepository interface {
+	// RecordEvent adds an event to the ledger, replacing the points of an
+	// existing event with the same kind, source and actor.
+	RecordEvent(ctx context.Context, e *entity.ReputationEvent) error
+	RemoveEvent(ctx context.Context, kind entity.ReputationEventKind, sourceID int64, actorID int) error
+	// Totals sums a user's ledger: all points, and the number of days active.
+	Totals(ctx context.Context, userID int) (points, daysActive int, err error)
+	// Get returns the stored reputation, or a zero one for unknown users.
+	Get(ctx context.Context, userID int) (*entity.Reputation, error)
+	Save(ctx context.Context, rep *entity.Reputation) error
+	// ListUserIDs returns every user with a ledger entry or stored reputation.
+	ListUserIDs(ctx context.Context) ([]int, error)
+}
+
+type SQLiteReputationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReputationRepository(db *sql.DB) ReputationRepository {
+	return &SQLiteReputationRepository{db: db}
+}
+
+func (r *SQLiteReputationRepository) RecordEvent(ctx context.Context, e *entity.ReputationEvent) error {
+	e.CreatedAt = time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO reputation_events (user_id, kind, source_id, actor_id, points, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (kind, source_id, actor_id) DO UPDATE SET user_id = excluded.user_id, points = excluded.points`,
+		e.UserID, e.Kind, e.SourceID, e.ActorID, e.Points, e.CreatedAt)
+	return err
+}
+
+func (r *SQLiteReputationRepository) RemoveEvent(ctx context.Context, kind entity.ReputationEventKind, sourceID int64, actorID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM reputation_events WHERE kind = ? AND source_id = ? AND actor_id = ?", kind, sourceID, actorID)
+	return err
+}
+
+func (r *SQLiteReputationRepository) Totals(ctx context.Context, userID int) (int, int, error) {
+	var points, days int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(points), 0), COALESCE(SUM(kind = ?), 0)
+		FROM reputation_events WHERE user_id = ?`,
+		entity.ReputationDayActive, userID).Scan(&points, &days)
+	return points, days, err
+}
+
+func (r *SQLiteReputationRepository) Get(ctx context.Context, userID int) (*entity.Reputation, error) {
+	rep := entity.Reputation{UserID: userID}
+	err := r.db.QueryRowContext(ctx,
+		"SELECT points, days_active, trust_level, updated_at FROM reputation WHERE user_id = ?", userID).
+		Scan(&rep.Points, &rep.DaysActive, &rep.TrustLevel, &rep.UpdatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+func (r *SQLiteReputationRepository) Save(ctx context.Context, rep *entity.Reputation) error {
+	rep.UpdatedAt = time.Now().UTC()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO reputation (user_id, points, days_active, trust_level, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			points = excluded.points, days_active = excluded.days_active,
+			trust_level = excluded.trust_level, updated_at = excluded.updated_at`,
+		rep.UserID, rep.Points, rep.DaysActive, rep.TrustLevel, rep.UpdatedAt)
+	return err
+}
+
+func (r *SQLiteReputationRepository) ListUserIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT user_id FROM reputation_events UNION SELECT user_id FROM reputation ORDER BY user_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
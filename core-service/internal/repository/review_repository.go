@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+)
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *entity.PostReview) error
+	GetByID(ctx context.Context, id int64) (*entity.PostReview, error)
+	ListByStatus(ctx context.Context, status entity.ReviewStatus, req pagination.Request) (pagination.Page[entity.PostReview], error)
+	// Resolve moves a pending review to its final status. It fails with
+	// ErrNotFound if the review is not pending, so two moderators can't both
+	// act on it.
+	Resolve(ctx context.Context, id int64, status entity.ReviewStatus, by int) error
+	// Reopen puts a resolved review back into the queue.
+	Reopen(ctx context.Context, id int64) error
+}
+
+const reviewColumns = "id, author_id, category_id, topic_id, reply_to_id, title, body, attachment_ids, verdict, reasons, status, reviewed_by, created_at, reviewed_at"
+
+var reviewKeyset = keyset{column: "id", id: "id", desc: true}
+
+type SQLiteReviewRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReviewRepository(db *sql.DB) ReviewRepository {
+	return &SQLiteReviewRepository{db: db}
+}
+
+func (r *SQLiteReviewRepository) Create(ctx context.Context, review *entity.PostReview) error {
+	review.CreatedAt = time.Now().UTC()
+
+	attachmentIDs, err := json.Marshal(review.AttachmentIDs)
+	if err != nil {
+		return err
+	}
+	reasons, err := json.Marshal(review.Reasons)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO post_reviews (author_id, category_id, topic_id, reply_to_id, title, body, attachment_ids, verdict, reasons, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		review.AuthorID, review.CategoryID, review.TopicID, review.ReplyToID, review.Title, review.Body,
+		string(attachmentIDs), review.Verdict, string(reasons), review.Status, review.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	review.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteReviewRepository) GetByID(ctx context.Context, id int64) (*entity.PostReview, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+reviewColumns+" FROM post_reviews WHERE id = ?", id)
+	review, err := scanReview(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return review, nil
+}
+
+func (r *SQLiteReviewRepository) ListByStatus(ctx context.Context, status entity.ReviewStatus, req pagination.Request) (pagination.Page[entity.PostReview], error) {
+	where, args := reviewKeyset.after(req.After)
+	query := "SELECT " + reviewColumns + " FROM post_reviews WHERE status = ?" + where + reviewKeyset.orderBy() + " LIMIT ?"
+	args = append([]any{status}, args...)
+	args = append(args, req.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[entity.PostReview]{}, err
+	}
+	defer rows.Close()
+
+	var reviews []entity.PostReview
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return pagination.Page[entity.PostReview]{}, err
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[entity.PostReview]{}, err
+	}
+	return pagination.NewPage(reviews, req, func(r entity.PostReview) pagination.Cursor {
+		return pagination.Cursor{Sort: req.Sort, Key: r.ID, ID: r.ID}
+	}), nil
+}
+
+func (r *SQLiteReviewRepository) Resolve(ctx context.Context, id int64, status entity.ReviewStatus, by int) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE post_reviews SET status = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ? AND status = ?",
+		status, by, time.Now().UTC(), id, entity.ReviewPending)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteReviewRepository) Reopen(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE post_reviews SET status = ?, reviewed_by = 0, reviewed_at = NULL WHERE id = ?",
+		entity.ReviewPending, id)
+	return err
+}
+
+func scanReview(row rowScanner) (*entity.PostReview, error) {
+	var r entity.PostReview
+	var attachmentIDs, reasons string
+	var reviewedAt sql.NullTime
+	err := row.Scan(&r.ID, &r.AuthorID, &r.CategoryID, &r.TopicID, &r.ReplyToID, &r.Title, &r.Body,
+		&attachmentIDs, &r.Verdict, &reasons, &r.Status, &r.ReviewedBy, &r.CreatedAt, &reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(attachmentIDs), &r.AttachmentIDs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(reasons), &r.Reasons); err != nil {
+		return nil, err
+	}
+	r.ReviewedAt = nullTime(reviewedAt)
+	return &r, nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type ScheduleRepository interface {
+	Create(ctx context.Context, s *entity.ScheduledPost) error
+	GetByID(ctx context.Context, id int64) (*entity.ScheduledPost, error)
+	// ListByAuthor returns the author's items that are not published yet,
+	// the next one first.
+	ListByAuthor(ctx context.Context, authorID int) ([]entity.ScheduledPost, error)
+	// Reschedule moves a pending or failed item to a new time and makes it
+	// pending again.
+	Reschedule(ctx context.Context, id int64, authorID int, at time.Time) error
+	// Delete cancels an item that is not being or has not been published.
+	Delete(ctx context.Context, id int64, authorID int) error
+	// ClaimDue marks up to limit due items as publishing and returns them.
+	// Items left publishing since before staleBefore are claimed again, so
+	// work interrupted by a restart is picked up.
+	ClaimDue(ctx context.Context, now, staleBefore time.Time, limit int) ([]entity.ScheduledPost, error)
+	// SetTopic records the topic a new-topic item opened before its first
+	// post was stored, so a retry adds the post instead of opening another
+	// topic. Items are marked published by PostRepository.Create.
+	SetTopic(ctx context.Context, id int64, topicID int64) error
+	// MarkFailed ends a claim. A non-zero retryAt puts the item back to
+	// pending, due at that time, instead of failing it for good. Items no
+	// longer publishing, such as ones published already, are left alone.
+	MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error
+}
+
+const scheduleColumns = "id, author_id, category_id, topic_id, reply_to_id, title, body, tags, publish_at, status, attempts, post_id, error, created_at"
+
+type SQLiteScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteScheduleRepository(db *sql.DB) ScheduleRepository {
+	return &SQLiteScheduleRepository{db: db}
+}
+
+func (r *SQLiteScheduleRepository) Create(ctx context.Context, s *entity.ScheduledPost) error {
+	s.CreatedAt = time.Now().UTC()
+	s.PublishAt = s.PublishAt.UTC()
+	s.Status = entity.SchedulePending
+
+	tags, err := json.Marshal(s.Tags)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO scheduled_posts (author_id, category_id, topic_id, reply_to_id, title, body, tags, publish_at, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.AuthorID, s.CategoryID, s.TopicID, s.ReplyToID, s.Title, s.Body, string(tags), s.PublishAt, s.Status, s.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	s.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteScheduleRepository) GetByID(ctx context.Context, id int64) (*entity.ScheduledPost, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+scheduleColumns+" FROM scheduled_posts WHERE id = ?", id)
+	s, err := scanScheduledPost(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return s, nil
+}
+
+func (r *SQLiteScheduleRepository) ListByAuthor(ctx context.Context, authorID int) ([]entity.ScheduledPost, error) {
+	return r.query(ctx,
+		"SELECT "+scheduleColumns+" FROM scheduled_posts WHERE author_id = ? AND status != ? ORDER BY publish_at, id",
+		authorID, entity.SchedulePublished)
+}
+
+func (r *SQLiteScheduleRepository) Reschedule(ctx context.Context, id int64, authorID int, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE scheduled_posts SET publish_at = ?, status = ?, attempts = 0, error = ''
+		WHERE id = ? AND author_id = ? AND status IN (?, ?)`,
+		at.UTC(), entity.SchedulePending, id, authorID, entity.SchedulePending, entity.ScheduleFailed)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteScheduleRepository) Delete(ctx context.Context, id int64, authorID int) error {
+	res, err := r.db.ExecContext(ctx,
+		"DELETE FROM scheduled_posts WHERE id = ? AND author_id = ? AND status IN (?, ?)",
+		id, authorID, entity.SchedulePending, entity.ScheduleFailed)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimDue selects and marks the items in one statement, so two schedulers
+// never claim the same item.
+func (r *SQLiteScheduleRepository) ClaimDue(ctx context.Context, now, staleBefore time.Time, limit int) ([]entity.ScheduledPost, error) {
+	return r.query(ctx, `
+		UPDATE scheduled_posts SET status = ?, claimed_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM scheduled_posts
+			WHERE (status = ? AND publish_at <= ?) OR (status = ? AND claimed_at < ?)
+			ORDER BY publish_at, id LIMIT ?
+		)
+		RETURNING `+scheduleColumns,
+		entity.SchedulePublishing, now.UTC(),
+		entity.SchedulePending, now.UTC(), entity.SchedulePublishing, staleBefore.UTC(), limit)
+}
+
+func (r *SQLiteScheduleRepository) SetTopic(ctx context.Context, id int64, topicID int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE scheduled_posts SET topic_id = ? WHERE id = ?", topicID, id)
+	return err
+}
+
+func (r *SQLiteScheduleRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	status, publishAt := entity.ScheduleFailed, any(nil)
+	if !retryAt.IsZero() {
+		status, publishAt = entity.SchedulePending, retryAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE scheduled_posts SET status = ?, error = ?, claimed_at = NULL, publish_at = COALESCE(?, publish_at) WHERE id = ? AND status = ?",
+		status, reason, publishAt, id, entity.SchedulePublishing)
+	return err
+}
+
+func (r *SQLiteScheduleRepository) query(ctx context.Context, query string, args ...any) ([]entity.ScheduledPost, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []entity.ScheduledPost{}
+	for rows.Next() {
+		s, err := scanScheduledPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *s)
+	}
+	return items, rows.Err()
+}
+
+func scanScheduledPost(row rowScanner) (*entity.ScheduledPost, error) {
+	var s entity.ScheduledPost
+	var tags string
+	err := row.Scan(&s.ID, &s.AuthorID, &s.CategoryID, &s.TopicID, &s.ReplyToID, &s.Title, &s.Body, &tags,
+		&s.PublishAt, &s.Status, &s.Attempts, &s.PostID, &s.Error, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &s.Tags); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
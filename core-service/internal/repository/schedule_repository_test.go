@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+func TestSchedulePublishedWithPost(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	categories := NewSQLiteCategoryRepository(db)
+	topics := NewSQLiteTopicRepository(db)
+	posts := NewSQLitePostRepository(db)
+	schedule := NewSQLiteScheduleRepository(db)
+
+	category := &entity.Category{Name: "general"}
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	topic := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "topic"}
+	if err := topics.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+	item := &entity.ScheduledPost{AuthorID: 1, TopicID: topic.ID, Body: "later", PublishAt: time.Now().Add(-time.Second)}
+	if err := schedule.Create(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if claimed, err := schedule.ClaimDue(ctx, now, now.Add(-time.Hour), 10); err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimDue = %v, %v", claimed, err)
+	}
+	post := &entity.Post{TopicID: topic.ID, AuthorID: 1, Body: "later", ScheduledID: item.ID}
+	if err := posts.Create(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+
+	// Even once the claim has gone stale, the item is not published twice
+	later := now.Add(time.Hour)
+	if claimed, err := schedule.ClaimDue(ctx, later, later, 10); err != nil || len(claimed) != 0 {
+		t.Errorf("ClaimDue after publishing = %v, %v; want nothing", claimed, err)
+	}
+	// A failure reported after the post was stored leaves it published
+	if err := schedule.MarkFailed(ctx, item.ID, "late error", later); err != nil {
+		t.Fatal(err)
+	}
+	got, err := schedule.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != entity.SchedulePublished || got.PostID != post.ID || got.Error != "" {
+		t.Errorf("item = %+v, want published as post %d", got, post.ID)
+	}
+}
+
+func TestScheduleMarkFailed(t *testing.T) {
+	ctx := context.Background()
+	schedule := NewSQLiteScheduleRepository(openTestDB(t))
+
+	publishAt := time.Now().Add(-time.Second).Truncate(time.Second)
+	item := &entity.ScheduledPost{AuthorID: 1, TopicID: 1, Body: "later", PublishAt: publishAt}
+	if err := schedule.Create(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	claim := func(now time.Time) int {
+		t.Helper()
+		claimed, err := schedule.ClaimDue(ctx, now, now.Add(-time.Hour), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(claimed)
+	}
+
+	now := time.Now()
+	if n := claim(now); n != 1 {
+		t.Fatalf("claimed %d items, want 1", n)
+	}
+	retryAt := now.Add(time.Minute)
+	if err := schedule.MarkFailed(ctx, item.ID, "busy", retryAt); err != nil {
+		t.Fatal(err)
+	}
+	if n := claim(now); n != 0 {
+		t.Errorf("retried item claimed again at once")
+	}
+	if n := claim(retryAt); n != 1 {
+		t.Fatalf("retried item not claimed when due")
+	}
+
+	// Without a retry time the item fails for good where it was
+	if err := schedule.MarkFailed(ctx, item.ID, "gone", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := schedule.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != entity.ScheduleFailed || got.Error != "gone" || !got.PublishAt.Equal(retryAt.UTC()) {
+		t.Errorf("item = %+v, want failed and due at %v", got, retryAt)
+	}
+	if n := claim(retryAt.Add(time.Hour)); n != 0 {
+		t.Errorf("failed item claimed again")
+	}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// TokenCount is how many spam and ham documents a token appeared in.
+type TokenCount struct {
+	Spam int
+	Ham  int
+}
+
+// Corpus is the number of documents the classifier was trained on.
+type Corpus struct {
+	SpamDocs int
+	HamDocs  int
+}
+
+type SpamRepository interface {
+	RecordFingerprint(ctx context.Context, fingerprint string, authorID int) error
+	// CountFingerprint returns how often a fingerprint was seen since the
+	// given time, from the author and from everybody else.
+	CountFingerprint(ctx context.Context, fingerprint string, authorID int, since time.Time) (own, others int, err error)
+	PruneFingerprints(ctx context.Context, before time.Time) error
+	TokenCounts(ctx context.Context, tokens []string) (map[string]TokenCount, Corpus, error)
+	// Train adds one document, given as its distinct tokens, to the corpus.
+	Train(ctx context.Context, tokens []string, spam bool) error
+}
+
+type SQLiteSpamRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSpamRepository(db *sql.DB) SpamRepository {
+	return &SQLiteSpamRepository{db: db}
+}
+
+func (r *SQLiteSpamRepository) RecordFingerprint(ctx context.Context, fingerprint string, authorID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO spam_fingerprints (fingerprint, author_id, created_at) VALUES (?, ?, ?)",
+		fingerprint, authorID, time.Now().UTC())
+	return err
+}
+
+func (r *SQLiteSpamRepository) CountFingerprint(ctx context.Context, fingerprint string, authorID int, since time.Time) (int, int, error) {
+	var own, others int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(author_id = ?), 0), COALESCE(SUM(author_id != ?), 0)
+		FROM spam_fingerprints WHERE fingerprint = ? AND created_at >= ?`,
+		authorID, authorID, fingerprint, since.UTC()).Scan(&own, &others)
+	return own, others, err
+}
+
+func (r *SQLiteSpamRepository) PruneFingerprints(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM spam_fingerprints WHERE created_at < ?", before.UTC())
+	return err
+}
+
+func (r *SQLiteSpamRepository) TokenCounts(ctx context.Context, tokens []string) (map[string]TokenCount, Corpus, error) {
+	var corpus Corpus
+	err := r.db.QueryRowContext(ctx, "SELECT spam_docs, ham_docs FROM spam_corpus WHERE id = 1").
+		Scan(&corpus.SpamDocs, &corpus.HamDocs)
+	if err != nil {
+		return nil, Corpus{}, err
+	}
+
+	counts := make(map[string]TokenCount, len(tokens))
+	if len(tokens) == 0 {
+		return counts, corpus, nil
+	}
+
+	args := make([]any, len(tokens))
+	for i, t := range tokens {
+		args[i] = t
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT token, spam, ham FROM spam_tokens WHERE token IN ("+placeholders(len(tokens))+")", args...)
+	if err != nil {
+		return nil, Corpus{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var token string
+		var c TokenCount
+		if err := rows.Scan(&token, &c.Spam, &c.Ham); err != nil {
+			return nil, Corpus{}, err
+		}
+		counts[token] = c
+	}
+	return counts, corpus, rows.Err()
+}
+
+func (r *SQLiteSpamRepository) Train(ctx context.Context, tokens []string, spam bool) error {
+	column := "ham"
+	if spam {
+		column = "spam"
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, token := range tokens {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO spam_tokens (token, "+column+") VALUES (?, 1) ON CONFLICT (token) DO UPDATE SET "+column+" = "+column+" + 1",
+			token)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE spam_corpus SET "+column+"_docs = "+column+"_docs + 1 WHERE id = 1"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"io/fs"
+	"log"
+	"strings"
+	"time"
+
+	"forum-app/pkg/migrate"
+	_ "github.com/glebarez/sqlite" // SQLite driver
+)
+
+var ErrNotFound = errors.New("not found")
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// OpenSQLiteDB opens the database shared by all core-service repositories and
+// migrates it to the latest schema. Schema changes go into a new file under
+// migrations; applied migrations must never be edited.
+func OpenSQLiteDB(dbFilePath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbFilePath+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return db, nil
+}
+
+// NewMigrator returns a migrator for the core-service's embedded migrations.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, sub)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// placeholders returns "?,?,...,?" for an IN clause with n values.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// softDelete marks a row of a table with deleted_at/deleted_by columns as
+// deleted. Deleting an already deleted row is reported as not found.
+func softDelete(ctx context.Context, db *sql.DB, table string, id int64, by int) error {
+	res, err := db.ExecContext(ctx,
+		"UPDATE "+table+" SET deleted_at = ?, deleted_by = ? WHERE id = ? AND deleted_at IS NULL",
+		time.Now().UTC(), by, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func restore(ctx context.Context, db *sql.DB, table string, id int64) error {
+	res, err := db.ExecContext(ctx,
+		"UPDATE "+table+" SET deleted_at = NULL, deleted_by = 0 WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func deletedBefore(ctx context.Context, db *sql.DB, table string, before time.Time, limit int) ([]int64, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT id FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY id LIMIT ?",
+		before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func int64Args(ids []int64) []any {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
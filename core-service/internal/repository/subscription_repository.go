@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type SubscriptionRepository interface {
+	Subscribe(ctx context.Context, sub *entity.Subscription) error
+	Unsubscribe(ctx context.Context, sub *entity.Subscription) error
+	// Watchers returns the users watching the topic or its category.
+	Watchers(ctx context.Context, topicID, categoryID int64) ([]int, error)
+}
+
+type SQLiteSubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSubscriptionRepository(db *sql.DB) SubscriptionRepository {
+	return &SQLiteSubscriptionRepository{db: db}
+}
+
+func (r *SQLiteSubscriptionRepository) Subscribe(ctx context.Context, sub *entity.Subscription) error {
+	sub.CreatedAt = time.Now().UTC()
+	_, err := r.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO subscriptions (user_id, topic_id, category_id, created_at) VALUES (?, ?, ?, ?)",
+		sub.UserID, sub.TopicID, sub.CategoryID, sub.CreatedAt)
+	return err
+}
+
+func (r *SQLiteSubscriptionRepository) Unsubscribe(ctx context.Context, sub *entity.Subscription) error {
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM subscriptions WHERE user_id = ? AND topic_id = ? AND category_id = ?",
+		sub.UserID, sub.TopicID, sub.CategoryID)
+	return err
+}
+
+func (r *SQLiteSubscriptionRepository) Watchers(ctx context.Context, topicID, categoryID int64) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT user_id FROM subscriptions
+		WHERE (topic_id = ? AND category_id = 0) OR (category_id = ? AND topic_id = 0)`,
+		topicID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		users = append(users, id)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"core-service/internal/entity"
+)
+
+type TagRepository interface {
+	GetByName(ctx context.Context, name string) (*entity.Tag, error)
+	// Canonicalize maps every name that is a synonym to the tag it stands for
+	// and leaves all other names untouched.
+	Canonicalize(ctx context.Context, names []string) ([]string, error)
+	// SetTopicTags replaces a topic's tags, creating tags that don't exist yet.
+	SetTopicTags(ctx context.Context, topicID int64, names []string) error
+	ListByTopics(ctx context.Context, topicIDs []int64) (map[int64][]string, error)
+	// Search returns tags whose name or one of its synonyms starts with prefix,
+	// most used first.
+	Search(ctx context.Context, prefix string, limit int) ([]entity.Tag, error)
+	// Cloud returns the most used tags with their usage counts.
+	Cloud(ctx context.Context, limit int) ([]entity.Tag, error)
+	AddSynonym(ctx context.Context, tagID int64, name string) error
+	RemoveSynonym(ctx context.Context, name string) error
+	// Merge moves all topics and synonyms of one tag to another and keeps the
+	// old name as a synonym of the target.
+	Merge(ctx context.Context, fromID, intoID int64) error
+}
+
+// tagColumns counts only topics that haven't been deleted.
+const tagColumns = `t.id, t.name, t.created_at,
+	(SELECT COUNT(*) FROM topic_tags tt JOIN topics p ON p.id = tt.topic_id
+	 WHERE tt.tag_id = t.id AND p.deleted_at IS NULL) AS topic_count`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type SQLiteTagRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteTagRepository(db *sql.DB) TagRepository {
+	return &SQLiteTagRepository{db: db}
+}
+
+func (r *SQLiteTagRepository) GetByName(ctx context.Context, name string) (*entity.Tag, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+tagColumns+" FROM tags t WHERE t.name = ?", name)
+	tag, err := scanTag(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT name FROM tag_synonyms WHERE tag_id = ? ORDER BY name", tag.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var synonym string
+		if err := rows.Scan(&synonym); err != nil {
+			return nil, err
+		}
+		tag.Synonyms = append(tag.Synonyms, synonym)
+	}
+	return tag, rows.Err()
+}
+
+func (r *SQLiteTagRepository) Canonicalize(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return names, nil
+	}
+
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT s.name, t.name FROM tag_synonyms s JOIN tags t ON t.id = s.tag_id WHERE s.name IN ("+placeholders(len(names))+")",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	canonical := make(map[string]string)
+	for rows.Next() {
+		var synonym, name string
+		if err := rows.Scan(&synonym, &name); err != nil {
+			return nil, err
+		}
+		canonical[synonym] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(names))
+	for i, name := range names {
+		if c, ok := canonical[name]; ok {
+			name = c
+		}
+		result[i] = name
+	}
+	return result, nil
+}
+
+func (r *SQLiteTagRepository) SetTopicTags(ctx context.Context, topicID int64, names []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM topic_tags WHERE topic_id = ?", topicID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO tags (name, created_at) VALUES (?, ?)", name, now); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO topic_tags (topic_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			topicID, name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteTagRepository) ListByTopics(ctx context.Context, topicIDs []int64) (map[int64][]string, error) {
+	result := make(map[int64][]string)
+	if len(topicIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tt.topic_id, t.name FROM topic_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.topic_id IN (`+placeholders(len(topicIDs))+`) ORDER BY t.name`,
+		int64Args(topicIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var topicID int64
+		var name string
+		if err := rows.Scan(&topicID, &name); err != nil {
+			return nil, err
+		}
+		result[topicID] = append(result[topicID], name)
+	}
+	return result, rows.Err()
+}
+
+func (r *SQLiteTagRepository) Search(ctx context.Context, prefix string, limit int) ([]entity.Tag, error) {
+	pattern := likeEscaper.Replace(prefix) + "%"
+	return r.query(ctx, `
+		SELECT `+tagColumns+` FROM tags t
+		WHERE t.name LIKE ? ESCAPE '\'
+		   OR t.id IN (SELECT tag_id FROM tag_synonyms WHERE name LIKE ? ESCAPE '\')
+		ORDER BY topic_count DESC, t.name LIMIT ?`,
+		pattern, pattern, limit)
+}
+
+func (r *SQLiteTagRepository) Cloud(ctx context.Context, limit int) ([]entity.Tag, error) {
+	return r.query(ctx, `
+		SELECT * FROM (SELECT `+tagColumns+` FROM tags t)
+		WHERE topic_count > 0
+		ORDER BY topic_count DESC, name LIMIT ?`,
+		limit)
+}
+
+func (r *SQLiteTagRepository) AddSynonym(ctx context.Context, tagID int64, name string) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO tag_synonyms (name, tag_id) VALUES (?, ?)", name, tagID)
+	return err
+}
+
+func (r *SQLiteTagRepository) RemoveSynonym(ctx context.Context, name string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM tag_synonyms WHERE name = ?", name)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *SQLiteTagRepository) Merge(ctx context.Context, fromID, intoID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"INSERT OR IGNORE INTO topic_tags (topic_id, tag_id) SELECT topic_id, ? FROM topic_tags WHERE tag_id = ?",
+		"UPDATE tag_synonyms SET tag_id = ? WHERE tag_id = ?",
+		"INSERT INTO tag_synonyms (name, tag_id) SELECT name, ? FROM tags WHERE id = ?",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt, intoID, fromID); err != nil {
+			return err
+		}
+	}
+	// Deleting the tag cascades to the topic_tags rows that were copied above.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE id = ?", fromID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteTagRepository) query(ctx context.Context, query string, args ...any) ([]entity.Tag, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []entity.Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, rows.Err()
+}
+
+func scanTag(row rowScanner) (*entity.Tag, error) {
+	var t entity.Tag
+	if err := row.Scan(&t.ID, &t.Name, &t.CreatedAt, &t.TopicCount); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
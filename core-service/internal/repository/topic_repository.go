@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+)
+
+// TopicFilter narrows topic listings down for Q&A categories.
+type TopicFilter string
+
+const (
+	TopicsAll      TopicFilter = ""
+	TopicsSolved   TopicFilter = "solved"
+	TopicsUnsolved TopicFilter = "unsolved"
+	// TopicsUnanswered are questions without any reply yet.
+	TopicsUnanswered TopicFilter = "unanswered"
+)
+
+var topicFilters = map[TopicFilter]string{
+	TopicsAll:      "",
+	TopicsSolved:   " AND accepted_post_id != 0",
+	TopicsUnsolved: " AND accepted_post_id = 0",
+	TopicsUnanswered: ` AND accepted_post_id = 0 AND (SELECT COUNT(*) FROM posts p
+		WHERE p.topic_id = topics.id AND p.deleted_at IS NULL) <= 1`,
+}
+
+type TopicRepository interface {
+	Create(ctx context.Context, topic *entity.Topic) error
+	GetByID(ctx context.Context, id int64) (*entity.Topic, error)
+	ListByCategory(ctx context.Context, categoryID int64, filter TopicFilter, req pagination.Request) (pagination.Page[entity.Topic], error)
+	// ListUnanswered lists unanswered questions across all Q&A categories.
+	ListUnanswered(ctx context.Context, req pagination.Request) (pagination.Page[entity.Topic], error)
+	// ListUnread lists topics the user has read before that got new posts
+	// since.
+	ListUnread(ctx context.Context, userID int, req pagination.Request) (pagination.Page[entity.Topic], error)
+	// SetAcceptedAnswer marks a post as the topic's answer; 0 clears it.
+	SetAcceptedAnswer(ctx context.Context, id int64, postID int64) error
+	ListByTag(ctx context.Context, tagID int64, req pagination.Request) (pagination.Page[entity.Topic], error)
+	ListByAuthor(ctx context.Context, authorID int, req pagination.Request) (pagination.Page[entity.Topic], error)
+	SoftDelete(ctx context.Context, id int64, by int) error
+	Restore(ctx context.Context, id int64) error
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]int64, error)
+	// Purge permanently removes a topic row; its posts must be purged first.
+	Purge(ctx context.Context, id int64) error
+}
+
+var topicSorts = map[pagination.Sort]keyset{
+	pagination.SortNewest:   {column: "id", id: "id", desc: true},
+	pagination.SortActivity: {column: "last_activity_at", id: "id", desc: true, isTime: true},
+	pagination.SortScore:    {column: "score", id: "id", desc: true},
+}
+
+const topicColumns = "id, category_id, author_id, title, post_count, score, accepted_post_id, created_at, last_activity_at, deleted_at, deleted_by"
+
+type SQLiteTopicRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteTopicRepository(db *sql.DB) TopicRepository {
+	return &SQLiteTopicRepository{db: db}
+}
+
+func (r *SQLiteTopicRepository) Create(ctx context.Context, topic *entity.Topic) error {
+	now := time.Now().UTC()
+	topic.CreatedAt = now
+	topic.LastActivityAt = now
+
+	res, err := r.db.ExecContext(ctx,
+		"INSERT INTO topics (category_id, author_id, title, created_at, last_activity_at) VALUES (?, ?, ?, ?, ?)",
+		topic.CategoryID, topic.AuthorID, topic.Title, topic.CreatedAt, topic.LastActivityAt)
+	if err != nil {
+		return err
+	}
+
+	topic.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *SQLiteTopicRepository) GetByID(ctx context.Context, id int64) (*entity.Topic, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+topicColumns+" FROM topics WHERE id = ?", id)
+	topic, err := scanTopic(row)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return topic, nil
+}
+
+func (r *SQLiteTopicRepository) ListByCategory(ctx context.Context, categoryID int64, filter TopicFilter, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	condition, ok := topicFilters[filter]
+	if !ok {
+		return pagination.Page[entity.Topic]{}, fmt.Errorf("unknown topic filter %q", filter)
+	}
+	return r.list(ctx, "category_id = ?"+condition, categoryID, req)
+}
+
+func (r *SQLiteTopicRepository) ListUnanswered(ctx context.Context, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	return r.list(ctx, "category_id IN (SELECT id FROM categories WHERE qa_mode = ?)"+topicFilters[TopicsUnanswered], true, req)
+}
+
+func (r *SQLiteTopicRepository) ListUnread(ctx context.Context, userID int, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	return r.list(ctx, `id IN (SELECT r.topic_id FROM topic_reads r WHERE r.user_id = ? AND EXISTS (
+		SELECT 1 FROM posts p WHERE p.topic_id = r.topic_id AND p.id > r.last_read_post_id AND p.deleted_at IS NULL))`,
+		userID, req)
+}
+
+func (r *SQLiteTopicRepository) ListByAuthor(ctx context.Context, authorID int, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	return r.list(ctx, "author_id = ?", authorID, req)
+}
+
+func (r *SQLiteTopicRepository) ListByTag(ctx context.Context, tagID int64, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	return r.list(ctx, "id IN (SELECT topic_id FROM topic_tags WHERE tag_id = ?)", tagID, req)
+}
+
+func (r *SQLiteTopicRepository) SetAcceptedAnswer(ctx context.Context, id int64, postID int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE topics SET accepted_post_id = ? WHERE id = ?", postID, id)
+	return err
+}
+
+func (r *SQLiteTopicRepository) list(ctx context.Context, filter string, value any, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	k, err := sortFor(topicSorts, req.Sort)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	where, args := k.after(req.After)
+	query := "SELECT " + topicColumns + " FROM topics WHERE " + filter + " AND deleted_at IS NULL" + where + k.orderBy() + " LIMIT ?"
+	args = append([]any{value}, args...)
+	args = append(args, req.Limit+1)
+
+	topics, err := r.query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	return pagination.NewPage(topics, req, func(t entity.Topic) pagination.Cursor {
+		return topicCursor(t, req.Sort)
+	}), nil
+}
+
+func (r *SQLiteTopicRepository) SoftDelete(ctx context.Context, id int64, by int) error {
+	return softDelete(ctx, r.db, "topics", id, by)
+}
+
+func (r *SQLiteTopicRepository) Restore(ctx context.Context, id int64) error {
+	return restore(ctx, r.db, "topics", id)
+}
+
+func (r *SQLiteTopicRepository) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	return deletedBefore(ctx, r.db, "topics", before, limit)
+}
+
+func (r *SQLiteTopicRepository) Purge(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM subscriptions WHERE topic_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM topics WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteTopicRepository) query(ctx context.Context, query string, args ...any) ([]entity.Topic, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []entity.Topic
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, *topic)
+	}
+	return topics, rows.Err()
+}
+
+func scanTopic(row rowScanner) (*entity.Topic, error) {
+	var t entity.Topic
+	var deletedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.CategoryID, &t.AuthorID, &t.Title, &t.PostCount, &t.Score, &t.AcceptedPostID, &t.CreatedAt, &t.LastActivityAt,
+		&deletedAt, &t.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	t.DeletedAt = nullTime(deletedAt)
+	return &t, nil
+}
+
+func topicCursor(t entity.Topic, sort pagination.Sort) pagination.Cursor {
+	c := pagination.Cursor{Sort: sort, Key: t.ID, ID: t.ID}
+	switch sort {
+	case pagination.SortActivity:
+		c.Key = t.LastActivityAt.UnixNano()
+	case pagination.SortScore:
+		c.Key = int64(t.Score)
+	}
+	return c
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type VoteRepository interface {
+	// Vote sets a user's vote on a post to +1 or -1, or removes it for 0, and
+	// returns the post's new score.
+	Vote(ctx context.Context, postID int64, userID int, value int) (int, error)
+}
+
+type SQLiteVoteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteVoteRepository(db *sql.DB) VoteRepository {
+	return &SQLiteVoteRepository{db: db}
+}
+
+func (r *SQLiteVoteRepository) Vote(ctx context.Context, postID int64, userID int, value int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if value == 0 {
+		_, err = tx.ExecContext(ctx, "DELETE FROM post_votes WHERE post_id = ? AND user_id = ?", postID, userID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO post_votes (post_id, user_id, value, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (post_id, user_id) DO UPDATE SET value = excluded.value`,
+			postID, userID, value, time.Now().UTC())
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Recounting instead of adding the difference keeps the score exact even
+	// if it ever drifted.
+	var score int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE posts SET score = (SELECT COALESCE(SUM(value), 0) FROM post_votes WHERE post_id = ?)
+		WHERE id = ? RETURNING score`,
+		postID, postID).Scan(&score)
+	if err != nil {
+		return 0, notFound(err)
+	}
+	return score, tx.Commit()
+}
@@ -0,0 +1,94 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"core-service/internal/repository"
+)
+
+const (
+	// The classifier stays silent until it has seen this many documents of
+	// each class; with less data its scores are noise.
+	minTrainingDocs = 10
+	maxTokens       = 500
+)
+
+var tokenPattern = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}'.-]{1,29}`)
+
+// Tokenize returns the distinct lowercase words of a text.
+func Tokenize(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range tokenPattern.FindAllString(strings.ToLower(text), -1) {
+		token = strings.TrimRight(token, "'.-")
+		if len(token) < 2 || seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+		if len(tokens) == maxTokens {
+			break
+		}
+	}
+	return tokens
+}
+
+// BayesCheck is a naive Bayes classifier trained from moderator decisions:
+// posts marked as spam count as spam, held posts that get approved as ham.
+type BayesCheck struct {
+	spamRepo  repository.SpamRepository
+	threshold float64
+}
+
+func NewBayesCheck(spamRepo repository.SpamRepository, threshold float64) *BayesCheck {
+	return &BayesCheck{spamRepo: spamRepo, threshold: threshold}
+}
+
+func (c *BayesCheck) Name() string { return "classifier" }
+
+func (c *BayesCheck) Check(ctx context.Context, s *Submission) (Result, error) {
+	p, err := c.SpamProbability(ctx, s.text())
+	if err != nil {
+		return Result{}, err
+	}
+	if p >= c.threshold {
+		return Result{Verdict: Hold, Reason: fmt.Sprintf("looks like spam (%.0f%%)", p*100)}, nil
+	}
+	return allow(), nil
+}
+
+// SpamProbability scores text between 0 and 1. It returns 0 while the
+// classifier is not trained enough.
+func (c *BayesCheck) SpamProbability(ctx context.Context, text string) (float64, error) {
+	tokens := Tokenize(text)
+	counts, corpus, err := c.spamRepo.TokenCounts(ctx, tokens)
+	if err != nil {
+		return 0, err
+	}
+	if corpus.SpamDocs < minTrainingDocs || corpus.HamDocs < minTrainingDocs {
+		return 0, nil
+	}
+
+	// Sum log-likelihoods of the tokens seen in training, with Laplace
+	// smoothing; unknown tokens carry no information and are skipped.
+	spamDocs, hamDocs := float64(corpus.SpamDocs), float64(corpus.HamDocs)
+	logSpam := math.Log(spamDocs / (spamDocs + hamDocs))
+	logHam := math.Log(hamDocs / (spamDocs + hamDocs))
+	for _, token := range tokens {
+		count, ok := counts[token]
+		if !ok {
+			continue
+		}
+		logSpam += math.Log((float64(count.Spam) + 1) / (spamDocs + 2))
+		logHam += math.Log((float64(count.Ham) + 1) / (hamDocs + 2))
+	}
+	return 1 / (1 + math.Exp(logHam-logSpam)), nil
+}
+
+func (c *BayesCheck) Train(ctx context.Context, text string, spam bool) error {
+	return c.spamRepo.Train(ctx, Tokenize(text), spam)
+}
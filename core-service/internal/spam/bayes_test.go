@@ -0,0 +1,74 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Don't BUY cheap-pills... a B2B deal, buy now!")
+	want := []string{"don't", "buy", "cheap-pills", "b2b", "deal", "now"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %q, want %q", got, want)
+	}
+}
+
+func TestBayesCheck(t *testing.T) {
+	ctx := context.Background()
+	check := NewBayesCheck(openSpamRepository(t), 0.9)
+	probability := func(text string) float64 {
+		t.Helper()
+		p, err := check.SpamProbability(ctx, text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	train := func(text string, spam bool) {
+		t.Helper()
+		if err := check.Train(ctx, text, spam); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const spamText = "cheap pills casino bonus, click now"
+	const hamText = "the build fails after upgrading the compiler"
+
+	// Silent until both classes have enough examples
+	for i := range minTrainingDocs {
+		train(fmt.Sprintf("cheap pills and casino bonus %d, click now", i), true)
+	}
+	for i := range minTrainingDocs - 1 {
+		train(fmt.Sprintf("how do I fix the build after upgrading the compiler %d", i), false)
+	}
+	if p := probability(spamText); p != 0 {
+		t.Errorf("probability with too little ham = %v, want 0", p)
+	}
+	train("how do I fix the build after upgrading the compiler", false)
+
+	if p := probability(spamText); p < 0.9 {
+		t.Errorf("probability of spam = %v, want at least 0.9", p)
+	}
+	if p := probability(hamText); p > 0.1 {
+		t.Errorf("probability of ham = %v, want at most 0.1", p)
+	}
+	// With balanced classes, unknown words say nothing either way
+	if p := probability("völlig unbekannte wörter"); p != 0.5 {
+		t.Errorf("probability of unknown words = %v, want 0.5", p)
+	}
+
+	for _, tc := range []struct {
+		text string
+		want Verdict
+	}{{spamText, Hold}, {hamText, Allow}} {
+		result, err := check.Check(ctx, &Submission{Body: tc.text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Verdict != tc.want {
+			t.Errorf("Check(%q) = %v, want %v", tc.text, result.Verdict, tc.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package spam
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"core-service/internal/repository"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>]+`)
+
+// CountLinks returns the number of links in text and their total length.
+func CountLinks(text string) (count, length int) {
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		count++
+		length += len(link)
+	}
+	return count, length
+}
+
+// LinkCheck holds posts with more links than allowed, or whose text is
+// mostly links.
+type LinkCheck struct {
+	maxLinks   int
+	maxDensity float64
+}
+
+func NewLinkCheck(maxLinks int, maxDensity float64) *LinkCheck {
+	return &LinkCheck{maxLinks: maxLinks, maxDensity: maxDensity}
+}
+
+func (c *LinkCheck) Name() string { return "links" }
+
+func (c *LinkCheck) Check(_ context.Context, s *Submission) (Result, error) {
+	text := s.text()
+	count, length := CountLinks(text)
+	if count > c.maxLinks {
+		return Result{Verdict: Hold, Reason: fmt.Sprintf("%d links, at most %d allowed", count, c.maxLinks)}, nil
+	}
+	if count > 1 && float64(length)/float64(len(text)) > c.maxDensity {
+		return Result{Verdict: Hold, Reason: "post consists mostly of links"}, nil
+	}
+	return allow(), nil
+}
+
+const minDuplicateLength = 20
+
+// DuplicateCheck catches the same text being posted again. Authors repeating
+// themselves are rejected; text posted by several accounts is held, as that
+// is how spam runs usually look. Only published posts are remembered, so an
+// author may retry a post that was held or failed to be stored.
+type DuplicateCheck struct {
+	spamRepo repository.SpamRepository
+	window   time.Duration
+}
+
+func NewDuplicateCheck(spamRepo repository.SpamRepository, window time.Duration) *DuplicateCheck {
+	return &DuplicateCheck{spamRepo: spamRepo, window: window}
+}
+
+func (c *DuplicateCheck) Name() string { return "duplicate" }
+
+func (c *DuplicateCheck) Check(ctx context.Context, s *Submission) (Result, error) {
+	// Short replies like "thanks!" are repeated legitimately all the time.
+	if len([]rune(s.Body)) < minDuplicateLength {
+		return allow(), nil
+	}
+
+	own, others, err := c.spamRepo.CountFingerprint(ctx, Fingerprint(s.Body), s.AuthorID, time.Now().Add(-c.window))
+	if err != nil {
+		return Result{}, err
+	}
+
+	switch {
+	case own > 0:
+		return Result{Verdict: Reject, Reason: "you already posted this"}, nil
+	case others > 0:
+		return Result{Verdict: Hold, Reason: "same text was posted by another account"}, nil
+	}
+	return allow(), nil
+}
+
+func (c *DuplicateCheck) Record(ctx context.Context, s *Submission) error {
+	if len([]rune(s.Body)) < minDuplicateLength {
+		return nil
+	}
+	return c.spamRepo.RecordFingerprint(ctx, Fingerprint(s.Body), s.AuthorID)
+}
+
+// Fingerprint hashes text after dropping case, punctuation and whitespace, so
+// trivial variations of the same message collide.
+func Fingerprint(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAccountCheck limits accounts that started posting recently: they may
+// post only a few times per hour, and their posts with links are held.
+// Account age is taken from the author's first post, as the core service
+// does not know when users registered.
+type NewAccountCheck struct {
+	postRepo        repository.PostRepository
+	minAge          time.Duration
+	maxPostsPerHour int
+}
+
+func NewNewAccountCheck(postRepo repository.PostRepository, minAge time.Duration, maxPostsPerHour int) *NewAccountCheck {
+	return &NewAccountCheck{postRepo: postRepo, minAge: minAge, maxPostsPerHour: maxPostsPerHour}
+}
+
+func (c *NewAccountCheck) Name() string { return "new-account" }
+
+func (c *NewAccountCheck) Check(ctx context.Context, s *Submission) (Result, error) {
+	first, err := c.postRepo.FirstPostAt(ctx, s.AuthorID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		first = time.Now()
+	case err != nil:
+		return Result{}, err
+	}
+	if time.Since(first) >= c.minAge {
+		return allow(), nil
+	}
+
+	recent, err := c.postRepo.CountByAuthorSince(ctx, s.AuthorID, time.Now().Add(-time.Hour))
+	if err != nil {
+		return Result{}, err
+	}
+	if recent >= c.maxPostsPerHour {
+		return Result{Verdict: Reject, Reason: fmt.Sprintf("new accounts may post %d times per hour", c.maxPostsPerHour)}, nil
+	}
+	if links, _ := CountLinks(s.text()); links > 0 {
+		return Result{Verdict: Hold, Reason: "links from a new account"}, nil
+	}
+	return allow(), nil
+}
+
+// BlocklistCheck rejects posts matching any blocked keyword or pattern.
+type BlocklistCheck struct {
+	patterns []*regexp.Regexp
+}
+
+// LoadBlocklist reads one entry per line. Lines starting with "re:" are
+// regular expressions, any other line is a case-insensitive keyword matched
+// on word boundaries. Empty lines and lines starting with "#" are skipped.
+// An empty path yields an empty blocklist.
+func LoadBlocklist(path string) (*BlocklistCheck, error) {
+	c := &BlocklistCheck{}
+	if path == "" {
+		return c, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		expr := `(?i)\b` + regexp.QuoteMeta(entry) + `\b`
+		if pattern, ok := strings.CutPrefix(entry, "re:"); ok {
+			expr = pattern
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	return c, scanner.Err()
+}
+
+func (c *BlocklistCheck) Name() string { return "blocklist" }
+
+func (c *BlocklistCheck) Check(_ context.Context, s *Submission) (Result, error) {
+	text := s.text()
+	for _, re := range c.patterns {
+		if match := re.FindString(text); match != "" {
+			return Result{Verdict: Reject, Reason: fmt.Sprintf("contains blocked text %q", match)}, nil
+		}
+	}
+	return allow(), nil
+}
@@ -0,0 +1,92 @@
+package spam
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"core-service/internal/repository"
+)
+
+func openSpamRepository(t *testing.T) repository.SpamRepository {
+	t.Helper()
+	db, err := repository.OpenSQLiteDB(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return repository.NewSQLiteSpamRepository(db)
+}
+
+func TestDuplicateCheck(t *testing.T) {
+	ctx := context.Background()
+	check := NewDuplicateCheck(openSpamRepository(t), time.Hour)
+	verdict := func(authorID int, body string) Verdict {
+		t.Helper()
+		result, err := check.Check(ctx, &Submission{AuthorID: authorID, Body: body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Verdict
+	}
+	record := func(authorID int, body string) {
+		t.Helper()
+		if err := check.Record(ctx, &Submission{AuthorID: authorID, Body: body}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const body = "Buy the best watches at the lowest prices"
+	// Checking alone remembers nothing, so a held post can be retried
+	for range 2 {
+		if v := verdict(1, body); v != Allow {
+			t.Fatalf("unpublished text: %v, want allow", v)
+		}
+	}
+
+	record(1, body)
+	if v := verdict(1, "buy the BEST watches, at the lowest prices!"); v != Reject {
+		t.Errorf("author repeating a variation: %v, want reject", v)
+	}
+	if v := verdict(2, body); v != Hold {
+		t.Errorf("another account posting the same: %v, want hold", v)
+	}
+	if v := verdict(1, body+" today"); v != Allow {
+		t.Errorf("different text: %v, want allow", v)
+	}
+
+	// Short replies are neither remembered nor checked
+	record(1, "Thanks!")
+	if v := verdict(1, "Thanks!"); v != Allow {
+		t.Errorf("short reply: %v, want allow", v)
+	}
+
+	// Posts older than the window are forgotten
+	check.window = 0
+	if v := verdict(1, body); v != Allow {
+		t.Errorf("repeat after the window: %v, want allow", v)
+	}
+}
+
+func TestLinkCheck(t *testing.T) {
+	ctx := context.Background()
+	check := NewLinkCheck(2, 0.5)
+	for _, tc := range []struct {
+		body string
+		want Verdict
+	}{
+		{"no links at all", Allow},
+		{"see https://example.com/docs and www.example.org for the details on how to set this up", Allow},
+		{"a https://a.example b https://b.example c https://c.example", Hold},
+		{"https://example.com/a-long-path https://example.com/another-one", Hold},
+	} {
+		result, err := check.Check(ctx, &Submission{Body: tc.body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Verdict != tc.want {
+			t.Errorf("%q: %v, want %v", tc.body, result.Verdict, tc.want)
+		}
+	}
+}
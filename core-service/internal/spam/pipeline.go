@@ -0,0 +1,116 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Verdict is the outcome of a content check. Verdicts are ordered by
+// severity, so the pipeline's decision is the highest one any check returned.
+type Verdict int
+
+const (
+	Allow Verdict = iota
+	Hold
+	Reject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Hold:
+		return "hold"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// Submission is a post about to be published. Title is set only for the
+// first post of a new topic.
+type Submission struct {
+	AuthorID int
+	TopicID  int64
+	Title    string
+	Body     string
+}
+
+func (s *Submission) text() string {
+	if s.Title == "" {
+		return s.Body
+	}
+	return s.Title + "\n" + s.Body
+}
+
+type Result struct {
+	Verdict Verdict
+	Reason  string
+}
+
+func allow() Result { return Result{Verdict: Allow} }
+
+// Check inspects a submission for one kind of abuse. It must not remember
+// the submission, which may still be held or fail to be stored; checks that
+// need to know what was published implement Recorder as well.
+type Check interface {
+	Name() string
+	Check(ctx context.Context, s *Submission) (Result, error)
+}
+
+// Recorder is implemented by checks that keep track of published content.
+type Recorder interface {
+	Record(ctx context.Context, s *Submission) error
+}
+
+type Decision struct {
+	Verdict Verdict
+	// Reasons holds one "<check>: <reason>" entry per check that did not
+	// allow the submission.
+	Reasons []string
+}
+
+// Pipeline runs checks in order and stops at the first rejection. A check
+// that fails is logged and skipped rather than blocking every post.
+type Pipeline struct {
+	checks []Check
+}
+
+func NewPipeline(checks ...Check) *Pipeline {
+	return &Pipeline{checks: checks}
+}
+
+func (p *Pipeline) Run(ctx context.Context, s *Submission) Decision {
+	var d Decision
+	for _, check := range p.checks {
+		result, err := check.Check(ctx, s)
+		if err != nil {
+			log.Printf("Error running %s check: %v", check.Name(), err)
+			continue
+		}
+		if result.Verdict == Allow {
+			continue
+		}
+
+		d.Reasons = append(d.Reasons, fmt.Sprintf("%s: %s", check.Name(), result.Reason))
+		d.Verdict = max(d.Verdict, result.Verdict)
+		if d.Verdict == Reject {
+			break
+		}
+	}
+	return d
+}
+
+// Record tells the checks implementing Recorder that a submission was
+// published. Failures are logged, as the post is stored already.
+func (p *Pipeline) Record(ctx context.Context, s *Submission) {
+	for _, check := range p.checks {
+		recorder, ok := check.(Recorder)
+		if !ok {
+			continue
+		}
+		if err := recorder.Record(ctx, s); err != nil {
+			log.Printf("Error recording post in %s check: %v", check.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package spam
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type stubCheck struct {
+	name     string
+	result   Result
+	err      error
+	calls    int
+	recorded []*Submission
+}
+
+func (c *stubCheck) Name() string { return c.name }
+
+func (c *stubCheck) Check(context.Context, *Submission) (Result, error) {
+	c.calls++
+	return c.result, c.err
+}
+
+// recordingCheck is a stubCheck that also implements Recorder.
+type recordingCheck struct{ stubCheck }
+
+func (c *recordingCheck) Record(_ context.Context, s *Submission) error {
+	c.recorded = append(c.recorded, s)
+	return c.err
+}
+
+func TestPipelineRun(t *testing.T) {
+	ctx := context.Background()
+	s := &Submission{AuthorID: 1, Body: "body"}
+
+	failing := &stubCheck{name: "failing", err: errors.New("database is locked")}
+	allowed := &stubCheck{name: "allowed"}
+	held := &stubCheck{name: "held", result: Result{Verdict: Hold, Reason: "too many links"}}
+	rejected := &stubCheck{name: "rejected", result: Result{Verdict: Reject, Reason: "duplicate"}}
+	after := &stubCheck{name: "after", result: Result{Verdict: Hold, Reason: "never asked"}}
+
+	d := NewPipeline(failing, allowed, held, rejected, after).Run(ctx, s)
+	if d.Verdict != Reject {
+		t.Errorf("verdict = %v, want reject", d.Verdict)
+	}
+	if want := []string{"held: too many links", "rejected: duplicate"}; !reflect.DeepEqual(d.Reasons, want) {
+		t.Errorf("reasons = %q, want %q", d.Reasons, want)
+	}
+	if failing.calls != 1 || allowed.calls != 1 {
+		t.Error("checks before the rejection were not all run")
+	}
+	if after.calls != 0 {
+		t.Error("checks after a rejection were run")
+	}
+
+	// The most severe verdict wins regardless of order
+	held.calls = 0
+	d = NewPipeline(held, allowed).Run(ctx, s)
+	if d.Verdict != Hold || len(d.Reasons) != 1 {
+		t.Errorf("decision = %+v, want hold for one reason", d)
+	}
+	if d := NewPipeline(allowed, failing).Run(ctx, s); d.Verdict != Allow || d.Reasons != nil {
+		t.Errorf("decision = %+v, want allow", d)
+	}
+}
+
+func TestPipelineRecord(t *testing.T) {
+	ctx := context.Background()
+	s := &Submission{AuthorID: 1, Body: "body"}
+
+	plain := &stubCheck{name: "plain"}
+	failing := &recordingCheck{stubCheck{name: "failing", err: errors.New("database is locked")}}
+	recording := &recordingCheck{stubCheck{name: "recording"}}
+
+	// A failing recorder does not stop the others
+	NewPipeline(plain, failing, recording).Record(ctx, s)
+	if len(recording.recorded) != 1 || recording.recorded[0] != s {
+		t.Errorf("recorded %v, want the submission", recording.recorded)
+	}
+	if plain.calls != 0 || recording.calls != 0 {
+		t.Error("Record ran the checks")
+	}
+}
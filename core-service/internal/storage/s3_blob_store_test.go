@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "eu-central-1"
+	testBucket    = "attachments"
+)
+
+// fakeS3 is a stand-in for an S3-compatible store. It keeps objects in memory
+// and, like the real thing, rejects requests whose Signature Version 4 does
+// not check out.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+	fake := &fakeS3{objects: make(map[string][]byte), types: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := verifySignature(r, body); err != nil {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>"+err.Error()+"</Message></Error>", http.StatusForbidden)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+var authorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([a-z0-9;-]+), Signature=([0-9a-f]{64})$`)
+
+// verifySignature checks the request the way S3 does, from the headers the
+// client says it signed.
+func verifySignature(r *http.Request, body []byte) error {
+	m := authorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return errors.New("malformed Authorization header")
+	}
+	accessKey, date, region, signedHeaders, signature := m[1], m[2], m[3], m[4], m[5]
+	if accessKey != testAccessKey || region != testRegion {
+		return errors.New("unknown credential")
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil || !strings.HasPrefix(amzDate, date) {
+		return errors.New("bad X-Amz-Date")
+	}
+	if d := time.Since(signedAt); d > 15*time.Minute || d < -15*time.Minute {
+		return errors.New("request time too skewed")
+	}
+
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return errors.New("payload hash does not match the body")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	crSum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + date + "/" + region + "/s3/aws4_request\n" + hex.EncodeToString(crSum[:])
+
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{date, region, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature)) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+func newTestS3BlobStore(endpoint, secretKey string) *S3BlobStore {
+	return NewS3BlobStore(S3Config{
+		Endpoint:  endpoint + "/",
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: secretKey,
+	})
+}
+
+func TestS3BlobStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestS3BlobStore(newFakeS3(t).URL, testSecretKey)
+	key := "2024/05/a1b2c3.png"
+	data := []byte("\x89PNG not really")
+
+	if err := store.Put(ctx, key, data, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(got) != string(data) {
+		t.Errorf("Get = %q, %v, want %q", got, err, data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrBlobNotFound", err)
+	}
+	// Deleting is idempotent
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob = %v", err)
+	}
+}
+
+func TestS3BlobStoreEmptyBlob(t *testing.T) {
+	ctx := context.Background()
+	store := newTestS3BlobStore(newFakeS3(t).URL, testSecretKey)
+
+	if err := store.Put(ctx, "empty.txt", nil, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	body, err := store.Get(ctx, "empty.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer body.Close()
+	if got, _ := io.ReadAll(body); len(got) != 0 {
+		t.Errorf("Get = %q, want nothing", got)
+	}
+}
+
+func TestS3BlobStoreRejectedSignature(t *testing.T) {
+	ctx := context.Background()
+	store := newTestS3BlobStore(newFakeS3(t).URL, "not-the-secret")
+
+	err := store.Put(ctx, "a.txt", []byte("hello"), "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put with the wrong secret = %v, want a 403 error", err)
+	}
+	if _, err := store.Get(ctx, "a.txt"); err == nil || errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get with the wrong secret = %v, want a 403 error", err)
+	}
+}
+
+func TestS3BlobStoreSign(t *testing.T) {
+	store := newTestS3BlobStore("http://s3.example.com", testSecretKey)
+	req, err := http.NewRequest(http.MethodPut, "http://s3.example.com/attachments/a.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.sign(req, "/attachments/a.txt", []byte("hello"), time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC))
+
+	if got := req.Header.Get("X-Amz-Date"); got != "20240501T123000Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+	if got, want := req.Header.Get("X-Amz-Content-Sha256"), "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; got != want {
+		t.Errorf("X-Amz-Content-Sha256 = %q, want %q", got, want)
+	}
+	m := authorizationPattern.FindStringSubmatch(req.Header.Get("Authorization"))
+	if m == nil {
+		t.Fatalf("Authorization = %q", req.Header.Get("Authorization"))
+	}
+	if m[1] != testAccessKey || m[2] != "20240501" || m[3] != testRegion || m[4] != "host;x-amz-content-sha256;x-amz-date" {
+		t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+	}
+
+	// The same request signed a second later gets another signature
+	again := req.Clone(context.Background())
+	store.sign(again, "/attachments/a.txt", []byte("hello"), time.Date(2024, 5, 1, 12, 30, 1, 0, time.UTC))
+	if again.Header.Get("Authorization") == req.Header.Get("Authorization") {
+		t.Error("signature does not depend on the time")
+	}
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+	"core-service/internal/storage"
+	"github.com/google/uuid"
+)
+
+const maxAttachmentsPerPost = 10
+
+var ErrTooLarge = fmt.Errorf("%w: file too large", ErrInvalidInput)
+
+// allowedContentTypes maps sniffed MIME types to whether a thumbnail is made.
+var allowedContentTypes = map[string]bool{
+	"image/png":                 true,
+	"image/jpeg":                true,
+	"image/gif":                 true,
+	"image/webp":                false,
+	"text/plain; charset=utf-8": false,
+	"application/pdf":           false,
+	"application/zip":           false,
+	"application/x-gzip":        false,
+}
+
+type AttachmentUseCase struct {
+	authClient     *rest.AuthClient
+	attachmentRepo repository.AttachmentRepository
+	store          storage.BlobStore
+	reputation     *ReputationUseCase
+	roles          *Roles
+	maxSize        int64
+}
+
+func NewAttachmentUseCase(authClient *rest.AuthClient, attachmentRepo repository.AttachmentRepository, store storage.BlobStore, reputation *ReputationUseCase, roles *Roles, maxSize int64) *AttachmentUseCase {
+	return &AttachmentUseCase{
+		authClient:     authClient,
+		attachmentRepo: attachmentRepo,
+		store:          store,
+		reputation:     reputation,
+		roles:          roles,
+		maxSize:        maxSize,
+	}
+}
+
+// Upload stores a file that can later be linked to a post. The type is
+// determined by sniffing the content; whatever the client claims is ignored.
+func (uc *AttachmentUseCase) Upload(ctx context.Context, token, filename string, r io.Reader) (*entity.Attachment, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	if err := uc.reputation.require(ctx, userID, CapAttachFiles); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, uc.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > uc.maxSize {
+		return nil, ErrTooLarge
+	}
+	if len(data) == 0 {
+		return nil, ErrInvalidInput
+	}
+
+	contentType := http.DetectContentType(data)
+	thumbnail, allowed := allowedContentTypes[contentType]
+	if !allowed {
+		return nil, fmt.Errorf("%w: unsupported file type %s", ErrInvalidInput, contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	key := "attachments/" + uuid.New().String()
+	a := &entity.Attachment{
+		UploaderID:  userID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		StorageKey:  key,
+	}
+
+	if err := uc.store.Put(ctx, key, data, contentType); err != nil {
+		return nil, err
+	}
+	if thumbnail {
+		if thumb, thumbType, ok := makeThumbnail(data); ok {
+			if err := uc.store.Put(ctx, key+".thumb", thumb, thumbType); err != nil {
+				log.Printf("Error storing thumbnail for %s: %v", key, err)
+			} else {
+				a.ThumbnailKey = key + ".thumb"
+				a.HasThumbnail = true
+			}
+		}
+	}
+
+	if err := uc.attachmentRepo.Create(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (uc *AttachmentUseCase) Get(ctx context.Context, id int64) (*entity.Attachment, error) {
+	return uc.liveAttachment(ctx, id)
+}
+
+func (uc *AttachmentUseCase) Delete(ctx context.Context, token string, id int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	a, err := uc.liveAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.UploaderID != userID && !uc.roles.IsModerator(userID) {
+		return ErrForbidden
+	}
+	return uc.attachmentRepo.SoftDelete(ctx, id, userID)
+}
+
+func (uc *AttachmentUseCase) Restore(ctx context.Context, token string, id int64) (*entity.Attachment, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	a, err := uc.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canRestore(uc.roles, userID, a.UploaderID, a.DeletedBy) {
+		return nil, ErrForbidden
+	}
+
+	if err := uc.attachmentRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	a.DeletedAt, a.DeletedBy = nil, 0
+	return a, nil
+}
+
+func (uc *AttachmentUseCase) liveAttachment(ctx context.Context, id int64) (*entity.Attachment, error) {
+	a, err := uc.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return a, nil
+}
+
+// Open returns the attachment's content, or its thumbnail.
+func (uc *AttachmentUseCase) Open(ctx context.Context, id int64, thumbnail bool) (*entity.Attachment, io.ReadCloser, error) {
+	a, err := uc.liveAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := a.StorageKey
+	if thumbnail {
+		if a.ThumbnailKey == "" {
+			return nil, nil, repository.ErrNotFound
+		}
+		key = a.ThumbnailKey
+	}
+
+	r, err := uc.store.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, r, nil
+}
+
+// cleanFilename keeps only the base name and drops characters that could
+// break a Content-Disposition header.
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == '/' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+)
+
+const maxDraftTitleLength = 300
+
+// DraftUseCase keeps users' unfinished topics and replies on the server so
+// they survive a crashed browser. Drafts are private to their author.
+type DraftUseCase struct {
+	authClient *rest.AuthClient
+	draftRepo  repository.DraftRepository
+	topicRepo  repository.TopicRepository
+}
+
+func NewDraftUseCase(authClient *rest.AuthClient, draftRepo repository.DraftRepository, topicRepo repository.TopicRepository) *DraftUseCase {
+	return &DraftUseCase{
+		authClient: authClient,
+		draftRepo:  draftRepo,
+		topicRepo:  topicRepo,
+	}
+}
+
+// SaveDraft autosaves a draft. A save older than the stored one is ignored;
+// either way the stored draft is returned so the client can tell.
+func (uc *DraftUseCase) SaveDraft(ctx context.Context, token string, draft *entity.Draft) (*entity.Draft, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	// Drafts are stored as typed; they are sanitized once published.
+	if utf8.RuneCountInString(draft.Body) > maxBodyLength || utf8.RuneCountInString(draft.Title) > maxDraftTitleLength {
+		return nil, fmt.Errorf("%w: draft is too long", ErrInvalidInput)
+	}
+	if draft.TopicID != 0 {
+		if _, err := liveTopic(ctx, uc.topicRepo, draft.TopicID); err != nil {
+			return nil, err
+		}
+		draft.CategoryID, draft.Title = 0, ""
+	} else {
+		draft.ReplyToID = 0
+	}
+
+	draft.UserID = userID
+	return uc.draftRepo.Save(ctx, draft)
+}
+
+// GetDraft returns the user's draft for a topic, or for a new topic with
+// topicID 0.
+func (uc *DraftUseCase) GetDraft(ctx context.Context, token string, topicID int64) (*entity.Draft, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return uc.draftRepo.Get(ctx, userID, topicID)
+}
+
+func (uc *DraftUseCase) ListDrafts(ctx context.Context, token string) ([]entity.Draft, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return uc.draftRepo.ListByUser(ctx, userID)
+}
+
+func (uc *DraftUseCase) DeleteDraft(ctx context.Context, token string, topicID int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	return uc.draftRepo.Delete(ctx, userID, topicID)
+}
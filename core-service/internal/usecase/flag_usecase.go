@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+)
+
+const maxFlagReasonLength = 500
+
+// FlagUseCase lets users flag problematic posts. Flags are weighted by the
+// flagger's trust level; once a post's flags reach the threshold it is hidden
+// until a moderator restores it.
+type FlagUseCase struct {
+	authClient    *rest.AuthClient
+	flagRepo      repository.FlagRepository
+	postRepo      repository.PostRepository
+	posts         *PostUseCase
+	reputation    *ReputationUseCase
+	roles         *Roles
+	hideThreshold int
+}
+
+func NewFlagUseCase(authClient *rest.AuthClient, flagRepo repository.FlagRepository, postRepo repository.PostRepository, posts *PostUseCase, reputation *ReputationUseCase, roles *Roles, hideThreshold int) *FlagUseCase {
+	return &FlagUseCase{
+		authClient:    authClient,
+		flagRepo:      flagRepo,
+		postRepo:      postRepo,
+		posts:         posts,
+		reputation:    reputation,
+		roles:         roles,
+		hideThreshold: hideThreshold,
+	}
+}
+
+func (uc *FlagUseCase) FlagPost(ctx context.Context, token string, postID int64, reason string) (*entity.Flag, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxFlagReasonLength {
+		return nil, ErrInvalidInput
+	}
+
+	post, err := uc.posts.livePost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID == userID {
+		return nil, fmt.Errorf("%w: cannot flag your own post", ErrForbidden)
+	}
+
+	weight, err := uc.reputation.FlagWeight(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// A moderator's flag hides the post right away.
+	if uc.roles.IsModerator(userID) {
+		weight = uc.hideThreshold
+	}
+
+	flag := &entity.Flag{PostID: postID, UserID: userID, Reason: reason, Weight: weight}
+	total, err := uc.flagRepo.Create(ctx, flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if total >= uc.hideThreshold {
+		// Deleted by nobody, so only moderators can restore it.
+		if err := uc.postRepo.SoftDelete(ctx, postID, 0); err != nil {
+			return nil, err
+		}
+		uc.posts.publishPost(ctx, realtime.EventPostDeleted, 0, &entity.Post{ID: post.ID, TopicID: post.TopicID})
+	}
+	return flag, nil
+}
+
+func (uc *FlagUseCase) ListFlags(ctx context.Context, token string, postID int64) ([]entity.Flag, error) {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return nil, err
+	}
+	if _, err := uc.postRepo.GetByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return uc.flagRepo.ListByPost(ctx, postID)
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+)
+
+const (
+	maxConversationParticipants = 20
+	maxSubjectLength            = 200
+)
+
+type MessageUseCase struct {
+	authClient  *rest.AuthClient
+	messageRepo repository.MessageRepository
+	blockRepo   repository.BlockRepository
+	hub         *realtime.Hub
+}
+
+func NewMessageUseCase(authClient *rest.AuthClient, messageRepo repository.MessageRepository, blockRepo repository.BlockRepository, hub *realtime.Hub) *MessageUseCase {
+	return &MessageUseCase{
+		authClient:  authClient,
+		messageRepo: messageRepo,
+		blockRepo:   blockRepo,
+		hub:         hub,
+	}
+}
+
+// StartConversation creates a conversation between the caller and the given
+// users and posts its first message.
+func (uc *MessageUseCase) StartConversation(ctx context.Context, token, subject string, recipients []int, body string) (*entity.Conversation, *entity.Message, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, nil, ErrUnauthorized
+	}
+
+	participants := []int{userID}
+	seen := map[int]bool{userID: true}
+	for _, id := range recipients {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		participants = append(participants, id)
+	}
+	if len(participants) < 2 || len(participants) > maxConversationParticipants {
+		return nil, nil, ErrInvalidInput
+	}
+
+	subject = strings.TrimSpace(subject)
+	if len(subject) > maxSubjectLength {
+		return nil, nil, ErrInvalidInput
+	}
+	body, err = sanitizeBody(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blocked, err := uc.blockRepo.AnyBlocks(ctx, participants[1:], userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blocked {
+		return nil, nil, ErrForbidden
+	}
+
+	conv := &entity.Conversation{Subject: subject, CreatedBy: userID, Participants: participants}
+	msg := &entity.Message{SenderID: userID, Body: body}
+	if err := uc.messageRepo.CreateConversation(ctx, conv, msg); err != nil {
+		return nil, nil, err
+	}
+
+	uc.publish(conv, msg)
+	return conv, msg, nil
+}
+
+func (uc *MessageUseCase) SendMessage(ctx context.Context, token string, conversationID int64, body string) (*entity.Message, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	conv, err := uc.conversationFor(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = sanitizeBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := uc.blockRepo.AnyBlocks(ctx, others(conv.Participants, userID), userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrForbidden
+	}
+
+	msg := &entity.Message{ConversationID: conv.ID, SenderID: userID, Body: body}
+	if err := uc.messageRepo.AddMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	uc.publish(conv, msg)
+	return msg, nil
+}
+
+func (uc *MessageUseCase) GetConversation(ctx context.Context, token string, conversationID int64) (*entity.Conversation, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return uc.conversationFor(ctx, conversationID, userID)
+}
+
+func (uc *MessageUseCase) ListMessages(ctx context.Context, token string, conversationID int64, req pagination.Request) (pagination.Page[entity.Message], error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return pagination.Page[entity.Message]{}, ErrUnauthorized
+	}
+	if _, err := uc.conversationFor(ctx, conversationID, userID); err != nil {
+		return pagination.Page[entity.Message]{}, err
+	}
+	return uc.messageRepo.ListMessages(ctx, conversationID, req)
+}
+
+func (uc *MessageUseCase) MarkRead(ctx context.Context, token string, conversationID int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if _, err := uc.conversationFor(ctx, conversationID, userID); err != nil {
+		return err
+	}
+	return uc.messageRepo.MarkRead(ctx, conversationID, userID)
+}
+
+// Inbox lists the caller's conversations by latest message, along with the
+// total number of unread messages across all of them.
+func (uc *MessageUseCase) Inbox(ctx context.Context, token string, req pagination.Request) (pagination.Page[entity.InboxEntry], int, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return pagination.Page[entity.InboxEntry]{}, 0, ErrUnauthorized
+	}
+
+	page, err := uc.messageRepo.Inbox(ctx, userID, req)
+	if err != nil {
+		return pagination.Page[entity.InboxEntry]{}, 0, err
+	}
+	unread, err := uc.messageRepo.UnreadTotal(ctx, userID)
+	if err != nil {
+		return pagination.Page[entity.InboxEntry]{}, 0, err
+	}
+	return page, unread, nil
+}
+
+func (uc *MessageUseCase) Block(ctx context.Context, token string, blockedID int) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if blockedID <= 0 || blockedID == userID {
+		return ErrInvalidInput
+	}
+	return uc.blockRepo.Block(ctx, &entity.Block{UserID: userID, BlockedID: blockedID})
+}
+
+func (uc *MessageUseCase) Unblock(ctx context.Context, token string, blockedID int) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	return uc.blockRepo.Unblock(ctx, userID, blockedID)
+}
+
+func (uc *MessageUseCase) ListBlocked(ctx context.Context, token string) ([]entity.Block, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return uc.blockRepo.ListBlocked(ctx, userID)
+}
+
+// conversationFor loads a conversation on behalf of a user. Conversations the
+// user is not part of are reported as not found so their existence is not
+// revealed.
+func (uc *MessageUseCase) conversationFor(ctx context.Context, conversationID int64, userID int) (*entity.Conversation, error) {
+	ok, err := uc.messageRepo.IsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return uc.messageRepo.GetConversation(ctx, conversationID)
+}
+
+func (uc *MessageUseCase) publish(conv *entity.Conversation, msg *entity.Message) {
+	for _, recipient := range others(conv.Participants, msg.SenderID) {
+		uc.hub.Publish(realtime.Event{
+			Type:        realtime.EventMessage,
+			UserID:      msg.SenderID,
+			RecipientID: recipient,
+			Payload:     msg,
+		})
+	}
+}
+
+func others(participants []int, userID int) []int {
+	result := make([]int, 0, len(participants))
+	for _, id := range participants {
+		if id != userID {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"regexp"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/notification"
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+)
+
+const maxMentionsPerPost = 10
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w{3,32})`)
+
+type NotificationUseCase struct {
+	authClient       *rest.AuthClient
+	notificationRepo repository.NotificationRepository
+	subscriptionRepo repository.SubscriptionRepository
+	postRepo         repository.PostRepository
+	topicRepo        repository.TopicRepository
+	categoryRepo     repository.CategoryRepository
+	// Channels that deliver as soon as a notification is stored. Digest
+	// channels are driven by notification.Dispatcher instead.
+	instant []notification.Channel
+}
+
+func NewNotificationUseCase(
+	authClient *rest.AuthClient,
+	notificationRepo repository.NotificationRepository,
+	subscriptionRepo repository.SubscriptionRepository,
+	postRepo repository.PostRepository,
+	topicRepo repository.TopicRepository,
+	categoryRepo repository.CategoryRepository,
+	instant ...notification.Channel,
+) *NotificationUseCase {
+	return &NotificationUseCase{
+		authClient:       authClient,
+		notificationRepo: notificationRepo,
+		subscriptionRepo: subscriptionRepo,
+		postRepo:         postRepo,
+		topicRepo:        topicRepo,
+		categoryRepo:     categoryRepo,
+		instant:          instant,
+	}
+}
+
+// PostCreated fans a new post out to everyone it concerns. Each user gets at
+// most one notification per post, the most specific kind winning: a reply
+// beats a mention, which beats a watched topic or category.
+func (uc *NotificationUseCase) PostCreated(ctx context.Context, topic *entity.Topic, post *entity.Post) error {
+	recipients := make(map[int]entity.NotificationKind)
+	add := func(userID int, kind entity.NotificationKind) {
+		if userID == post.AuthorID {
+			return
+		}
+		if _, ok := recipients[userID]; !ok {
+			recipients[userID] = kind
+		}
+	}
+
+	if post.ReplyToID != 0 {
+		if parent, err := uc.postRepo.GetByID(ctx, post.ReplyToID); err == nil {
+			add(parent.AuthorID, entity.NotificationReply)
+		}
+	}
+	add(topic.AuthorID, entity.NotificationReply)
+
+	for _, userID := range uc.mentionedUsers(post.Body) {
+		add(userID, entity.NotificationMention)
+	}
+
+	watchers, err := uc.subscriptionRepo.Watchers(ctx, topic.ID, topic.CategoryID)
+	if err != nil {
+		return err
+	}
+	for _, userID := range watchers {
+		add(userID, entity.NotificationWatched)
+	}
+
+	for userID, kind := range recipients {
+		n := &entity.Notification{
+			UserID:  userID,
+			Kind:    kind,
+			ActorID: post.AuthorID,
+			TopicID: topic.ID,
+			PostID:  post.ID,
+		}
+		if err := uc.notificationRepo.Create(ctx, n); err != nil {
+			return err
+		}
+		for _, ch := range uc.instant {
+			if err := ch.Deliver(ctx, userID, []entity.Notification{*n}); err != nil {
+				log.Printf("Error delivering %s notification to user %d: %v", ch.Name(), userID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *NotificationUseCase) mentionedUsers(body string) []int {
+	seen := make(map[string]bool)
+	var users []int
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := m[1]
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		if len(seen) > maxMentionsPerPost {
+			break
+		}
+
+		userID, err := uc.authClient.LookupUserID(username)
+		if err != nil {
+			continue
+		}
+		users = append(users, userID)
+	}
+	return users
+}
+
+func (uc *NotificationUseCase) List(ctx context.Context, token string, unreadOnly bool, req pagination.Request) (pagination.Page[entity.Notification], int, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return pagination.Page[entity.Notification]{}, 0, ErrUnauthorized
+	}
+
+	page, err := uc.notificationRepo.ListByUser(ctx, userID, unreadOnly, req)
+	if err != nil {
+		return pagination.Page[entity.Notification]{}, 0, err
+	}
+	unread, err := uc.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return pagination.Page[entity.Notification]{}, 0, err
+	}
+	return page, unread, nil
+}
+
+func (uc *NotificationUseCase) MarkRead(ctx context.Context, token string, id int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	return uc.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (uc *NotificationUseCase) MarkAllRead(ctx context.Context, token string) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	return uc.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (uc *NotificationUseCase) WatchTopic(ctx context.Context, token string, topicID int64, watch bool) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if _, err := uc.topicRepo.GetByID(ctx, topicID); err != nil {
+		return err
+	}
+	return uc.setSubscription(ctx, &entity.Subscription{UserID: userID, TopicID: topicID}, watch)
+}
+
+func (uc *NotificationUseCase) WatchCategory(ctx context.Context, token string, categoryID int64, watch bool) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if _, err := uc.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return err
+	}
+	return uc.setSubscription(ctx, &entity.Subscription{UserID: userID, CategoryID: categoryID}, watch)
+}
+
+func (uc *NotificationUseCase) setSubscription(ctx context.Context, sub *entity.Subscription, watch bool) error {
+	if watch {
+		return uc.subscriptionRepo.Subscribe(ctx, sub)
+	}
+	return uc.subscriptionRepo.Unsubscribe(ctx, sub)
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+)
+
+const (
+	minPollOptions        = 2
+	maxPollOptions        = 20
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 200
+)
+
+// PollUseCase runs the polls attached to topics. Every user has one ballot
+// per poll, which they may change or withdraw until the poll closes.
+type PollUseCase struct {
+	authClient *rest.AuthClient
+	pollRepo   repository.PollRepository
+	topicRepo  repository.TopicRepository
+	hub        *realtime.Hub
+	roles      *Roles
+}
+
+func NewPollUseCase(authClient *rest.AuthClient, pollRepo repository.PollRepository, topicRepo repository.TopicRepository, hub *realtime.Hub, roles *Roles) *PollUseCase {
+	return &PollUseCase{
+		authClient: authClient,
+		pollRepo:   pollRepo,
+		topicRepo:  topicRepo,
+		hub:        hub,
+		roles:      roles,
+	}
+}
+
+// CreatePoll attaches a poll to a topic. Only the topic's author and
+// moderators may do so.
+func (uc *PollUseCase) CreatePoll(ctx context.Context, token string, poll *entity.Poll) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	topic, err := liveTopic(ctx, uc.topicRepo, poll.TopicID)
+	if err != nil {
+		return err
+	}
+	if topic.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return ErrForbidden
+	}
+	if err := validatePoll(poll); err != nil {
+		return err
+	}
+
+	if err := uc.pollRepo.Create(ctx, poll); err != nil {
+		if errors.Is(err, repository.ErrPollExists) {
+			return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		return err
+	}
+	return uc.view(ctx, poll, userID)
+}
+
+func validatePoll(poll *entity.Poll) error {
+	poll.Question = strings.TrimSpace(poll.Question)
+	if poll.Question == "" || len(poll.Question) > maxPollQuestionLength {
+		return fmt.Errorf("%w: question must be 1-%d characters", ErrInvalidInput, maxPollQuestionLength)
+	}
+
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return fmt.Errorf("%w: a poll needs %d-%d options", ErrInvalidInput, minPollOptions, maxPollOptions)
+	}
+	seen := make(map[string]bool, len(poll.Options))
+	for i := range poll.Options {
+		text := strings.TrimSpace(poll.Options[i].Text)
+		if text == "" || len(text) > maxPollOptionLength {
+			return fmt.Errorf("%w: options must be 1-%d characters", ErrInvalidInput, maxPollOptionLength)
+		}
+		if seen[strings.ToLower(text)] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidInput, text)
+		}
+		seen[strings.ToLower(text)] = true
+		poll.Options[i] = entity.PollOption{Text: text}
+	}
+
+	switch poll.Results {
+	case "":
+		poll.Results = entity.PollResultsAlways
+	case entity.PollResultsAlways, entity.PollResultsOnVote, entity.PollResultsOnClose:
+	default:
+		return fmt.Errorf("%w: unknown results visibility %q", ErrInvalidInput, poll.Results)
+	}
+
+	if poll.ClosesAt != nil {
+		if !poll.ClosesAt.After(time.Now()) {
+			return fmt.Errorf("%w: close date must be in the future", ErrInvalidInput)
+		}
+		closesAt := poll.ClosesAt.UTC()
+		poll.ClosesAt = &closesAt
+	}
+	return nil
+}
+
+// GetPoll returns a topic's poll as the caller may see it. The token is
+// optional; guests see what a user who has not voted sees.
+func (uc *PollUseCase) GetPoll(ctx context.Context, token string, topicID int64) (*entity.Poll, error) {
+	userID, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return nil, err
+	}
+
+	poll, err := uc.livePoll(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	return poll, uc.view(ctx, poll, userID)
+}
+
+// Vote casts or replaces the user's ballot. Single choice polls take exactly
+// one option.
+func (uc *PollUseCase) Vote(ctx context.Context, token string, topicID int64, optionIDs []int64) (*entity.Poll, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	poll, err := uc.livePoll(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	optionIDs = uniqueIDs(optionIDs)
+	if len(optionIDs) == 0 {
+		return nil, fmt.Errorf("%w: choose at least one option", ErrInvalidInput)
+	}
+	if !poll.Multiple && len(optionIDs) > 1 {
+		return nil, fmt.Errorf("%w: this poll takes a single choice", ErrInvalidInput)
+	}
+
+	return uc.cast(ctx, userID, poll, optionIDs)
+}
+
+// WithdrawVote removes the user's ballot while the poll is open.
+func (uc *PollUseCase) WithdrawVote(ctx context.Context, token string, topicID int64) (*entity.Poll, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	poll, err := uc.livePoll(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.cast(ctx, userID, poll, nil)
+}
+
+func (uc *PollUseCase) cast(ctx context.Context, userID int, poll *entity.Poll, optionIDs []int64) (*entity.Poll, error) {
+	err := uc.pollRepo.Vote(ctx, poll.ID, userID, optionIDs)
+	switch {
+	case errors.Is(err, repository.ErrPollClosed):
+		return nil, fmt.Errorf("%w: %v", ErrForbidden, err)
+	case errors.Is(err, repository.ErrInvalidChoice):
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	case err != nil:
+		return nil, err
+	}
+
+	uc.publish(ctx, userID, poll)
+	return poll, uc.view(ctx, poll, userID)
+}
+
+// ClosePoll ends voting now. Only the topic's author and moderators may close
+// a poll early.
+func (uc *PollUseCase) ClosePoll(ctx context.Context, token string, topicID int64) (*entity.Poll, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	topic, err := liveTopic(ctx, uc.topicRepo, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return nil, ErrForbidden
+	}
+
+	poll, err := uc.pollRepo.GetByTopic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.pollRepo.Close(ctx, poll.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	if poll, err = uc.pollRepo.GetByTopic(ctx, topicID); err != nil {
+		return nil, err
+	}
+
+	uc.publish(ctx, userID, poll)
+	return poll, uc.view(ctx, poll, userID)
+}
+
+func (uc *PollUseCase) livePoll(ctx context.Context, topicID int64) (*entity.Poll, error) {
+	if _, err := liveTopic(ctx, uc.topicRepo, topicID); err != nil {
+		return nil, err
+	}
+	return uc.pollRepo.GetByTopic(ctx, topicID)
+}
+
+// view fills in the parts of a poll that depend on the viewer: their own
+// choices, and the results if the poll's visibility rule allows it. User 0
+// is a guest.
+func (uc *PollUseCase) view(ctx context.Context, poll *entity.Poll, userID int) error {
+	poll.Closed = poll.IsClosed(time.Now())
+	poll.MyChoices = nil
+	if userID != 0 {
+		choices, err := uc.pollRepo.Choices(ctx, poll.ID, userID)
+		if err != nil {
+			return err
+		}
+		poll.MyChoices = choices
+	}
+
+	switch poll.Results {
+	case entity.PollResultsOnVote:
+		poll.ResultsVisible = poll.Closed || len(poll.MyChoices) > 0
+	case entity.PollResultsOnClose:
+		poll.ResultsVisible = poll.Closed
+	default:
+		poll.ResultsVisible = true
+	}
+
+	if !poll.ResultsVisible {
+		poll.Voters = 0
+		for i := range poll.Options {
+			poll.Options[i].Votes, poll.Options[i].VoterIDs = 0, nil
+		}
+		return nil
+	}
+	return uc.pollRepo.Tally(ctx, poll, !poll.Anonymous)
+}
+
+// publish tells the topic's viewers about new results, as a guest would see
+// them, so hidden results stay hidden.
+func (uc *PollUseCase) publish(ctx context.Context, userID int, poll *entity.Poll) {
+	topic, err := uc.topicRepo.GetByID(ctx, poll.TopicID)
+	if err != nil {
+		return
+	}
+
+	public := *poll
+	public.Options = append([]entity.PollOption(nil), poll.Options...)
+	if err := uc.view(ctx, &public, 0); err != nil {
+		return
+	}
+	uc.hub.Publish(realtime.Event{
+		Type:       realtime.EventPollUpdated,
+		TopicID:    topic.ID,
+		CategoryID: topic.CategoryID,
+		UserID:     userID,
+		Payload:    &public,
+	})
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"core-service/internal/entity"
+)
+
+// newTestPoll opens a topic by user 1 with a yes/no poll.
+func (a *testApp) newTestPoll(t *testing.T, results entity.PollResults, anonymous bool) *entity.Poll {
+	t.Helper()
+	ctx := context.Background()
+	topic := &entity.Topic{CategoryID: a.category.ID, AuthorID: 1, Title: "topic"}
+	if err := a.topicRepo.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+	poll := &entity.Poll{TopicID: topic.ID, Question: "Yes?", Results: results, Anonymous: anonymous,
+		Options: []entity.PollOption{{Text: "yes"}, {Text: "no"}}}
+	if err := a.polls.CreatePoll(ctx, userToken(1), poll); err != nil {
+		t.Fatal(err)
+	}
+	return poll
+}
+
+func TestPollResultsVisibility(t *testing.T) {
+	ctx := context.Background()
+	a := newTestApp(t)
+
+	// get returns the poll as userID sees it; 0 is a guest.
+	get := func(poll *entity.Poll, userID int) *entity.Poll {
+		t.Helper()
+		token := ""
+		if userID != 0 {
+			token = userToken(userID)
+		}
+		got, err := a.polls.GetPoll(ctx, token, poll.TopicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	vote := func(poll *entity.Poll, userID int) {
+		t.Helper()
+		if _, err := a.polls.Vote(ctx, userToken(userID), poll.TopicID, []int64{poll.Options[0].ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// visible checks whether results are shown, and that hidden ones are
+	// not leaked.
+	visible := func(name string, got *entity.Poll, want bool) {
+		t.Helper()
+		if got.ResultsVisible != want {
+			t.Errorf("%s: results visible = %v, want %v", name, got.ResultsVisible, want)
+		}
+		if want && (got.Voters != 1 || got.Options[0].Votes != 1) {
+			t.Errorf("%s: %d voters, %d yes votes; want 1 and 1", name, got.Voters, got.Options[0].Votes)
+		}
+		if !want && (got.Voters != 0 || got.Options[0].Votes != 0 || got.Options[0].VoterIDs != nil) {
+			t.Errorf("%s: hidden results leaked: %+v", name, got)
+		}
+	}
+
+	always := a.newTestPoll(t, entity.PollResultsAlways, false)
+	vote(always, 2)
+	visible("always, guest", get(always, 0), true)
+	if ids := get(always, 3).Options[0].VoterIDs; len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("public poll voters = %v, want [2]", ids)
+	}
+
+	anonymous := a.newTestPoll(t, entity.PollResultsAlways, true)
+	vote(anonymous, 2)
+	if got := get(anonymous, 3); got.Options[0].Votes != 1 || got.Options[0].VoterIDs != nil {
+		t.Errorf("anonymous poll option = %+v, want a count without voters", got.Options[0])
+	}
+
+	onVote := a.newTestPoll(t, entity.PollResultsOnVote, false)
+	vote(onVote, 2)
+	visible("on_vote, voter", get(onVote, 2), true)
+	visible("on_vote, other user", get(onVote, 3), false)
+	visible("on_vote, guest", get(onVote, 0), false)
+
+	onClose := a.newTestPoll(t, entity.PollResultsOnClose, false)
+	vote(onClose, 2)
+	visible("on_close, voter", get(onClose, 2), false)
+	if got := get(onClose, 2); len(got.MyChoices) != 1 || got.Closed {
+		t.Errorf("on_close, voter: closed %v with choices %v", got.Closed, got.MyChoices)
+	}
+
+	// Only the topic's author may close it; then results are visible to all
+	if _, err := a.polls.ClosePoll(ctx, userToken(2), onClose.TopicID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("ClosePoll by another user = %v, want ErrForbidden", err)
+	}
+	if _, err := a.polls.ClosePoll(ctx, userToken(1), onClose.TopicID); err != nil {
+		t.Fatal(err)
+	}
+	got := get(onClose, 0)
+	if !got.Closed {
+		t.Error("poll not closed")
+	}
+	visible("on_close, closed, guest", got, true)
+	if _, err := a.polls.ClosePoll(ctx, userToken(1), onVote.TopicID); err != nil {
+		t.Fatal(err)
+	}
+	visible("on_vote, closed, guest", get(onVote, 0), true)
+
+	// Ballots can no longer be cast, changed or withdrawn
+	if _, err := a.polls.Vote(ctx, userToken(3), onClose.TopicID, []int64{onClose.Options[1].ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Vote on a closed poll = %v, want ErrForbidden", err)
+	}
+	if _, err := a.polls.WithdrawVote(ctx, userToken(2), onClose.TopicID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("WithdrawVote on a closed poll = %v, want ErrForbidden", err)
+	}
+	visible("on_close, after late votes", get(onClose, 0), true)
+}
+
+func TestPollVoteValidation(t *testing.T) {
+	ctx := context.Background()
+	a := newTestApp(t)
+	poll := a.newTestPoll(t, entity.PollResultsAlways, false)
+	other := a.newTestPoll(t, entity.PollResultsAlways, false)
+
+	for _, tc := range []struct {
+		name    string
+		options []int64
+	}{
+		{"no options", nil},
+		{"two options in a single choice poll", []int64{poll.Options[0].ID, poll.Options[1].ID}},
+		{"another poll's option", []int64{other.Options[0].ID}},
+	} {
+		if _, err := a.polls.Vote(ctx, userToken(2), poll.TopicID, tc.options); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Vote with %s = %v, want ErrInvalidInput", tc.name, err)
+		}
+	}
+
+	// Repeating an option counts once
+	got, err := a.polls.Vote(ctx, userToken(2), poll.TopicID, []int64{poll.Options[1].ID, poll.Options[1].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Voters != 1 || got.Options[1].Votes != 1 {
+		t.Errorf("after voting: %d voters, %d votes for no", got.Voters, got.Options[1].Votes)
+	}
+	if _, err := a.polls.Vote(ctx, "", poll.TopicID, []int64{poll.Options[0].ID}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Vote as a guest = %v, want ErrUnauthorized", err)
+	}
+}
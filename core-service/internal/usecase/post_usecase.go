@@ -1,25 +1,500 @@
 package usecase
 
 import (
-	"core-service/internal/delivery/rest"
+	"context"
 	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/diff"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+	"core-service/internal/spam"
+)
+
+var (
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrHeldForReview = errors.New("held for review")
+	ErrRejected      = errors.New("rejected")
 )
 
+// ModerationError is returned for posts that did not pass the content
+// checks. It unwraps to ErrHeldForReview or ErrRejected.
+type ModerationError struct {
+	Review *entity.PostReview
+}
+
+func (e *ModerationError) Error() string {
+	return fmt.Sprintf("post %s: %s", e.Unwrap(), strings.Join(e.Review.Reasons, "; "))
+}
+
+func (e *ModerationError) Unwrap() error {
+	if e.Review.Status == entity.ReviewPending {
+		return ErrHeldForReview
+	}
+	return ErrRejected
+}
+
 type PostUseCase struct {
-	authClient *rest.AuthClient
-	// ... другие поля
+	authClient     *rest.AuthClient
+	postRepo       repository.PostRepository
+	topicRepo      repository.TopicRepository
+	attachmentRepo repository.AttachmentRepository
+	reviewRepo     repository.ReviewRepository
+	hub            *realtime.Hub
+	notifier       *NotificationUseCase
+	checks         *spam.Pipeline
+	reputation     *ReputationUseCase
+	roles          *Roles
+	// How long authors may edit their own posts; zero means forever.
+	editWindow time.Duration
 }
 
-func NewPostUseCase(authClient *rest.AuthClient) *PostUseCase {
+func NewPostUseCase(authClient *rest.AuthClient, postRepo repository.PostRepository, topicRepo repository.TopicRepository, attachmentRepo repository.AttachmentRepository, reviewRepo repository.ReviewRepository, hub *realtime.Hub, notifier *NotificationUseCase, checks *spam.Pipeline, reputation *ReputationUseCase, roles *Roles, editWindow time.Duration) *PostUseCase {
 	return &PostUseCase{
-		authClient: authClient,
+		authClient:     authClient,
+		postRepo:       postRepo,
+		topicRepo:      topicRepo,
+		attachmentRepo: attachmentRepo,
+		reviewRepo:     reviewRepo,
+		hub:            hub,
+		notifier:       notifier,
+		checks:         checks,
+		reputation:     reputation,
+		roles:          roles,
+		editWindow:     editWindow,
 	}
 }
 
 func (uc *PostUseCase) CreatePost(token string, post *entity.Post) error {
-	valid, err := uc.authClient.ValidateToken(token)
-	if err != nil || !valid {
-		return errors.New("unauthorized")
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	ctx := context.Background()
+	topic, err := uc.preparePost(ctx, userID, post)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkCapabilities(ctx, userID, post.Body); err != nil {
+		return err
+	}
+
+	review := &entity.PostReview{
+		AuthorID:      userID,
+		TopicID:       post.TopicID,
+		ReplyToID:     post.ReplyToID,
+		Body:          post.Body,
+		AttachmentIDs: post.AttachmentIDs,
+	}
+	if err := uc.screen(ctx, review); err != nil {
+		return err
+	}
+	return uc.publishNewPost(ctx, userID, topic, post)
+}
+
+// createPost publishes a post without running the content checks, for posts
+// that were already screened or approved by a moderator.
+func (uc *PostUseCase) createPost(ctx context.Context, userID int, post *entity.Post) error {
+	topic, err := uc.preparePost(ctx, userID, post)
+	if err != nil {
+		return err
+	}
+	return uc.publishNewPost(ctx, userID, topic, post)
+}
+
+// checkCapabilities makes sure the author's trust level allows what the post
+// contains.
+func (uc *PostUseCase) checkCapabilities(ctx context.Context, userID int, body string) error {
+	if links, _ := spam.CountLinks(body); links > 0 {
+		return uc.reputation.require(ctx, userID, CapPostLinks)
+	}
+	return nil
+}
+
+// screen runs the content checks on a submission whose body is already
+// sanitized. Anything not allowed is recorded for review together with the
+// reasons and reported as a *ModerationError. Moderators are not screened.
+func (uc *PostUseCase) screen(ctx context.Context, review *entity.PostReview) error {
+	if uc.roles.IsModerator(review.AuthorID) {
+		return nil
+	}
+
+	decision := uc.checks.Run(ctx, &spam.Submission{
+		AuthorID: review.AuthorID,
+		TopicID:  review.TopicID,
+		Title:    review.Title,
+		Body:     html.UnescapeString(review.Body),
+	})
+	if decision.Verdict == spam.Allow {
+		return nil
+	}
+
+	review.Verdict = decision.Verdict.String()
+	review.Reasons = decision.Reasons
+	review.Status = entity.ReviewPending
+	if decision.Verdict == spam.Reject {
+		review.Status = entity.ReviewRejected
+	}
+	if err := uc.reviewRepo.Create(ctx, review); err != nil {
+		return err
+	}
+	return &ModerationError{Review: review}
+}
+
+// preparePost sanitizes a new post and checks that everything it refers to
+// exists and may be used by the author.
+func (uc *PostUseCase) preparePost(ctx context.Context, userID int, post *entity.Post) (*entity.Topic, error) {
+	body, err := sanitizeBody(post.Body)
+	if err != nil {
+		return nil, err
+	}
+	post.Body = body
+
+	topic, err := liveTopic(ctx, uc.topicRepo, post.TopicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.ReplyToID != 0 {
+		parent, err := uc.livePost(ctx, post.ReplyToID)
+		if err != nil || parent.TopicID != post.TopicID {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	if len(post.AttachmentIDs) > maxAttachmentsPerPost {
+		return nil, ErrInvalidInput
+	}
+	linkable, err := uc.attachmentRepo.Linkable(ctx, userID, post.AttachmentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if !linkable {
+		return nil, ErrInvalidInput
+	}
+	return topic, nil
+}
+
+func (uc *PostUseCase) publishNewPost(ctx context.Context, userID int, topic *entity.Topic, post *entity.Post) error {
+	post.AuthorID = userID
+	if err := uc.postRepo.Create(ctx, post); err != nil {
+		return err
+	}
+	// Moderators are not screened, so their posts are not remembered either.
+	if !uc.roles.IsModerator(userID) {
+		uc.checks.Record(ctx, &spam.Submission{
+			AuthorID: userID,
+			TopicID:  post.TopicID,
+			Body:     html.UnescapeString(post.Body),
+		})
+	}
+	if err := uc.withAttachments(ctx, []*entity.Post{post}); err != nil {
+		return err
+	}
+
+	uc.hub.Publish(realtime.Event{
+		Type:       realtime.EventPostCreated,
+		TopicID:    topic.ID,
+		CategoryID: topic.CategoryID,
+		UserID:     userID,
+		Payload:    post,
+	})
+
+	if err := uc.notifier.PostCreated(ctx, topic, post); err != nil {
+		log.Printf("Error creating notifications for post %d: %v", post.ID, err)
+	}
+	if err := uc.reputation.RecordActivity(ctx, userID); err != nil {
+		log.Printf("Error recording activity of user %d: %v", userID, err)
+	}
+	return nil
+}
+
+func (uc *PostUseCase) ListTopicPosts(ctx context.Context, topicID int64, req pagination.Request) (pagination.Page[entity.Post], error) {
+	topic, err := liveTopic(ctx, uc.topicRepo, topicID)
+	if err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+
+	page, err := uc.postRepo.ListByTopic(ctx, topicID, req)
+	if err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+	if err := uc.withAttachments(ctx, pointers(page.Items)); err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+
+	// Deleted posts stay in the listing as tombstones so replies to them keep
+	// their place in the thread.
+	for i := range page.Items {
+		page.Items[i].Accepted = topic.AcceptedPostID != 0 && page.Items[i].ID == topic.AcceptedPostID
+		if page.Items[i].DeletedAt != nil {
+			page.Items[i].Body = ""
+			page.Items[i].Attachments = nil
+		}
+	}
+	return page, nil
+}
+
+// ListUserActivity returns the posts a user has written across all topics.
+func (uc *PostUseCase) ListUserActivity(ctx context.Context, userID int, req pagination.Request) (pagination.Page[entity.Post], error) {
+	page, err := uc.postRepo.ListByAuthor(ctx, userID, req)
+	if err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+	return page, uc.withAttachments(ctx, pointers(page.Items))
+}
+
+func (uc *PostUseCase) withAttachments(ctx context.Context, posts []*entity.Post) error {
+	ids := make([]int64, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+
+	attachments, err := uc.attachmentRepo.ListByPosts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		p.Attachments = attachments[p.ID]
+	}
+	return nil
+}
+
+func pointers[T any](items []T) []*T {
+	result := make([]*T, len(items))
+	for i := range items {
+		result[i] = &items[i]
+	}
+	return result
+}
+
+const maxEditReasonLength = 500
+
+// EditPost changes a post's body, keeping the previous one as a revision.
+// Authors may edit their posts within the edit window; moderators may edit
+// any post at any time, and so may trusted members edit wiki posts.
+func (uc *PostUseCase) EditPost(ctx context.Context, token string, postID int64, body, reason string) (*entity.Post, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	post, err := uc.livePost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	editsWiki := false
+	if post.Wiki {
+		if editsWiki, err = uc.reputation.Can(ctx, userID, CapEditWiki); err != nil {
+			return nil, err
+		}
+	}
+	if !uc.roles.IsModerator(userID) && !editsWiki {
+		if post.AuthorID != userID {
+			return nil, ErrForbidden
+		}
+		if uc.editWindow > 0 && time.Since(post.CreatedAt) > uc.editWindow {
+			return nil, fmt.Errorf("%w: edit window has passed", ErrForbidden)
+		}
+	}
+
+	body, err = sanitizeBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkCapabilities(ctx, userID, body); err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxEditReasonLength {
+		return nil, ErrInvalidInput
+	}
+	if body == post.Body {
+		return post, nil
+	}
+
+	post.Body = body
+	if _, err := uc.postRepo.Edit(ctx, post, userID, reason); err != nil {
+		return nil, err
+	}
+
+	uc.publishPost(ctx, realtime.EventPostEdited, userID, post)
+	return post, nil
+}
+
+// SetWiki turns a post into a wiki post, or back. Only its author and
+// moderators may do so.
+func (uc *PostUseCase) SetWiki(ctx context.Context, token string, postID int64, wiki bool) (*entity.Post, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	post, err := uc.livePost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return nil, ErrForbidden
+	}
+
+	if err := uc.postRepo.SetWiki(ctx, postID, wiki); err != nil {
+		return nil, err
+	}
+	post.Wiki = wiki
+
+	uc.publishPost(ctx, realtime.EventPostEdited, userID, post)
+	return post, nil
+}
+
+// DeletePost soft-deletes a post. It stays in its topic as a tombstone until
+// it is restored or purged after the retention period.
+func (uc *PostUseCase) DeletePost(ctx context.Context, token string, postID int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	post, err := uc.livePost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return ErrForbidden
+	}
+
+	if err := uc.postRepo.SoftDelete(ctx, postID, userID); err != nil {
+		return err
+	}
+
+	uc.publishPost(ctx, realtime.EventPostDeleted, userID, &entity.Post{ID: post.ID, TopicID: post.TopicID})
+	return nil
+}
+
+// RestorePost undoes a deletion. Moderators may restore any post; authors
+// only the ones they deleted themselves.
+func (uc *PostUseCase) RestorePost(ctx context.Context, token string, postID int64) (*entity.Post, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	post, err := uc.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if !canRestore(uc.roles, userID, post.AuthorID, post.DeletedBy) {
+		return nil, ErrForbidden
+	}
+
+	if err := uc.postRepo.Restore(ctx, postID); err != nil {
+		return nil, err
+	}
+	post.DeletedAt, post.DeletedBy = nil, 0
+	if err := uc.withAttachments(ctx, []*entity.Post{post}); err != nil {
+		return nil, err
+	}
+
+	uc.publishPost(ctx, realtime.EventPostRestored, userID, post)
+	return post, nil
+}
+
+func (uc *PostUseCase) publishPost(ctx context.Context, eventType realtime.EventType, userID int, post *entity.Post) {
+	topic, err := uc.topicRepo.GetByID(ctx, post.TopicID)
+	if err != nil {
+		return
+	}
+	uc.hub.Publish(realtime.Event{
+		Type:       eventType,
+		TopicID:    topic.ID,
+		CategoryID: topic.CategoryID,
+		UserID:     userID,
+		Payload:    post,
+	})
+}
+
+// livePost loads a post, treating deleted posts as missing.
+func (uc *PostUseCase) livePost(ctx context.Context, id int64) (*entity.Post, error) {
+	post, err := uc.postRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if post.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return post, nil
+}
+
+func liveTopic(ctx context.Context, topicRepo repository.TopicRepository, id int64) (*entity.Topic, error) {
+	topic, err := topicRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if topic.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return topic, nil
+}
+
+// canRestore reports whether userID may undo a deletion: moderators always,
+// owners only if they deleted the content themselves.
+func canRestore(roles *Roles, userID, ownerID, deletedBy int) bool {
+	return roles.IsModerator(userID) || (userID == ownerID && deletedBy == userID)
+}
+
+type RevisionDiff struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Unified string      `json:"unified,omitempty"`
+	Words   []diff.Edit `json:"words,omitempty"`
+}
+
+// Revisions lists a post's revisions. When from and to are both non-zero, the
+// diff between those two revisions is returned as well, either in unified
+// format or word by word.
+func (uc *PostUseCase) Revisions(ctx context.Context, postID int64, from, to int, wordLevel bool) ([]entity.PostRevision, *RevisionDiff, error) {
+	if _, err := uc.livePost(ctx, postID); err != nil {
+		return nil, nil, err
+	}
+
+	revisions, err := uc.postRepo.ListRevisions(ctx, postID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from == 0 && to == 0 {
+		return revisions, nil, nil
+	}
+
+	var fromRev, toRev *entity.PostRevision
+	for i := range revisions {
+		if revisions[i].Number == from {
+			fromRev = &revisions[i]
+		}
+		if revisions[i].Number == to {
+			toRev = &revisions[i]
+		}
+	}
+	if fromRev == nil || toRev == nil {
+		return nil, nil, fmt.Errorf("%w: unknown revision", ErrInvalidInput)
+	}
+
+	d := &RevisionDiff{From: from, To: to}
+	if wordLevel {
+		d.Words = diff.Words(fromRev.Body, toRev.Body)
+	} else {
+		d.Unified = diff.Unified(fromRev.Body, toRev.Body,
+			fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to), 3)
 	}
-	// ... остальная логика
+	return revisions, d, nil
 }
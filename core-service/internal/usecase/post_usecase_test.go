@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/spam"
+)
+
+// holdCheck holds posts asking for it, like a check a moderator must clear.
+type holdCheck struct{}
+
+func (holdCheck) Name() string { return "hold" }
+
+func (holdCheck) Check(_ context.Context, s *spam.Submission) (spam.Result, error) {
+	if strings.Contains(s.Body, "[hold]") {
+		return spam.Result{Verdict: spam.Hold, Reason: "asked for it"}, nil
+	}
+	return spam.Result{}, nil
+}
+
+func TestCreatePostDuplicateRetry(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyPostRepository{}
+	a := newTestApp(t, func(a *testApp) {
+		flaky.PostRepository = a.postRepo
+		a.postRepo = flaky
+		a.checks = spam.NewPipeline(spam.NewDuplicateCheck(a.spamRepo, time.Hour), holdCheck{})
+	})
+	topic := &entity.Topic{CategoryID: a.category.ID, AuthorID: 2, Title: "topic"}
+	if err := a.topicRepo.Create(ctx, topic); err != nil {
+		t.Fatal(err)
+	}
+	create := func(body string) error {
+		return a.posts.CreatePost(userToken(1), &entity.Post{TopicID: topic.ID, Body: body})
+	}
+	reasons := func(err error) []string {
+		var moderation *ModerationError
+		if !errors.As(err, &moderation) {
+			t.Fatalf("CreatePost = %v, want a ModerationError", err)
+		}
+		return moderation.Review.Reasons
+	}
+
+	// Held posts are not remembered, so the author may post them again
+	const held = "[hold] Is this allowed here, or should I ask elsewhere?"
+	for range 2 {
+		if got := reasons(create(held)); !slices.Equal(got, []string{"hold: asked for it"}) {
+			t.Errorf("held post reasons = %q", got)
+		}
+	}
+
+	// Nor are posts that failed to be stored
+	const body = "Here is how I solved it in the end, step by step."
+	flaky.fail = 1
+	if err := create(body); err == nil || errors.As(err, new(*ModerationError)) {
+		t.Fatalf("CreatePost with a failing database = %v", err)
+	}
+	if err := create(body); err != nil {
+		t.Fatalf("CreatePost retry = %v", err)
+	}
+	if err := create(body); !errors.Is(err, ErrRejected) {
+		t.Errorf("CreatePost of a published duplicate = %v, want ErrRejected", err)
+	}
+}
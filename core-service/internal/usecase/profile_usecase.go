@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 2000
+	maxSignatureLength   = 300
+	maxLocationLength    = 100
+	// profileRecentItems is how many recent topics and posts a profile page
+	// shows.
+	profileRecentItems = 5
+)
+
+var defaultPrivacy = entity.ProfilePrivacy{
+	Location: entity.VisibilityPublic,
+	LastSeen: entity.VisibilityPublic,
+	Activity: entity.VisibilityPublic,
+}
+
+// ProfilePage is what a user's public page shows. The recent lists are
+// left out if the user hides their activity from the viewer.
+type ProfilePage struct {
+	Profile      *entity.Profile `json:"profile"`
+	RecentTopics []entity.Topic  `json:"recent_topics,omitempty"`
+	RecentPosts  []entity.Post   `json:"recent_posts,omitempty"`
+}
+
+type ProfileUseCase struct {
+	authClient     *rest.AuthClient
+	profileRepo    repository.ProfileRepository
+	postRepo       repository.PostRepository
+	topicRepo      repository.TopicRepository
+	attachmentRepo repository.AttachmentRepository
+	posts          *PostUseCase
+	tags           *TagUseCase
+	roles          *Roles
+}
+
+func NewProfileUseCase(authClient *rest.AuthClient, profileRepo repository.ProfileRepository, postRepo repository.PostRepository, topicRepo repository.TopicRepository, attachmentRepo repository.AttachmentRepository, posts *PostUseCase, tags *TagUseCase, roles *Roles) *ProfileUseCase {
+	return &ProfileUseCase{
+		authClient:     authClient,
+		profileRepo:    profileRepo,
+		postRepo:       postRepo,
+		topicRepo:      topicRepo,
+		attachmentRepo: attachmentRepo,
+		posts:          posts,
+		tags:           tags,
+		roles:          roles,
+	}
+}
+
+// GetOwnProfile returns the caller's full profile including their privacy
+// settings, creating it on first use.
+func (uc *ProfileUseCase) GetOwnProfile(ctx context.Context, token string) (*entity.Profile, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	if err := uc.profileRepo.Touch(ctx, []int{userID}, time.Now()); err != nil {
+		return nil, err
+	}
+	return uc.load(ctx, userID)
+}
+
+// UpdateProfile replaces the editable fields of the caller's profile.
+func (uc *ProfileUseCase) UpdateProfile(ctx context.Context, token string, update *entity.Profile) (*entity.Profile, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	profile, err := uc.load(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		privacy := defaultPrivacy
+		profile = &entity.Profile{UserID: userID, Privacy: &privacy}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if profile.DisplayName, err = sanitizeField(update.DisplayName, maxDisplayNameLength, false); err != nil {
+		return nil, fmt.Errorf("%w: display name is limited to %d characters", ErrInvalidInput, maxDisplayNameLength)
+	}
+	if profile.Bio, err = sanitizeField(update.Bio, maxBioLength, true); err != nil {
+		return nil, fmt.Errorf("%w: bio is limited to %d characters", ErrInvalidInput, maxBioLength)
+	}
+	if profile.Signature, err = sanitizeField(update.Signature, maxSignatureLength, true); err != nil {
+		return nil, fmt.Errorf("%w: signature is limited to %d characters", ErrInvalidInput, maxSignatureLength)
+	}
+	if profile.Location, err = sanitizeField(update.Location, maxLocationLength, false); err != nil {
+		return nil, fmt.Errorf("%w: location is limited to %d characters", ErrInvalidInput, maxLocationLength)
+	}
+
+	if update.AvatarID != 0 && update.AvatarID != profile.AvatarID {
+		avatar, err := uc.attachmentRepo.GetByID(ctx, update.AvatarID)
+		if err != nil || avatar.DeletedAt != nil || avatar.UploaderID != userID {
+			return nil, fmt.Errorf("%w: avatar must be an image you uploaded", ErrInvalidInput)
+		}
+		if !strings.HasPrefix(avatar.ContentType, "image/") {
+			return nil, fmt.Errorf("%w: avatar must be an image you uploaded", ErrInvalidInput)
+		}
+	}
+	profile.AvatarID = update.AvatarID
+
+	if update.Privacy != nil {
+		for _, v := range []entity.Visibility{update.Privacy.Location, update.Privacy.LastSeen, update.Privacy.Activity} {
+			if !validVisibility(v) {
+				return nil, fmt.Errorf("%w: unknown visibility %q", ErrInvalidInput, v)
+			}
+		}
+		profile.Privacy = update.Privacy
+	}
+
+	if err := uc.profileRepo.Save(ctx, profile); err != nil {
+		return nil, err
+	}
+	return uc.load(ctx, userID)
+}
+
+func validVisibility(v entity.Visibility) bool {
+	switch v {
+	case entity.VisibilityPublic, entity.VisibilityMembers, entity.VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// GetProfilePage returns a user's profile with their recent topics and
+// posts as the viewer may see them. The token is optional.
+func (uc *ProfileUseCase) GetProfilePage(ctx context.Context, token string, userID int) (*ProfilePage, error) {
+	viewer, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := uc.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	page := &ProfilePage{Profile: profile}
+	if !uc.canSee(profile.Privacy.Activity, viewer, userID) {
+		uc.redact(profile, viewer)
+		return page, nil
+	}
+
+	req := pagination.Request{Sort: pagination.SortNewest, Limit: profileRecentItems}
+	topics, err := uc.topicRepo.ListByAuthor(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.tags.withTags(ctx, pointers(topics.Items)); err != nil {
+		return nil, err
+	}
+	posts, err := uc.posts.ListUserActivity(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	page.RecentTopics, page.RecentPosts = topics.Items, posts.Items
+	uc.redact(profile, viewer)
+	return page, nil
+}
+
+// ListTopics pages through the topics a user opened, if their privacy
+// settings let the viewer see them.
+func (uc *ProfileUseCase) ListTopics(ctx context.Context, token string, userID int, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	if err := uc.requireActivity(ctx, token, userID); err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	page, err := uc.topicRepo.ListByAuthor(ctx, userID, req)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	return page, uc.tags.withTags(ctx, pointers(page.Items))
+}
+
+// ListPosts pages through a user's posts, if their privacy settings let the
+// viewer see them.
+func (uc *ProfileUseCase) ListPosts(ctx context.Context, token string, userID int, req pagination.Request) (pagination.Page[entity.Post], error) {
+	if err := uc.requireActivity(ctx, token, userID); err != nil {
+		return pagination.Page[entity.Post]{}, err
+	}
+	return uc.posts.ListUserActivity(ctx, userID, req)
+}
+
+func (uc *ProfileUseCase) requireActivity(ctx context.Context, token string, userID int) error {
+	viewer, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return err
+	}
+
+	profile, err := uc.load(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Users who never posted have nothing to hide either.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !uc.canSee(profile.Privacy.Activity, viewer, userID) {
+		return fmt.Errorf("%w: activity is hidden", ErrForbidden)
+	}
+	return nil
+}
+
+// load returns a user's profile. Users who posted before profiles existed
+// get one on first sight, dated from their first post.
+func (uc *ProfileUseCase) load(ctx context.Context, userID int) (*entity.Profile, error) {
+	profile, err := uc.profileRepo.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		firstPost, err := uc.postRepo.FirstPostAt(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		privacy := defaultPrivacy
+		profile = &entity.Profile{UserID: userID, JoinedAt: firstPost, Privacy: &privacy}
+		if err := uc.profileRepo.Save(ctx, profile); err != nil {
+			return nil, err
+		}
+		return uc.profileRepo.Get(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.AvatarID != 0 {
+		profile.AvatarURL = fmt.Sprintf("/attachments/%d/content", profile.AvatarID)
+	}
+	return profile, nil
+}
+
+// canSee applies a visibility setting of owner's profile to the viewer, with
+// 0 standing for a guest.
+func (uc *ProfileUseCase) canSee(v entity.Visibility, viewer, owner int) bool {
+	if viewer != 0 && (viewer == owner || uc.roles.IsModerator(viewer)) {
+		return true
+	}
+	switch v {
+	case entity.VisibilityPublic:
+		return true
+	case entity.VisibilityMembers:
+		return viewer != 0
+	}
+	return false
+}
+
+// redact removes what the viewer may not see from a loaded profile.
+func (uc *ProfileUseCase) redact(profile *entity.Profile, viewer int) {
+	privacy := profile.Privacy
+	if !uc.canSee(privacy.Location, viewer, profile.UserID) {
+		profile.Location = ""
+	}
+	if !uc.canSee(privacy.LastSeen, viewer, profile.UserID) {
+		profile.LastSeenAt = nil
+	}
+	if viewer != profile.UserID {
+		profile.Privacy = nil
+	}
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"core-service/internal/repository"
+	"core-service/internal/storage"
+)
+
+const purgeBatchSize = 100
+
+// PurgeUseCase permanently removes soft-deleted content once it has been
+// deleted for longer than the retention period.
+type PurgeUseCase struct {
+	topicRepo      repository.TopicRepository
+	postRepo       repository.PostRepository
+	attachmentRepo repository.AttachmentRepository
+	spamRepo       repository.SpamRepository
+	draftRepo      repository.DraftRepository
+	store          storage.BlobStore
+	retention      time.Duration
+	draftRetention time.Duration
+}
+
+func NewPurgeUseCase(topicRepo repository.TopicRepository, postRepo repository.PostRepository, attachmentRepo repository.AttachmentRepository, spamRepo repository.SpamRepository, draftRepo repository.DraftRepository, store storage.BlobStore, retention, draftRetention time.Duration) *PurgeUseCase {
+	return &PurgeUseCase{
+		topicRepo:      topicRepo,
+		postRepo:       postRepo,
+		attachmentRepo: attachmentRepo,
+		spamRepo:       spamRepo,
+		draftRepo:      draftRepo,
+		store:          store,
+		retention:      retention,
+		draftRetention: draftRetention,
+	}
+}
+
+// Purge removes, in order, expired topics with all their posts, expired
+// posts, and finally attachments of either as well as expired attachments.
+// Duplicate-content fingerprints older than the retention period go too, as
+// do drafts left untouched for longer than the draft retention period.
+func (uc *PurgeUseCase) Purge(ctx context.Context) error {
+	cutoff := time.Now().Add(-uc.retention)
+
+	for {
+		topicIDs, err := uc.topicRepo.ListDeletedBefore(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, topicID := range topicIDs {
+			postIDs, err := uc.postRepo.ListIDsByTopic(ctx, topicID)
+			if err != nil {
+				return err
+			}
+			if err := uc.postRepo.Purge(ctx, postIDs); err != nil {
+				return err
+			}
+			if err := uc.topicRepo.Purge(ctx, topicID); err != nil {
+				return err
+			}
+		}
+		if len(topicIDs) < purgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		postIDs, err := uc.postRepo.ListDeletedBefore(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return err
+		}
+		if err := uc.postRepo.Purge(ctx, postIDs); err != nil {
+			return err
+		}
+		if len(postIDs) < purgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		attachments, err := uc.attachmentRepo.ListPurgeable(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		// Blobs go first: a row without a blob is retried next time, a blob
+		// without a row would leak.
+		ids := make([]int64, 0, len(attachments))
+		for _, a := range attachments {
+			if err := uc.store.Delete(ctx, a.StorageKey); err != nil {
+				log.Printf("Error deleting blob %s: %v", a.StorageKey, err)
+				continue
+			}
+			if a.ThumbnailKey != "" {
+				if err := uc.store.Delete(ctx, a.ThumbnailKey); err != nil {
+					log.Printf("Error deleting blob %s: %v", a.ThumbnailKey, err)
+				}
+			}
+			ids = append(ids, a.ID)
+		}
+		if err := uc.attachmentRepo.Purge(ctx, ids); err != nil {
+			return err
+		}
+		if len(attachments) < purgeBatchSize || len(ids) == 0 {
+			break
+		}
+	}
+
+	if err := uc.spamRepo.PruneFingerprints(ctx, cutoff); err != nil {
+		return err
+	}
+	return uc.draftRepo.DeleteOlderThan(ctx, time.Now().Add(-uc.draftRetention))
+}
+
+// Функция для периодической очистки удалённого контента
+func PurgeDeletedPeriodically(ctx context.Context, purger *PurgeUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := purger.Purge(ctx); err != nil {
+				log.Printf("Error purging deleted content: %v", err)
+			}
+		}
+	}
+}
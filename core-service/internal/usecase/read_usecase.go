@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+)
+
+// maxPendingReads is how many read markers are buffered before a write is
+// forced ahead of the next periodic flush.
+const maxPendingReads = 1000
+
+type readKey struct {
+	userID  int
+	topicID int64
+}
+
+// ReadUseCase tracks how far users have read each topic. Clients report
+// what they have seen as they scroll, so markers are collected in memory
+// and written in batches. The same reports keep users' last seen time
+// current.
+type ReadUseCase struct {
+	authClient   *rest.AuthClient
+	readRepo     repository.ReadRepository
+	topicRepo    repository.TopicRepository
+	categoryRepo repository.CategoryRepository
+	postRepo     repository.PostRepository
+	profileRepo  repository.ProfileRepository
+
+	mu      sync.Mutex
+	pending map[readKey]int64
+}
+
+func NewReadUseCase(authClient *rest.AuthClient, readRepo repository.ReadRepository, topicRepo repository.TopicRepository, categoryRepo repository.CategoryRepository, postRepo repository.PostRepository, profileRepo repository.ProfileRepository) *ReadUseCase {
+	return &ReadUseCase{
+		authClient:   authClient,
+		readRepo:     readRepo,
+		topicRepo:    topicRepo,
+		categoryRepo: categoryRepo,
+		postRepo:     postRepo,
+		profileRepo:  profileRepo,
+		pending:      make(map[readKey]int64),
+	}
+}
+
+// MarkRead records that the user has read the topic up to the given post.
+// Markers never move back, so reporting an older post is harmless.
+func (uc *ReadUseCase) MarkRead(ctx context.Context, token string, topicID, postID int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	post, err := uc.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.TopicID != topicID {
+		return ErrInvalidInput
+	}
+
+	uc.mu.Lock()
+	key := readKey{userID: userID, topicID: topicID}
+	if postID > uc.pending[key] {
+		uc.pending[key] = postID
+	}
+	full := len(uc.pending) >= maxPendingReads
+	uc.mu.Unlock()
+
+	if full {
+		return uc.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes all buffered markers. Markers that fail to save are dropped,
+// so that a batch that cannot be written does not hold up every later one.
+func (uc *ReadUseCase) Flush(ctx context.Context) error {
+	return uc.flush(ctx, func(readKey) bool { return true })
+}
+
+// flushUser writes one user's buffered markers, so what they read a moment
+// ago shows up in their own listings right away.
+func (uc *ReadUseCase) flushUser(ctx context.Context, userID int) error {
+	return uc.flush(ctx, func(k readKey) bool { return k.userID == userID })
+}
+
+func (uc *ReadUseCase) flush(ctx context.Context, match func(readKey) bool) error {
+	uc.mu.Lock()
+	var markers []entity.ReadMarker
+	for k, postID := range uc.pending {
+		if match(k) {
+			markers = append(markers, entity.ReadMarker{UserID: k.userID, TopicID: k.topicID, PostID: postID})
+			delete(uc.pending, k)
+		}
+	}
+	uc.mu.Unlock()
+
+	if err := uc.readRepo.SaveMarkers(ctx, markers); err != nil {
+		return err
+	}
+
+	seen := make(map[int]bool)
+	var userIDs []int
+	for _, m := range markers {
+		if !seen[m.UserID] {
+			seen[m.UserID] = true
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	return uc.profileRepo.Touch(ctx, userIDs, time.Now())
+}
+
+// MarkCategoryRead marks all topics of a category as read.
+func (uc *ReadUseCase) MarkCategoryRead(ctx context.Context, token string, categoryID int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if _, err := uc.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return err
+	}
+	return uc.readRepo.MarkCategoryRead(ctx, userID, categoryID)
+}
+
+// ListUnread returns the topics the user has read before that have new
+// posts, with their read state.
+func (uc *ReadUseCase) ListUnread(ctx context.Context, token string, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, ErrUnauthorized
+	}
+	if err := uc.flushUser(ctx, userID); err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	page, err := uc.topicRepo.ListUnread(ctx, userID, req)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	return page, uc.withReadState(ctx, userID, pointers(page.Items))
+}
+
+// withReadState adds the user's read state to topics. Guests (user 0) have
+// none.
+func (uc *ReadUseCase) withReadState(ctx context.Context, userID int, topics []*entity.Topic) error {
+	if userID == 0 || len(topics) == 0 {
+		return nil
+	}
+	if err := uc.flushUser(ctx, userID); err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(topics))
+	for i, t := range topics {
+		ids[i] = t.ID
+	}
+	states, err := uc.readRepo.States(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+	for _, t := range topics {
+		if s, ok := states[t.ID]; ok {
+			t.Read = &s
+		}
+	}
+	return nil
+}
+
+// viewerID resolves an optional token; without one the caller is a guest
+// with user id 0.
+func viewerID(authClient *rest.AuthClient, token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	userID, err := authClient.ResolveUserID(token)
+	if err != nil {
+		return 0, ErrUnauthorized
+	}
+	return userID, nil
+}
+
+// Функция для периодической записи отметок о прочтении
+func FlushReadsPeriodically(ctx context.Context, reads *ReadUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := reads.Flush(ctx); err != nil {
+				log.Printf("Error saving read markers: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+)
+
+// failingReadRepository fails every write, like a batch SQLite rejects.
+type failingReadRepository struct {
+	repository.ReadRepository
+	calls int
+}
+
+func (r *failingReadRepository) SaveMarkers(_ context.Context, markers []entity.ReadMarker) error {
+	if len(markers) == 0 {
+		return nil
+	}
+	r.calls++
+	return errors.New("constraint failed")
+}
+
+func TestReadFlush(t *testing.T) {
+	ctx := context.Background()
+	db, err := repository.OpenSQLiteDB(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	categoryRepo := repository.NewSQLiteCategoryRepository(db)
+	topicRepo := repository.NewSQLiteTopicRepository(db)
+	readRepo := repository.NewSQLiteReadRepository(db)
+	reads := NewReadUseCase(nil, readRepo, topicRepo, categoryRepo,
+		repository.NewSQLitePostRepository(db), repository.NewSQLiteProfileRepository(db))
+
+	category := &entity.Category{Name: "general"}
+	if err := categoryRepo.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	kept := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "kept"}
+	purged := &entity.Topic{CategoryID: category.ID, AuthorID: 1, Title: "purged"}
+	for _, topic := range []*entity.Topic{kept, purged} {
+		if err := topicRepo.Create(ctx, topic); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The topic is purged between MarkRead and the flush
+	reads.pending[readKey{userID: 1, topicID: purged.ID}] = 1
+	reads.pending[readKey{userID: 2, topicID: kept.ID}] = 2
+	if err := topicRepo.Purge(ctx, purged.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := reads.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(reads.pending) != 0 {
+		t.Errorf("%d markers still pending", len(reads.pending))
+	}
+	if states, err := readRepo.States(ctx, 2, []int64{kept.ID}); err != nil || states[kept.ID].LastReadPostID != 2 {
+		t.Errorf("States = %+v, %v", states, err)
+	}
+
+	// A batch that cannot be saved is not tried again and again
+	failing := &failingReadRepository{ReadRepository: readRepo}
+	reads.readRepo = failing
+	reads.pending[readKey{userID: 3, topicID: kept.ID}] = 2
+	if err := reads.Flush(ctx); err == nil {
+		t.Fatal("Flush hid the error")
+	}
+	if err := reads.Flush(ctx); err != nil || failing.calls != 1 {
+		t.Errorf("second Flush = %v after %d writes, want the batch dropped", err, failing.calls)
+	}
+}
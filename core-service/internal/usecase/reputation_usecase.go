@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+)
+
+const (
+	pointsUpvote         = 10
+	pointsDownvote       = -2
+	pointsAcceptedAnswer = 15
+	pointsDayActive      = 1
+)
+
+// trustRequirements lists what it takes to reach each level above TrustNew.
+// Levels are derived from the ledger totals alone, so recomputing them always
+// gives the same result.
+var trustRequirements = []struct {
+	level      entity.TrustLevel
+	points     int
+	daysActive int
+}{
+	{entity.TrustBasic, 5, 2},
+	{entity.TrustMember, 50, 10},
+	{entity.TrustRegular, 250, 30},
+}
+
+type Capability string
+
+const (
+	CapPostLinks   Capability = "post links"
+	CapAttachFiles Capability = "attach files"
+	CapEditWiki    Capability = "edit wiki posts"
+)
+
+var capabilityLevels = map[Capability]entity.TrustLevel{
+	CapPostLinks:   entity.TrustBasic,
+	CapAttachFiles: entity.TrustBasic,
+	CapEditWiki:    entity.TrustMember,
+}
+
+// flagWeights is how much a flag counts towards hiding a post, by the trust
+// level of the user raising it.
+var flagWeights = map[entity.TrustLevel]int{
+	entity.TrustNew:     1,
+	entity.TrustBasic:   1,
+	entity.TrustMember:  2,
+	entity.TrustRegular: 3,
+}
+
+// ReputationUseCase keeps users' reputation and trust levels in step with the
+// event ledger and answers what a user is trusted to do.
+type ReputationUseCase struct {
+	authClient     *rest.AuthClient
+	reputationRepo repository.ReputationRepository
+	roles          *Roles
+}
+
+func NewReputationUseCase(authClient *rest.AuthClient, reputationRepo repository.ReputationRepository, roles *Roles) *ReputationUseCase {
+	return &ReputationUseCase{
+		authClient:     authClient,
+		reputationRepo: reputationRepo,
+		roles:          roles,
+	}
+}
+
+func (uc *ReputationUseCase) Get(ctx context.Context, userID int) (*entity.Reputation, error) {
+	return uc.reputationRepo.Get(ctx, userID)
+}
+
+// RecordActivity counts today as a day the user was active.
+func (uc *ReputationUseCase) RecordActivity(ctx context.Context, userID int) error {
+	day := time.Now().UTC().Unix() / int64(24*time.Hour/time.Second)
+	return uc.award(ctx, &entity.ReputationEvent{
+		UserID:   userID,
+		Kind:     entity.ReputationDayActive,
+		SourceID: day,
+		ActorID:  userID,
+		Points:   pointsDayActive,
+	})
+}
+
+func (uc *ReputationUseCase) award(ctx context.Context, e *entity.ReputationEvent) error {
+	if err := uc.reputationRepo.RecordEvent(ctx, e); err != nil {
+		return err
+	}
+	_, err := uc.refresh(ctx, e.UserID)
+	return err
+}
+
+func (uc *ReputationUseCase) revoke(ctx context.Context, userID int, kind entity.ReputationEventKind, sourceID int64, actorID int) error {
+	if err := uc.reputationRepo.RemoveEvent(ctx, kind, sourceID, actorID); err != nil {
+		return err
+	}
+	_, err := uc.refresh(ctx, userID)
+	return err
+}
+
+// refresh recomputes a user's reputation and trust level from the ledger.
+func (uc *ReputationUseCase) refresh(ctx context.Context, userID int) (*entity.Reputation, error) {
+	points, days, err := uc.reputationRepo.Totals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := uc.reputationRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &entity.Reputation{UserID: userID, Points: points, DaysActive: days, TrustLevel: trustLevelFor(points, days)}
+	if err := uc.reputationRepo.Save(ctx, rep); err != nil {
+		return nil, err
+	}
+	if rep.TrustLevel > previous.TrustLevel {
+		log.Printf("User %d promoted to trust level %s", userID, rep.TrustLevel)
+	}
+	return rep, nil
+}
+
+func trustLevelFor(points, daysActive int) entity.TrustLevel {
+	level := entity.TrustNew
+	for _, req := range trustRequirements {
+		if points >= req.points && daysActive >= req.daysActive {
+			level = req.level
+		}
+	}
+	return level
+}
+
+// Recompute rebuilds every user's reputation from the ledger and returns how
+// many users were updated.
+func (uc *ReputationUseCase) Recompute(ctx context.Context, token string) (int, error) {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return 0, err
+	}
+
+	userIDs, err := uc.reputationRepo.ListUserIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, userID := range userIDs {
+		if _, err := uc.refresh(ctx, userID); err != nil {
+			return i, err
+		}
+	}
+	return len(userIDs), nil
+}
+
+// TrustLevel returns the user's level. Moderators are trusted fully.
+func (uc *ReputationUseCase) TrustLevel(ctx context.Context, userID int) (entity.TrustLevel, error) {
+	if uc.roles.IsModerator(userID) {
+		return entity.TrustRegular, nil
+	}
+	rep, err := uc.reputationRepo.Get(ctx, userID)
+	if err != nil {
+		return entity.TrustNew, err
+	}
+	return rep.TrustLevel, nil
+}
+
+func (uc *ReputationUseCase) Can(ctx context.Context, userID int, c Capability) (bool, error) {
+	level, err := uc.TrustLevel(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return level >= capabilityLevels[c], nil
+}
+
+// require fails with ErrForbidden unless the user may use the capability.
+func (uc *ReputationUseCase) require(ctx context.Context, userID int, c Capability) error {
+	ok, err := uc.Can(ctx, userID, c)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: trust level %s is needed to %s", ErrForbidden, capabilityLevels[c], c)
+	}
+	return nil
+}
+
+func (uc *ReputationUseCase) FlagWeight(ctx context.Context, userID int) (int, error) {
+	level, err := uc.TrustLevel(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return flagWeights[level], nil
+}
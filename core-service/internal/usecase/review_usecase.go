@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"html"
+	"log"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+	"core-service/internal/spam"
+)
+
+// ReviewUseCase lets moderators work through posts held by the content
+// checks. Their decisions train the spam classifier.
+type ReviewUseCase struct {
+	authClient *rest.AuthClient
+	reviewRepo repository.ReviewRepository
+	posts      *PostUseCase
+	topics     *TopicUseCase
+	classifier *spam.BayesCheck
+	roles      *Roles
+}
+
+func NewReviewUseCase(authClient *rest.AuthClient, reviewRepo repository.ReviewRepository, posts *PostUseCase, topics *TopicUseCase, classifier *spam.BayesCheck, roles *Roles) *ReviewUseCase {
+	return &ReviewUseCase{
+		authClient: authClient,
+		reviewRepo: reviewRepo,
+		posts:      posts,
+		topics:     topics,
+		classifier: classifier,
+		roles:      roles,
+	}
+}
+
+func (uc *ReviewUseCase) ListReviews(ctx context.Context, token string, status entity.ReviewStatus, req pagination.Request) (pagination.Page[entity.PostReview], error) {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return pagination.Page[entity.PostReview]{}, err
+	}
+
+	switch status {
+	case "":
+		status = entity.ReviewPending
+	case entity.ReviewPending, entity.ReviewApproved, entity.ReviewSpam, entity.ReviewRejected:
+	default:
+		return pagination.Page[entity.PostReview]{}, ErrInvalidInput
+	}
+	return uc.reviewRepo.ListByStatus(ctx, status, req)
+}
+
+// Approve publishes a held post as its author, opening its topic first if it
+// was submitted as a new topic.
+func (uc *ReviewUseCase) Approve(ctx context.Context, token string, id int64) (*entity.Post, error) {
+	moderatorID, err := uc.roles.requireModerator(uc.authClient, token)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := uc.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reviewRepo.Resolve(ctx, id, entity.ReviewApproved, moderatorID); err != nil {
+		return nil, err
+	}
+
+	post, err := uc.publish(ctx, review)
+	if err != nil {
+		if err := uc.reviewRepo.Reopen(ctx, id); err != nil {
+			log.Printf("Error reopening review %d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	uc.train(ctx, review, false)
+	return post, nil
+}
+
+func (uc *ReviewUseCase) publish(ctx context.Context, review *entity.PostReview) (*entity.Post, error) {
+	body := html.UnescapeString(review.Body)
+	if review.TopicID == 0 {
+		topic := &entity.Topic{CategoryID: review.CategoryID, Title: review.Title}
+		return uc.topics.createTopic(ctx, review.AuthorID, topic, body, nil)
+	}
+
+	post := &entity.Post{
+		TopicID:       review.TopicID,
+		ReplyToID:     review.ReplyToID,
+		Body:          body,
+		AttachmentIDs: review.AttachmentIDs,
+	}
+	if err := uc.posts.createPost(ctx, review.AuthorID, post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// MarkSpam discards a held post and trains the classifier on it.
+func (uc *ReviewUseCase) MarkSpam(ctx context.Context, token string, id int64) error {
+	moderatorID, err := uc.roles.requireModerator(uc.authClient, token)
+	if err != nil {
+		return err
+	}
+
+	review, err := uc.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.reviewRepo.Resolve(ctx, id, entity.ReviewSpam, moderatorID); err != nil {
+		return err
+	}
+
+	uc.train(ctx, review, true)
+	return nil
+}
+
+// MarkPostSpam deletes a published post that slipped through the checks and
+// trains the classifier on it.
+func (uc *ReviewUseCase) MarkPostSpam(ctx context.Context, token string, postID int64) error {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return err
+	}
+
+	post, err := uc.posts.livePost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if err := uc.posts.DeletePost(ctx, token, postID); err != nil {
+		return err
+	}
+
+	if err := uc.classifier.Train(ctx, html.UnescapeString(post.Body), true); err != nil {
+		log.Printf("Error training spam classifier on post %d: %v", postID, err)
+	}
+	return nil
+}
+
+func (uc *ReviewUseCase) train(ctx context.Context, review *entity.PostReview, isSpam bool) {
+	text := html.UnescapeString(review.Body)
+	if review.Title != "" {
+		text = review.Title + "\n" + text
+	}
+	if err := uc.classifier.Train(ctx, text, isSpam); err != nil {
+		log.Printf("Error training spam classifier on review %d: %v", review.ID, err)
+	}
+}
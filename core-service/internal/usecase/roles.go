@@ -0,0 +1,33 @@
+package usecase
+
+import "core-service/internal/controllers/rest"
+
+// Roles answers which users hold elevated permissions in the forum.
+type Roles struct {
+	moderators map[int]bool
+}
+
+func NewRoles(moderatorIDs []int) *Roles {
+	moderators := make(map[int]bool, len(moderatorIDs))
+	for _, id := range moderatorIDs {
+		moderators[id] = true
+	}
+	return &Roles{moderators: moderators}
+}
+
+func (r *Roles) IsModerator(userID int) bool {
+	return r.moderators[userID]
+}
+
+// requireModerator resolves the token and fails unless it belongs to a
+// moderator.
+func (r *Roles) requireModerator(authClient *rest.AuthClient, token string) (int, error) {
+	userID, err := authClient.ResolveUserID(token)
+	if err != nil {
+		return 0, ErrUnauthorized
+	}
+	if !r.IsModerator(userID) {
+		return 0, ErrForbidden
+	}
+	return userID, nil
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxBodyLength = 32000
+
+// sanitizeBody normalizes user-written text before it is stored: line endings
+// are unified, control characters other than newlines and tabs are dropped
+// and HTML is escaped. Empty or oversized bodies are rejected.
+func sanitizeBody(body string) (string, error) {
+	body = cleanText(body, true)
+	if body == "" || utf8.RuneCountInString(body) > maxBodyLength {
+		return "", ErrInvalidInput
+	}
+	return html.EscapeString(body), nil
+}
+
+// sanitizeField does the same for short, optional profile fields; newlines
+// are kept only if multiline is set.
+func sanitizeField(text string, maxLength int, multiline bool) (string, error) {
+	text = cleanText(text, multiline)
+	if utf8.RuneCountInString(text) > maxLength {
+		return "", ErrInvalidInput
+	}
+	return html.EscapeString(text), nil
+}
+
+func cleanText(text string, multiline bool) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.Map(func(r rune) rune {
+		if multiline && (r == '\n' || r == '\t') {
+			return r
+		}
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, text)
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+)
+
+const (
+	scheduleBatchSize = 50
+	// maxScheduleAhead is how far in the future something may be scheduled.
+	maxScheduleAhead = 365 * 24 * time.Hour
+	// maxScheduleAttempts bounds retries of items failing for reasons other
+	// than their content, such as a busy database.
+	maxScheduleAttempts = 5
+	// scheduleClaimTimeout is after how long an item still marked as
+	// publishing is assumed to belong to a scheduler that died.
+	scheduleClaimTimeout = 10 * time.Minute
+	// scheduleRetryDelay is how long an item waits before its first retry;
+	// the wait doubles with each further attempt.
+	scheduleRetryDelay = time.Minute
+)
+
+// ScheduleUseCase publishes topics and replies at a time chosen by their
+// author. Items live in the database until published, so nothing is lost
+// over a restart; anything that fell due while the service was down is
+// published on the next run.
+type ScheduleUseCase struct {
+	authClient   *rest.AuthClient
+	scheduleRepo repository.ScheduleRepository
+	categoryRepo repository.CategoryRepository
+	posts        *PostUseCase
+	topics       *TopicUseCase
+}
+
+func NewScheduleUseCase(authClient *rest.AuthClient, scheduleRepo repository.ScheduleRepository, categoryRepo repository.CategoryRepository, posts *PostUseCase, topics *TopicUseCase) *ScheduleUseCase {
+	return &ScheduleUseCase{
+		authClient:   authClient,
+		scheduleRepo: scheduleRepo,
+		categoryRepo: categoryRepo,
+		posts:        posts,
+		topics:       topics,
+	}
+}
+
+// Schedule queues a new topic (no TopicID) or a reply for publishing. The
+// content is screened now rather than at publishing time, so the author
+// learns right away if it is held; a held item is published once a
+// moderator approves it instead of at the scheduled time.
+func (uc *ScheduleUseCase) Schedule(ctx context.Context, token string, s *entity.ScheduledPost) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	if err := validatePublishAt(s.PublishAt); err != nil {
+		return err
+	}
+
+	body, err := sanitizeBody(s.Body)
+	if err != nil {
+		return err
+	}
+	review := &entity.PostReview{AuthorID: userID, Body: body}
+
+	if s.TopicID == 0 {
+		s.Title = strings.TrimSpace(s.Title)
+		if s.Title == "" {
+			return ErrInvalidInput
+		}
+		if _, err := uc.categoryRepo.GetByID(ctx, s.CategoryID); err != nil {
+			return err
+		}
+		if s.Tags, err = uc.topics.tags.canonicalTags(ctx, s.Tags); err != nil {
+			return err
+		}
+		s.ReplyToID = 0
+		review.CategoryID, review.Title = s.CategoryID, s.Title
+		if err := uc.posts.checkCapabilities(ctx, userID, s.Title+"\n"+body); err != nil {
+			return err
+		}
+	} else {
+		if _, err := uc.posts.preparePost(ctx, userID, &entity.Post{TopicID: s.TopicID, ReplyToID: s.ReplyToID, Body: s.Body}); err != nil {
+			return err
+		}
+		s.CategoryID, s.Title, s.Tags = 0, "", nil
+		review.TopicID, review.ReplyToID = s.TopicID, s.ReplyToID
+		if err := uc.posts.checkCapabilities(ctx, userID, body); err != nil {
+			return err
+		}
+	}
+
+	if err := uc.posts.screen(ctx, review); err != nil {
+		return err
+	}
+
+	s.AuthorID = userID
+	s.Body = html.UnescapeString(body)
+	return uc.scheduleRepo.Create(ctx, s)
+}
+
+func validatePublishAt(at time.Time) error {
+	now := time.Now()
+	if !at.After(now) || at.After(now.Add(maxScheduleAhead)) {
+		return fmt.Errorf("%w: publish time must be within the next %d days", ErrInvalidInput, int(maxScheduleAhead.Hours()/24))
+	}
+	return nil
+}
+
+// ListScheduled returns the user's items that are not published yet,
+// including failed ones with the reason.
+func (uc *ScheduleUseCase) ListScheduled(ctx context.Context, token string) ([]entity.ScheduledPost, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return uc.scheduleRepo.ListByAuthor(ctx, userID)
+}
+
+// Reschedule moves an item to a new time; a failed item is retried then.
+func (uc *ScheduleUseCase) Reschedule(ctx context.Context, token string, id int64, at time.Time) (*entity.ScheduledPost, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	if err := validatePublishAt(at); err != nil {
+		return nil, err
+	}
+	if err := uc.scheduleRepo.Reschedule(ctx, id, userID, at); err != nil {
+		return nil, err
+	}
+	return uc.scheduleRepo.GetByID(ctx, id)
+}
+
+func (uc *ScheduleUseCase) Cancel(ctx context.Context, token string, id int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+	return uc.scheduleRepo.Delete(ctx, id, userID)
+}
+
+// PublishDue publishes everything that is due and returns how many items
+// were published.
+func (uc *ScheduleUseCase) PublishDue(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		now := time.Now()
+		items, err := uc.scheduleRepo.ClaimDue(ctx, now, now.Add(-scheduleClaimTimeout), scheduleBatchSize)
+		if err != nil {
+			return published, err
+		}
+
+		for i := range items {
+			if uc.publish(ctx, &items[i]) {
+				published++
+			}
+		}
+		if len(items) < scheduleBatchSize {
+			return published, nil
+		}
+	}
+}
+
+func (uc *ScheduleUseCase) publish(ctx context.Context, s *entity.ScheduledPost) bool {
+	err := uc.publishItem(ctx, s)
+	if err == nil {
+		return true
+	}
+
+	// Content that became unpublishable, say because its topic was deleted,
+	// fails for good; anything else is retried later.
+	var retryAt time.Time
+	if s.Attempts < maxScheduleAttempts &&
+		!errors.Is(err, repository.ErrNotFound) &&
+		!errors.Is(err, ErrInvalidInput) &&
+		!errors.Is(err, ErrForbidden) {
+		retryAt = time.Now().Add(scheduleRetryDelay << (s.Attempts - 1))
+	}
+	log.Printf("Error publishing scheduled post %d (attempt %d): %v", s.ID, s.Attempts, err)
+	if err := uc.scheduleRepo.MarkFailed(ctx, s.ID, err.Error(), retryAt); err != nil {
+		log.Printf("Error marking scheduled post %d as failed: %v", s.ID, err)
+	}
+	return false
+}
+
+// publishItem stores the item's post, which marks the item published in the
+// same transaction. A new topic is recorded on the item as soon as it is
+// opened, so a retry adds the first post to it instead of opening another.
+func (uc *ScheduleUseCase) publishItem(ctx context.Context, s *entity.ScheduledPost) error {
+	if s.TopicID == 0 {
+		// Tags may have been merged since the item was scheduled.
+		tags, err := uc.topics.tags.canonicalTags(ctx, s.Tags)
+		if err != nil {
+			return err
+		}
+		topic := &entity.Topic{CategoryID: s.CategoryID, Title: s.Title}
+		if err := uc.topics.openTopic(ctx, s.AuthorID, topic, tags); err != nil {
+			return err
+		}
+		if err := uc.scheduleRepo.SetTopic(ctx, s.ID, topic.ID); err != nil {
+			return err
+		}
+		s.TopicID = topic.ID
+	}
+
+	post := &entity.Post{TopicID: s.TopicID, ReplyToID: s.ReplyToID, Body: s.Body, ScheduledID: s.ID}
+	return uc.posts.createPost(ctx, s.AuthorID, post)
+}
+
+// Функция для периодической публикации запланированных тем и ответов
+func PublishScheduledPeriodically(ctx context.Context, scheduler *ScheduleUseCase, interval time.Duration) {
+	run := func() {
+		if _, err := scheduler.PublishDue(ctx); err != nil {
+			log.Printf("Error publishing scheduled posts: %v", err)
+		}
+	}
+
+	// Catch up on whatever fell due while the service was down.
+	run()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
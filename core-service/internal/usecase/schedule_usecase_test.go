@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"core-service/internal/entity"
+	"core-service/internal/repository"
+)
+
+// flakyPostRepository fails the next fail post creations.
+type flakyPostRepository struct {
+	repository.PostRepository
+	fail int
+}
+
+func (r *flakyPostRepository) Create(ctx context.Context, post *entity.Post) error {
+	if r.fail > 0 {
+		r.fail--
+		return errors.New("database is locked")
+	}
+	return r.PostRepository.Create(ctx, post)
+}
+
+func TestPublishScheduledTopicRetry(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyPostRepository{fail: 2}
+	a := newTestApp(t, func(a *testApp) {
+		flaky.PostRepository = a.postRepo
+		a.postRepo = flaky
+	})
+
+	item := &entity.ScheduledPost{AuthorID: 1, CategoryID: a.category.ID, Title: "Scheduled topic",
+		Body: "Published a little later.", PublishAt: time.Now().Add(-time.Second)}
+	if err := a.scheduleRepo.Create(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	makeDue := func() {
+		t.Helper()
+		if _, err := a.db.Exec("UPDATE scheduled_posts SET publish_at = ? WHERE id = ?",
+			time.Now().Add(-time.Second).UTC(), item.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each failed attempt waits twice as long as the one before
+	for attempt, delay := range []time.Duration{scheduleRetryDelay, 2 * scheduleRetryDelay} {
+		makeDue()
+		start := time.Now()
+		if n, err := a.schedule.PublishDue(ctx); err != nil || n != 0 {
+			t.Fatalf("attempt %d: PublishDue = %d, %v; want it to fail", attempt+1, n, err)
+		}
+		got, err := a.scheduleRepo.GetByID(ctx, item.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != entity.SchedulePending || got.TopicID == 0 {
+			t.Fatalf("attempt %d: item = %+v, want pending with its topic", attempt+1, got)
+		}
+		if wait := got.PublishAt.Sub(start); wait < delay-time.Second || wait > delay+time.Second {
+			t.Errorf("attempt %d: retried after %v, want %v", attempt+1, wait, delay)
+		}
+		if n, err := a.schedule.PublishDue(ctx); err != nil || n != 0 {
+			t.Errorf("attempt %d: item retried before it was due", attempt+1)
+		}
+	}
+
+	makeDue()
+	if n, err := a.schedule.PublishDue(ctx); err != nil || n != 1 {
+		t.Fatalf("PublishDue = %d, %v; want 1", n, err)
+	}
+	got, err := a.scheduleRepo.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != entity.SchedulePublished || got.PostID == 0 {
+		t.Errorf("item = %+v, want published", got)
+	}
+	// The retries reused the topic opened by the first attempt
+	if n := a.count(t, "SELECT COUNT(*) FROM topics"); n != 1 {
+		t.Errorf("%d topics opened, want 1", n)
+	}
+	if n := a.count(t, "SELECT COUNT(*) FROM posts WHERE topic_id = ?", got.TopicID); n != 1 {
+		t.Errorf("%d posts in the topic, want 1", n)
+	}
+}
+
+func TestPublishScheduledGivesUp(t *testing.T) {
+	ctx := context.Background()
+	a := newTestApp(t)
+
+	// Replies to a deleted topic fail for good on the first attempt
+	item := &entity.ScheduledPost{AuthorID: 1, TopicID: 999, Body: "Too late.", PublishAt: time.Now().Add(-time.Second)}
+	if err := a.scheduleRepo.Create(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := a.schedule.PublishDue(ctx); err != nil || n != 0 {
+		t.Fatalf("PublishDue = %d, %v", n, err)
+	}
+	got, err := a.scheduleRepo.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != entity.ScheduleFailed || got.Attempts != 1 {
+		t.Errorf("item = %+v, want failed after one attempt", got)
+	}
+}
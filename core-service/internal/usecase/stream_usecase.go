@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+)
+
+type StreamUseCase struct {
+	authClient   *rest.AuthClient
+	hub          *realtime.Hub
+	categoryRepo repository.CategoryRepository
+	topicRepo    repository.TopicRepository
+}
+
+func NewStreamUseCase(authClient *rest.AuthClient, hub *realtime.Hub, categoryRepo repository.CategoryRepository, topicRepo repository.TopicRepository) *StreamUseCase {
+	return &StreamUseCase{
+		authClient:   authClient,
+		hub:          hub,
+		categoryRepo: categoryRepo,
+		topicRepo:    topicRepo,
+	}
+}
+
+func (uc *StreamUseCase) SubscribeTopic(ctx context.Context, token string, topicID int64) (*realtime.Subscription, error) {
+	if valid, err := uc.authClient.ValidateToken(token); err != nil || !valid {
+		return nil, ErrUnauthorized
+	}
+	if _, err := uc.topicRepo.GetByID(ctx, topicID); err != nil {
+		return nil, err
+	}
+	return uc.hub.SubscribeTopic(topicID), nil
+}
+
+func (uc *StreamUseCase) SubscribeCategory(ctx context.Context, token string, categoryID int64) (*realtime.Subscription, error) {
+	if valid, err := uc.authClient.ValidateToken(token); err != nil || !valid {
+		return nil, ErrUnauthorized
+	}
+	if _, err := uc.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return nil, err
+	}
+	return uc.hub.SubscribeCategory(categoryID), nil
+}
+
+// SubscribeUser opens the personal stream that in-app notifications go to.
+func (uc *StreamUseCase) SubscribeUser(ctx context.Context, token string) (*realtime.Subscription, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return uc.hub.SubscribeUser(userID), nil
+}
+
+// Typing announces that the user is composing a reply in the topic.
+func (uc *StreamUseCase) Typing(ctx context.Context, token string, topicID int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	topic, err := uc.topicRepo.GetByID(ctx, topicID)
+	if err != nil {
+		return err
+	}
+
+	uc.hub.Publish(realtime.Event{
+		Type:       realtime.EventTyping,
+		TopicID:    topic.ID,
+		CategoryID: topic.CategoryID,
+		UserID:     userID,
+	})
+	return nil
+}
@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/repository"
+)
+
+const (
+	maxTagsPerTopic  = 5
+	minTagLength     = 2
+	maxTagLength     = 30
+	defaultTagsLimit = 50
+	maxTagsLimit     = 200
+)
+
+// Tag names are lowercase words of letters and digits joined by single
+// hyphens or dots, e.g. "go", "sqlite", "web-dev", "node.js".
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9]+([-.][a-z0-9]+)*$`)
+
+type TagUseCase struct {
+	authClient *rest.AuthClient
+	tagRepo    repository.TagRepository
+	topicRepo  repository.TopicRepository
+	reads      *ReadUseCase
+	roles      *Roles
+}
+
+func NewTagUseCase(authClient *rest.AuthClient, tagRepo repository.TagRepository, topicRepo repository.TopicRepository, reads *ReadUseCase, roles *Roles) *TagUseCase {
+	return &TagUseCase{
+		authClient: authClient,
+		tagRepo:    tagRepo,
+		topicRepo:  topicRepo,
+		reads:      reads,
+		roles:      roles,
+	}
+}
+
+// SetTopicTags replaces the tags of a topic. Only its author and moderators
+// may retag it.
+func (uc *TagUseCase) SetTopicTags(ctx context.Context, token string, topicID int64, names []string) ([]string, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	topic, err := liveTopic(ctx, uc.topicRepo, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return nil, ErrForbidden
+	}
+
+	return uc.setTags(ctx, topicID, names)
+}
+
+func (uc *TagUseCase) setTags(ctx context.Context, topicID int64, names []string) ([]string, error) {
+	names, err := uc.canonicalTags(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.tagRepo.SetTopicTags(ctx, topicID, names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// canonicalTags normalizes and validates tag names, resolves synonyms and
+// drops duplicates, then enforces the per-topic limit.
+func (uc *TagUseCase) canonicalTags(ctx context.Context, names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, name)
+	}
+
+	canonical, err := uc.tagRepo.Canonicalize(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(canonical))
+	result := make([]string, 0, len(canonical))
+	for _, name := range canonical {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	if len(result) > maxTagsPerTopic {
+		return nil, fmt.Errorf("%w: at most %d tags per topic", ErrInvalidInput, maxTagsPerTopic)
+	}
+	return result, nil
+}
+
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '\t'
+	}), "-")
+
+	if len(name) < minTagLength || len(name) > maxTagLength {
+		return "", fmt.Errorf("%w: tag names must be %d to %d characters", ErrInvalidInput, minTagLength, maxTagLength)
+	}
+	if !tagNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: invalid tag name %q", ErrInvalidInput, name)
+	}
+	return name, nil
+}
+
+// withTags fills in the tags of the given topics.
+func (uc *TagUseCase) withTags(ctx context.Context, topics []*entity.Topic) error {
+	ids := make([]int64, len(topics))
+	for i, t := range topics {
+		ids[i] = t.ID
+	}
+
+	tags, err := uc.tagRepo.ListByTopics(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, t := range topics {
+		t.Tags = tags[t.ID]
+		if t.Tags == nil {
+			t.Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// GetTag looks a tag up by name or by one of its synonyms.
+func (uc *TagUseCase) GetTag(ctx context.Context, name string) (*entity.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, repository.ErrNotFound
+	}
+	canonical, err := uc.tagRepo.Canonicalize(ctx, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	return uc.tagRepo.GetByName(ctx, canonical[0])
+}
+
+func (uc *TagUseCase) ListTagTopics(ctx context.Context, token, name string, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	userID, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	tag, err := uc.GetTag(ctx, name)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	page, err := uc.topicRepo.ListByTag(ctx, tag.ID, req)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	if err := uc.withTags(ctx, pointers(page.Items)); err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	return page, uc.reads.withReadState(ctx, userID, pointers(page.Items))
+}
+
+// Autocomplete suggests tags starting with the given prefix, matching
+// synonyms as well so users find the canonical name.
+func (uc *TagUseCase) Autocomplete(ctx context.Context, prefix string, limit int) ([]entity.Tag, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return []entity.Tag{}, nil
+	}
+	return uc.tagRepo.Search(ctx, prefix, clampTagsLimit(limit))
+}
+
+func (uc *TagUseCase) Cloud(ctx context.Context, limit int) ([]entity.Tag, error) {
+	return uc.tagRepo.Cloud(ctx, clampTagsLimit(limit))
+}
+
+func clampTagsLimit(limit int) int {
+	if limit <= 0 {
+		return defaultTagsLimit
+	}
+	return min(limit, maxTagsLimit)
+}
+
+// AddSynonym makes synonym an alias of the named tag. Names that are already
+// tags in their own right have to be merged instead.
+func (uc *TagUseCase) AddSynonym(ctx context.Context, token, tagName, synonym string) (*entity.Tag, error) {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return nil, err
+	}
+
+	tag, err := uc.GetTag(ctx, tagName)
+	if err != nil {
+		return nil, err
+	}
+	synonym, err = normalizeTagName(synonym)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := uc.tagRepo.Canonicalize(ctx, []string{synonym})
+	if err != nil {
+		return nil, err
+	}
+	if canonical[0] != synonym {
+		return nil, fmt.Errorf("%w: %q is already a synonym of %q", ErrInvalidInput, synonym, canonical[0])
+	}
+	if _, err := uc.tagRepo.GetByName(ctx, synonym); err == nil {
+		return nil, fmt.Errorf("%w: %q is a tag, merge it instead", ErrInvalidInput, synonym)
+	}
+
+	if err := uc.tagRepo.AddSynonym(ctx, tag.ID, synonym); err != nil {
+		return nil, err
+	}
+	return uc.tagRepo.GetByName(ctx, tag.Name)
+}
+
+func (uc *TagUseCase) RemoveSynonym(ctx context.Context, token, synonym string) error {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return err
+	}
+
+	synonym, err := normalizeTagName(synonym)
+	if err != nil {
+		return repository.ErrNotFound
+	}
+	return uc.tagRepo.RemoveSynonym(ctx, synonym)
+}
+
+// MergeTags folds one tag into another: its topics are retagged and its name
+// becomes a synonym of the target.
+func (uc *TagUseCase) MergeTags(ctx context.Context, token, from, into string) (*entity.Tag, error) {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return nil, err
+	}
+
+	fromTag, err := uc.GetTag(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	intoTag, err := uc.GetTag(ctx, into)
+	if err != nil {
+		return nil, err
+	}
+	if fromTag.ID == intoTag.ID {
+		return nil, fmt.Errorf("%w: cannot merge a tag into itself", ErrInvalidInput)
+	}
+
+	if err := uc.tagRepo.Merge(ctx, fromTag.ID, intoTag.ID); err != nil {
+		return nil, err
+	}
+	return uc.tagRepo.GetByName(ctx, intoTag.Name)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	thumb, contentType, ok := makeThumbnail(encodePNG(t, 640, 400))
+	if !ok || contentType != "image/png" {
+		t.Fatalf("makeThumbnail = %q, %v", contentType, ok)
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(thumb))
+	if err != nil || cfg.Width != 320 || cfg.Height != 200 {
+		t.Errorf("thumbnail is %dx%d, %v; want 320x200", cfg.Width, cfg.Height, err)
+	}
+
+	var src bytes.Buffer
+	if err := jpeg.Encode(&src, image.NewGray(image.Rect(0, 0, 100, 500)), nil); err != nil {
+		t.Fatal(err)
+	}
+	thumb, contentType, ok = makeThumbnail(src.Bytes())
+	if !ok || contentType != "image/jpeg" {
+		t.Fatalf("makeThumbnail of a JPEG = %q, %v", contentType, ok)
+	}
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb)); err != nil || cfg.Width != 64 || cfg.Height != 320 {
+		t.Errorf("JPEG thumbnail is %dx%d, %v; want 64x320", cfg.Width, cfg.Height, err)
+	}
+
+	// Small images keep their size
+	thumb, _, ok = makeThumbnail(encodePNG(t, 10, 20))
+	if cfg, err := png.DecodeConfig(bytes.NewReader(thumb)); !ok || err != nil || cfg.Width != 10 || cfg.Height != 20 {
+		t.Errorf("thumbnail of a small image is %dx%d, %v", cfg.Width, cfg.Height, err)
+	}
+
+	if _, _, ok := makeThumbnail([]byte("GIF89a not really")); ok {
+		t.Error("makeThumbnail accepted garbage")
+	}
+}
+
+func TestMakeThumbnailDecompressionBomb(t *testing.T) {
+	// A tiny PNG whose header claims 30000x30000 pixels
+	data := encodePNG(t, 1, 1)
+	ihdr := data[8:]
+	binary.BigEndian.PutUint32(ihdr[8:], 30000)
+	binary.BigEndian.PutUint32(ihdr[12:], 30000)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+	if cfg, err := png.DecodeConfig(bytes.NewReader(data)); err != nil || cfg.Width != 30000 {
+		t.Fatalf("crafted PNG header: %+v, %v", cfg, err)
+	}
+
+	if _, _, ok := makeThumbnail(data); ok {
+		t.Error("makeThumbnail decoded an image of 900 megapixels")
+	}
+}
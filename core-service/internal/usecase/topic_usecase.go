@@ -0,0 +1,383 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/pagination"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+)
+
+type TopicUseCase struct {
+	authClient   *rest.AuthClient
+	categoryRepo repository.CategoryRepository
+	topicRepo    repository.TopicRepository
+	posts        *PostUseCase
+	tags         *TagUseCase
+	reputation   *ReputationUseCase
+	reads        *ReadUseCase
+	roles        *Roles
+}
+
+func NewTopicUseCase(authClient *rest.AuthClient, categoryRepo repository.CategoryRepository, topicRepo repository.TopicRepository, posts *PostUseCase, tags *TagUseCase, reputation *ReputationUseCase, reads *ReadUseCase, roles *Roles) *TopicUseCase {
+	return &TopicUseCase{
+		authClient:   authClient,
+		categoryRepo: categoryRepo,
+		topicRepo:    topicRepo,
+		posts:        posts,
+		tags:         tags,
+		reputation:   reputation,
+		reads:        reads,
+		roles:        roles,
+	}
+}
+
+// CreateTopic opens a topic together with its first post.
+func (uc *TopicUseCase) CreateTopic(token string, topic *entity.Topic, body string, tags []string) (*entity.Post, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	topic.Title = strings.TrimSpace(topic.Title)
+	if topic.Title == "" || strings.TrimSpace(body) == "" {
+		return nil, ErrInvalidInput
+	}
+
+	ctx := context.Background()
+	if _, err := uc.categoryRepo.GetByID(ctx, topic.CategoryID); err != nil {
+		return nil, err
+	}
+	tags, err = uc.tags.canonicalTags(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	sanitized, err := sanitizeBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.posts.checkCapabilities(ctx, userID, topic.Title+"\n"+sanitized); err != nil {
+		return nil, err
+	}
+	review := &entity.PostReview{
+		AuthorID:   userID,
+		CategoryID: topic.CategoryID,
+		Title:      topic.Title,
+		Body:       sanitized,
+	}
+	if err := uc.posts.screen(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return uc.createTopic(ctx, userID, topic, body, tags)
+}
+
+// createTopic opens a topic without running the content checks.
+func (uc *TopicUseCase) createTopic(ctx context.Context, userID int, topic *entity.Topic, body string, tags []string) (*entity.Post, error) {
+	if err := uc.openTopic(ctx, userID, topic, tags); err != nil {
+		return nil, err
+	}
+
+	post := &entity.Post{TopicID: topic.ID, Body: body}
+	if err := uc.posts.createPost(ctx, userID, post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// openTopic stores a topic and its tags, without the first post.
+func (uc *TopicUseCase) openTopic(ctx context.Context, userID int, topic *entity.Topic, tags []string) error {
+	topic.AuthorID = userID
+	if err := uc.topicRepo.Create(ctx, topic); err != nil {
+		return err
+	}
+	if err := uc.tags.tagRepo.SetTopicTags(ctx, topic.ID, tags); err != nil {
+		return err
+	}
+	topic.Tags = tags
+	return nil
+}
+
+// GetTopic returns a topic with its tags and, in Q&A categories, its
+// accepted answer so clients can pin it under the question. With a token it
+// includes where the user left off.
+func (uc *TopicUseCase) GetTopic(ctx context.Context, token string, id int64) (*entity.Topic, error) {
+	userID, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := liveTopic(ctx, uc.topicRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.tags.withTags(ctx, []*entity.Topic{topic}); err != nil {
+		return nil, err
+	}
+	if err := uc.reads.withReadState(ctx, userID, []*entity.Topic{topic}); err != nil {
+		return nil, err
+	}
+
+	if topic.AcceptedPostID != 0 {
+		answer, err := uc.posts.livePost(ctx, topic.AcceptedPostID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			answer.Accepted = true
+			if err := uc.posts.withAttachments(ctx, []*entity.Post{answer}); err != nil {
+				return nil, err
+			}
+			topic.AcceptedAnswer = answer
+		}
+	}
+	return topic, nil
+}
+
+// AcceptAnswer marks a reply as the answer to a question in a Q&A category.
+// Only the question's author and moderators may do so, and the answer's
+// author earns reputation for it unless they answered their own question.
+// Accepting another reply moves the reputation over.
+func (uc *TopicUseCase) AcceptAnswer(ctx context.Context, token string, topicID, postID int64) (*entity.Topic, error) {
+	userID, topic, err := uc.questionForAuthor(ctx, token, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := uc.posts.livePost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.TopicID != topicID {
+		return nil, fmt.Errorf("%w: post is not a reply in this topic", ErrInvalidInput)
+	}
+	// The topic's first post is the question itself.
+	ids, err := uc.posts.postRepo.ListIDsByTopic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 && ids[0] == answer.ID {
+		return nil, fmt.Errorf("%w: the question cannot be its own answer", ErrInvalidInput)
+	}
+
+	previous := topic.AcceptedPostID
+	if err := uc.topicRepo.SetAcceptedAnswer(ctx, topicID, postID); err != nil {
+		return nil, err
+	}
+	topic.AcceptedPostID = postID
+	answer.Accepted = true
+	topic.AcceptedAnswer = answer
+
+	uc.updateAnswerReputation(ctx, topic, previous, answer)
+	uc.publishAnswer(userID, topic)
+	return topic, nil
+}
+
+func (uc *TopicUseCase) ClearAcceptedAnswer(ctx context.Context, token string, topicID int64) (*entity.Topic, error) {
+	userID, topic, err := uc.questionForAuthor(ctx, token, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic.AcceptedPostID == 0 {
+		return topic, nil
+	}
+
+	previous := topic.AcceptedPostID
+	if err := uc.topicRepo.SetAcceptedAnswer(ctx, topicID, 0); err != nil {
+		return nil, err
+	}
+	topic.AcceptedPostID = 0
+
+	uc.updateAnswerReputation(ctx, topic, previous, nil)
+	uc.publishAnswer(userID, topic)
+	return topic, nil
+}
+
+// questionForAuthor loads a topic of a Q&A category the user may pick the
+// answer for.
+func (uc *TopicUseCase) questionForAuthor(ctx context.Context, token string, topicID int64) (int, *entity.Topic, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return 0, nil, ErrUnauthorized
+	}
+
+	topic, err := liveTopic(ctx, uc.topicRepo, topicID)
+	if err != nil {
+		return 0, nil, err
+	}
+	category, err := uc.categoryRepo.GetByID(ctx, topic.CategoryID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !category.QAMode {
+		return 0, nil, fmt.Errorf("%w: category is not in Q&A mode", ErrInvalidInput)
+	}
+	if topic.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return 0, nil, ErrForbidden
+	}
+	return userID, topic, nil
+}
+
+// updateAnswerReputation keeps one accepted-answer event per topic, owned by
+// the current answer's author. Failures are logged; the answer itself stands.
+func (uc *TopicUseCase) updateAnswerReputation(ctx context.Context, topic *entity.Topic, previousPostID int64, answer *entity.Post) {
+	var err error
+	if answer != nil && answer.AuthorID != topic.AuthorID {
+		err = uc.reputation.award(ctx, &entity.ReputationEvent{
+			UserID:   answer.AuthorID,
+			Kind:     entity.ReputationAcceptedAnswer,
+			SourceID: topic.ID,
+			Points:   pointsAcceptedAnswer,
+		})
+	} else {
+		err = uc.reputation.reputationRepo.RemoveEvent(ctx, entity.ReputationAcceptedAnswer, topic.ID, 0)
+	}
+	if err != nil {
+		log.Printf("Error updating reputation for answer to topic %d: %v", topic.ID, err)
+	}
+
+	// The previous answer's author may just have lost the points.
+	if previousPostID != 0 && (answer == nil || previousPostID != answer.ID) {
+		if previous, err := uc.posts.postRepo.GetByID(ctx, previousPostID); err == nil {
+			if _, err := uc.reputation.refresh(ctx, previous.AuthorID); err != nil {
+				log.Printf("Error refreshing reputation of user %d: %v", previous.AuthorID, err)
+			}
+		}
+	}
+}
+
+func (uc *TopicUseCase) publishAnswer(userID int, topic *entity.Topic) {
+	uc.posts.hub.Publish(realtime.Event{
+		Type:       realtime.EventAnswer,
+		TopicID:    topic.ID,
+		CategoryID: topic.CategoryID,
+		UserID:     userID,
+		Payload:    topic,
+	})
+}
+
+// DeleteTopic soft-deletes a topic, hiding it and all its posts.
+func (uc *TopicUseCase) DeleteTopic(ctx context.Context, token string, id int64) error {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return ErrUnauthorized
+	}
+
+	topic, err := liveTopic(ctx, uc.topicRepo, id)
+	if err != nil {
+		return err
+	}
+	if topic.AuthorID != userID && !uc.roles.IsModerator(userID) {
+		return ErrForbidden
+	}
+	return uc.topicRepo.SoftDelete(ctx, id, userID)
+}
+
+func (uc *TopicUseCase) RestoreTopic(ctx context.Context, token string, id int64) (*entity.Topic, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	topic, err := uc.topicRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canRestore(uc.roles, userID, topic.AuthorID, topic.DeletedBy) {
+		return nil, ErrForbidden
+	}
+
+	if err := uc.topicRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	topic.DeletedAt, topic.DeletedBy = nil, 0
+	return topic, nil
+}
+
+func (uc *TopicUseCase) ListCategories(ctx context.Context) ([]entity.Category, error) {
+	return uc.categoryRepo.List(ctx)
+}
+
+func (uc *TopicUseCase) CreateCategory(token string, category *entity.Category) error {
+	if valid, err := uc.authClient.ValidateToken(token); err != nil || !valid {
+		return ErrUnauthorized
+	}
+
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return ErrInvalidInput
+	}
+	return uc.categoryRepo.Create(context.Background(), category)
+}
+
+// ListCategoryTopics lists a category's topics. The solved, unsolved and
+// unanswered filters only make sense in Q&A categories.
+func (uc *TopicUseCase) ListCategoryTopics(ctx context.Context, token string, categoryID int64, filter repository.TopicFilter, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	userID, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	category, err := uc.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	switch filter {
+	case repository.TopicsAll:
+	case repository.TopicsSolved, repository.TopicsUnsolved, repository.TopicsUnanswered:
+		if !category.QAMode {
+			return pagination.Page[entity.Topic]{}, fmt.Errorf("%w: category is not in Q&A mode", ErrInvalidInput)
+		}
+	default:
+		return pagination.Page[entity.Topic]{}, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, filter)
+	}
+
+	page, err := uc.topicRepo.ListByCategory(ctx, categoryID, filter, req)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	return page, uc.decorate(ctx, userID, page.Items)
+}
+
+// ListUnanswered lists questions without an accepted answer or any reply
+// across all Q&A categories.
+func (uc *TopicUseCase) ListUnanswered(ctx context.Context, token string, req pagination.Request) (pagination.Page[entity.Topic], error) {
+	userID, err := viewerID(uc.authClient, token)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+
+	page, err := uc.topicRepo.ListUnanswered(ctx, req)
+	if err != nil {
+		return pagination.Page[entity.Topic]{}, err
+	}
+	return page, uc.decorate(ctx, userID, page.Items)
+}
+
+// decorate adds tags and the viewer's read state to listed topics.
+func (uc *TopicUseCase) decorate(ctx context.Context, userID int, topics []entity.Topic) error {
+	if err := uc.tags.withTags(ctx, pointers(topics)); err != nil {
+		return err
+	}
+	return uc.reads.withReadState(ctx, userID, pointers(topics))
+}
+
+// SetQAMode switches a category in or out of Q&A mode. Accepted answers are
+// kept when it is switched off, so switching back restores them.
+func (uc *TopicUseCase) SetQAMode(ctx context.Context, token string, categoryID int64, enabled bool) (*entity.Category, error) {
+	if _, err := uc.roles.requireModerator(uc.authClient, token); err != nil {
+		return nil, err
+	}
+	if err := uc.categoryRepo.SetQAMode(ctx, categoryID, enabled); err != nil {
+		return nil, err
+	}
+	return uc.categoryRepo.GetByID(ctx, categoryID)
+}
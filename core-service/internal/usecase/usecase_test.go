@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/notification"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+	"core-service/internal/spam"
+)
+
+// testApp wires the use cases like app.go does, on a fresh SQLite database
+// and a fake auth-service. Tokens are "user-<id>"; no usernames exist.
+type testApp struct {
+	db *sql.DB
+
+	categoryRepo repository.CategoryRepository
+	topicRepo    repository.TopicRepository
+	postRepo     repository.PostRepository
+	spamRepo     repository.SpamRepository
+	pollRepo     repository.PollRepository
+	scheduleRepo repository.ScheduleRepository
+
+	// checks runs before the use cases are built; options may replace it.
+	checks *spam.Pipeline
+
+	posts    *PostUseCase
+	topics   *TopicUseCase
+	polls    *PollUseCase
+	schedule *ScheduleUseCase
+
+	category *entity.Category
+}
+
+// newTestApp builds a testApp. Options run before the use cases are built,
+// so they can wrap repositories or replace the content checks.
+func newTestApp(t *testing.T, options ...func(*testApp)) *testApp {
+	t.Helper()
+	db, err := repository.OpenSQLiteDB(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/validate" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct{ Token string }
+		json.NewDecoder(r.Body).Decode(&req)
+		id, err := strconv.Atoi(strings.TrimPrefix(req.Token, "user-"))
+		valid := err == nil && strings.HasPrefix(req.Token, "user-")
+		json.NewEncoder(w).Encode(map[string]any{"valid": valid, "user_id": id})
+	}))
+	t.Cleanup(auth.Close)
+
+	a := &testApp{
+		db:           db,
+		categoryRepo: repository.NewSQLiteCategoryRepository(db),
+		topicRepo:    repository.NewSQLiteTopicRepository(db),
+		postRepo:     repository.NewSQLitePostRepository(db),
+		spamRepo:     repository.NewSQLiteSpamRepository(db),
+		pollRepo:     repository.NewSQLitePollRepository(db),
+		scheduleRepo: repository.NewSQLiteScheduleRepository(db),
+	}
+	a.checks = spam.NewPipeline(spam.NewDuplicateCheck(a.spamRepo, time.Hour))
+	for _, option := range options {
+		option(a)
+	}
+
+	authClient := rest.NewAuthClient(auth.URL, "service-token")
+	hub := realtime.NewHub(16)
+	roles := NewRoles(nil)
+	attachmentRepo := repository.NewSQLiteAttachmentRepository(db)
+	notifications := NewNotificationUseCase(authClient, repository.NewSQLiteNotificationRepository(db),
+		repository.NewSQLiteSubscriptionRepository(db), a.postRepo, a.topicRepo, a.categoryRepo,
+		notification.NewInAppChannel(hub))
+	reputation := NewReputationUseCase(authClient, repository.NewSQLiteReputationRepository(db), roles)
+	a.posts = NewPostUseCase(authClient, a.postRepo, a.topicRepo, attachmentRepo,
+		repository.NewSQLiteReviewRepository(db), hub, notifications, a.checks, reputation, roles, time.Hour)
+	reads := NewReadUseCase(authClient, repository.NewSQLiteReadRepository(db), a.topicRepo, a.categoryRepo,
+		a.postRepo, repository.NewSQLiteProfileRepository(db))
+	tags := NewTagUseCase(authClient, repository.NewSQLiteTagRepository(db), a.topicRepo, reads, roles)
+	a.topics = NewTopicUseCase(authClient, a.categoryRepo, a.topicRepo, a.posts, tags, reputation, reads, roles)
+	a.polls = NewPollUseCase(authClient, a.pollRepo, a.topicRepo, hub, roles)
+	a.schedule = NewScheduleUseCase(authClient, a.scheduleRepo, a.categoryRepo, a.posts, a.topics)
+
+	a.category = &entity.Category{Name: "general"}
+	if err := a.categoryRepo.Create(context.Background(), a.category); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func userToken(userID int) string {
+	return "user-" + strconv.Itoa(userID)
+}
+
+// count returns the result of a single-value query.
+func (a *testApp) count(t *testing.T, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := a.db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
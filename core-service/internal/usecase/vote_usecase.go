@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"core-service/internal/controllers/rest"
+	"core-service/internal/entity"
+	"core-service/internal/realtime"
+	"core-service/internal/repository"
+)
+
+type VoteUseCase struct {
+	authClient *rest.AuthClient
+	voteRepo   repository.VoteRepository
+	posts      *PostUseCase
+	reputation *ReputationUseCase
+}
+
+func NewVoteUseCase(authClient *rest.AuthClient, voteRepo repository.VoteRepository, posts *PostUseCase, reputation *ReputationUseCase) *VoteUseCase {
+	return &VoteUseCase{
+		authClient: authClient,
+		voteRepo:   voteRepo,
+		posts:      posts,
+		reputation: reputation,
+	}
+}
+
+// Vote records an up (1) or down (-1) vote on a post, or takes the user's
+// vote back (0). The post author's reputation follows the vote.
+func (uc *VoteUseCase) Vote(ctx context.Context, token string, postID int64, value int) (*entity.Post, error) {
+	userID, err := uc.authClient.ResolveUserID(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	if value < -1 || value > 1 {
+		return nil, ErrInvalidInput
+	}
+
+	post, err := uc.posts.livePost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID == userID {
+		return nil, fmt.Errorf("%w: cannot vote on your own post", ErrForbidden)
+	}
+
+	post.Score, err = uc.voteRepo.Vote(ctx, postID, userID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == 0 {
+		err = uc.reputation.revoke(ctx, post.AuthorID, entity.ReputationVote, postID, userID)
+	} else {
+		points := pointsUpvote
+		if value < 0 {
+			points = pointsDownvote
+		}
+		err = uc.reputation.award(ctx, &entity.ReputationEvent{
+			UserID:   post.AuthorID,
+			Kind:     entity.ReputationVote,
+			SourceID: postID,
+			ActorID:  userID,
+			Points:   points,
+		})
+	}
+	if err != nil {
+		log.Printf("Error updating reputation for vote on post %d: %v", postID, err)
+	}
+	if err := uc.reputation.RecordActivity(ctx, userID); err != nil {
+		log.Printf("Error recording activity of user %d: %v", userID, err)
+	}
+
+	uc.posts.publishPost(ctx, realtime.EventReaction, userID, post)
+	return post, nil
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"log"
+
+	"core-service/internal"
+)
+
+func main() {
+	if err := internal.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,312 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// openDB opens a SQLite database at path. A short busy timeout makes
+// waiting for the migration lock depend on begin's retries.
+func openDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(10)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newMigrator(t *testing.T, db *sql.DB, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+	m, err := New(db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func file(data string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(data)}
+}
+
+// testMigrations are three migrations that each depend on the one before.
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_users.up.sql":    file("CREATE TABLE users (id INTEGER PRIMARY KEY)"),
+		"0001_users.down.sql":  file("DROP TABLE users"),
+		"0002_names.up.sql":    file("ALTER TABLE users ADD COLUMN name TEXT"),
+		"0002_names.down.sql":  file("ALTER TABLE users DROP COLUMN name"),
+		"0010_admin.up.sql":    file("INSERT INTO users (id, name) VALUES (1, 'admin')"),
+		"0010_admin.down.sql":  file("DELETE FROM users WHERE id = 1"),
+		"README.txt":           file("not a migration"),
+		"sub/0003_skip.up.sql": file("not in the root"),
+	}
+}
+
+func versions(migrations []Migration) []int {
+	var v []int
+	for _, m := range migrations {
+		v = append(v, m.Version)
+	}
+	return v
+}
+
+func appliedVersions(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	history, err := readHistory(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v []int
+	for version := range history {
+		v = append(v, version)
+	}
+	slices.Sort(v)
+	return v
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testMigrations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(migrations); !slices.Equal(got, []int{1, 2, 10}) {
+		t.Errorf("versions = %v, want [1 2 10]", got)
+	}
+	if m := migrations[0]; m.Name != "users" || m.Down != "DROP TABLE users" || len(m.Checksum) != 64 {
+		t.Errorf("first migration = %+v", m)
+	}
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name":  {"1-users.up.sql": file("SELECT 1")},
+		"two names": {"0001_a.up.sql": file("SELECT 1"), "0001_b.down.sql": file("SELECT 1")},
+		"no up":     {"0001_users.down.sql": file("SELECT 1")},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("Load with %s succeeded", name)
+		}
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "test.db"))
+	m := newMigrator(t, db, testMigrations())
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := versions(done); !slices.Equal(got, []int{1, 2, 10}) {
+		t.Errorf("Up applied %v, want [1 2 10]", got)
+	}
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Errorf("second Up = %v, %v; want nothing to do", versions(done), err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			t.Errorf("Status of %04d: not applied", s.Version)
+		}
+	}
+
+	// Down reverts the newest first
+	done, err = m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if got := versions(done); !slices.Equal(got, []int{10, 2}) {
+		t.Errorf("Down reverted %v, want [10 2]", got)
+	}
+	if got := appliedVersions(t, db); !slices.Equal(got, []int{1}) {
+		t.Errorf("applied after Down = %v, want [1]", got)
+	}
+	if _, err := db.Exec("INSERT INTO users (id) VALUES (2)"); err != nil {
+		t.Errorf("users table after Down: %v", err)
+	}
+
+	// More steps than applied migrations revert everything
+	if done, err := m.Down(ctx, 5); err != nil || !slices.Equal(versions(done), []int{1}) {
+		t.Errorf("Down(5) = %v, %v; want [1]", versions(done), err)
+	}
+	statuses, err = m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			t.Errorf("Status of %04d: still applied", s.Version)
+		}
+	}
+}
+
+// A failing migration leaves the database as it was, including the
+// migrations before it in the same run.
+func TestUpFailure(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "test.db"))
+	fsys := testMigrations()
+	fsys["0010_admin.up.sql"] = file("INSERT INTO nowhere VALUES (1)")
+
+	m := newMigrator(t, db, fsys)
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Up with a broken migration succeeded")
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			t.Errorf("%04d applied by a failed Up", s.Version)
+		}
+	}
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'users'").Scan(&tables); err != nil || tables != 0 {
+		t.Errorf("users table left behind: %d, %v", tables, err)
+	}
+}
+
+func TestDownIrreversible(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "test.db"))
+	fsys := testMigrations()
+	delete(fsys, "0002_names.down.sql")
+	m := newMigrator(t, db, fsys)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 0010 could be reverted, but not 0002, so nothing is
+	if _, err := m.Down(ctx, 2); !errors.Is(err, ErrIrreversible) {
+		t.Errorf("Down past an irreversible migration = %v, want ErrIrreversible", err)
+	}
+	if got := appliedVersions(t, db); !slices.Equal(got, []int{1, 2, 10}) {
+		t.Errorf("applied after a failed Down = %v, want [1 2 10]", got)
+	}
+	var admins int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE id = 1").Scan(&admins); err != nil || admins != 1 {
+		t.Errorf("admin user after a failed Down: %d, %v", admins, err)
+	}
+	if done, err := m.Down(ctx, 1); err != nil || !slices.Equal(versions(done), []int{10}) {
+		t.Errorf("Down(1) = %v, %v; want [10]", versions(done), err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "test.db"))
+	if _, err := newMigrator(t, db, testMigrations()).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := testMigrations()
+	edited["0002_names.up.sql"] = file("ALTER TABLE users ADD COLUMN full_name TEXT")
+	m := newMigrator(t, db, edited)
+	if _, err := m.Up(ctx); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Up with an edited migration = %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := m.Status(ctx); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Status with an edited migration = %v, want ErrChecksumMismatch", err)
+	}
+
+	// An older binary does not know 0010 and must not touch the database
+	older := testMigrations()
+	delete(older, "0010_admin.up.sql")
+	delete(older, "0010_admin.down.sql")
+	m = newMigrator(t, db, older)
+	if _, err := m.Up(ctx); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Up by an older binary = %v, want ErrUnknownVersion", err)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Down by an older binary = %v, want ErrUnknownVersion", err)
+	}
+	if got := appliedVersions(t, db); !slices.Equal(got, []int{1, 2, 10}) {
+		t.Errorf("applied = %v, want [1 2 10]", got)
+	}
+}
+
+// TestConcurrentUp runs two processes' migrators against the same file. The
+// one that comes second waits for the lock longer than the busy timeout and
+// then finds nothing left to do.
+func TestConcurrentUp(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	fsys := testMigrations()
+	// Keep the first runner busy for a while inside its transaction
+	fsys["0001_users.up.sql"] = file(`CREATE TABLE users (id INTEGER PRIMARY KEY);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 300000)
+		SELECT COUNT(*) FROM n;`)
+
+	var wg sync.WaitGroup
+	results := make([][]Migration, 2)
+	for i := range results {
+		m := newMigrator(t, openDB(t, path), fsys)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := m.Up(ctx)
+			if err != nil {
+				t.Errorf("runner %d: %v", i, err)
+			}
+			results[i] = done
+		}()
+	}
+	wg.Wait()
+
+	applied := append(versions(results[0]), versions(results[1])...)
+	slices.Sort(applied)
+	if !slices.Equal(applied, []int{1, 2, 10}) {
+		t.Errorf("runners applied %v and %v, want each migration once", versions(results[0]), versions(results[1]))
+	}
+}
+
+func TestUpWaitsForLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	holder := openDB(t, path)
+	conn, err := holder.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.Background(), "BEGIN IMMEDIATE"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newMigrator(t, openDB(t, path), testMigrations())
+
+	// Gives up when the context ends first
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	if _, err := m.Up(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Up while locked = %v, want DeadlineExceeded", err)
+	}
+
+	// Otherwise proceeds once the lock is released
+	release := time.AfterFunc(300*time.Millisecond, func() {
+		conn.ExecContext(context.Background(), "ROLLBACK")
+	})
+	defer release.Stop()
+	start := time.Now()
+	done, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Up after the lock was released: %v", err)
+	}
+	if len(done) != 3 || time.Since(start) < 250*time.Millisecond {
+		t.Errorf("Up applied %v after %v", versions(done), time.Since(start))
+	}
+}